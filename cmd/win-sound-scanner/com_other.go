@@ -0,0 +1,11 @@
+//go:build !windows
+
+package main
+
+// COM exists on Windows only; the simulated device source needs no apartment.
+
+func CoInitializeEx(_ uintptr) error {
+	return nil
+}
+
+func CoUninitialize() {}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package main
+
+import "syscall"
+
+var (
+	modOle32           = syscall.NewLazyDLL("ole32.dll")
+	procCoInitializeEx = modOle32.NewProc("CoInitializeEx")
+	procCoUninitialize = modOle32.NewProc("CoUninitialize")
+)
+
+func CoInitializeEx(coInit uintptr) error {
+	ret, _, _ := procCoInitializeEx.Call(0, coInit)
+	if ret != 0 {
+		return syscall.Errno(ret)
+	}
+	return nil
+}
+
+func CoUninitialize() {
+	//goland:noinspection GoUnhandledErrorResult
+	procCoUninitialize.Call() // best-effort cleanup; failure is ignored
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/config"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/scannerapp"
+)
+
+// overrideFlags collects repeated --set key=value flags.
+type overrideFlags []string
+
+func (f *overrideFlags) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *overrideFlags) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// parseCommandLine returns the command, if any, and the configuration
+// layers. Flags may come before or after the command.
+func parseCommandLine(args []string) (string, *config.Layers, error) {
+	var configPath string
+	var overrides overrideFlags
+	fs := flag.NewFlagSet("win-sound-scanner", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&configPath, "config", "", "configuration file (default %ProgramData%\\WinSoundScanner\\config.yaml)")
+	fs.Var(&overrides, "set", "override a setting, key=value; may be repeated")
+
+	if err := fs.Parse(args); err != nil {
+		return "", nil, err
+	}
+	var cmd string
+	if rest := fs.Args(); len(rest) > 0 {
+		cmd = strings.ToLower(strings.TrimSpace(rest[0]))
+		rest = rest[1:]
+		// "config" takes a subcommand: config show, config validate.
+		if cmd == configCommand && len(rest) > 0 && !strings.HasPrefix(rest[0], "-") {
+			cmd += " " + strings.ToLower(strings.TrimSpace(rest[0]))
+			rest = rest[1:]
+		}
+		if err := fs.Parse(rest); err != nil {
+			return "", nil, err
+		}
+		if len(fs.Args()) > 0 {
+			return "", nil, fmt.Errorf("unexpected arguments after %q: %s", cmd, strings.Join(fs.Args(), " "))
+		}
+	}
+
+	layers, err := config.New(scannerapp.Settings(), configPath, overrides)
+	if err != nil {
+		return "", nil, errors.Join(errors.New("invalid configuration flags"), err)
+	}
+	return cmd, layers, nil
+}
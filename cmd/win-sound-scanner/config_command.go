@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/config"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/scannerapp"
+)
+
+const (
+	configCommand         = "config"
+	configShowCommand     = "config show"
+	configValidateCommand = "config validate"
+)
+
+func isConfigCommand(cmd string) bool {
+	return cmd == configShowCommand || cmd == configValidateCommand
+}
+
+// runConfigCommand resolves the configuration the scanner would start with
+// and returns the process exit code: 1 when there is any problem.
+//
+// config show prints every setting of the selected enqueuers with its value,
+// secrets redacted, and where the value comes from; config validate only
+// lists the problems.
+func runConfigCommand(cmd string, layers *config.Layers, stdout, stderr io.Writer) int {
+	if layers == nil {
+		panic("nil config layers")
+	}
+
+	entries, err := layers.Apply()
+	if err != nil {
+		printProblems(stderr, nil, err)
+		return 1
+	}
+	resolved, err := scannerapp.ResolveConfig()
+	if cmd == configShowCommand {
+		printConfig(stdout, layers.Path(), entries, resolved)
+	}
+	if err != nil {
+		printProblems(stderr, entries, err)
+		return 1
+	}
+	if cmd == configValidateCommand {
+		_, _ = fmt.Fprintln(stdout, "Configuration is valid.")
+	}
+	return 0
+}
+
+func printConfig(w io.Writer, path string, entries []config.Entry, resolved []scannerapp.ResolvedSetting) {
+	if path == "" {
+		path = "none"
+	}
+	_, _ = fmt.Fprintf(w, "Configuration file: %s\n\n", path)
+
+	byKey := make(map[string]config.Entry, len(entries))
+	for _, entry := range entries {
+		byKey[entry.Setting.Key] = entry
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	for _, r := range resolved {
+		entry := byKey[r.Key]
+		value := config.Redact(entry.Setting, r.Value)
+		if value == "" {
+			value = `""`
+		}
+		source := sourceOf(entry)
+		if r.DerivedFrom != "" {
+			source = "derived from " + r.DerivedFrom + " (" + sourceOf(byKey[r.DerivedFrom]) + ")"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Key, value, source)
+	}
+	_ = tw.Flush()
+}
+
+func sourceOf(entry config.Entry) string {
+	if entry.Source == config.SourceFile {
+		return fmt.Sprintf("file:%d", entry.Line)
+	}
+	return entry.Source.String()
+}
+
+var envNamePattern = regexp.MustCompile(`WIN_SOUND_[A-Z0-9_]+`)
+
+// printProblems lists every problem; problems naming an environment variable
+// also name the file key and the layer that set it.
+func printProblems(w io.Writer, entries []config.Entry, err error) {
+	byEnv := make(map[string]config.Entry, len(entries))
+	for _, entry := range entries {
+		byEnv[entry.Setting.Env] = entry
+	}
+
+	problems := flatten(err)
+	_, _ = fmt.Fprintf(w, "Configuration has %d problem(s):\n", len(problems))
+	for _, problem := range problems {
+		msg := strings.ReplaceAll(problem.Error(), "\n", "\n    ")
+		var hints []string
+		seen := make(map[string]bool)
+		for _, env := range envNamePattern.FindAllString(msg, -1) {
+			if entry, ok := byEnv[env]; ok && entry.Source != config.SourceDefault && !seen[env] {
+				seen[env] = true
+				hints = append(hints, entry.Setting.Key+" from "+sourceOf(entry))
+			}
+		}
+		if len(hints) > 0 {
+			msg += " [" + strings.Join(hints, ", ") + "]"
+		}
+		_, _ = fmt.Fprintf(w, "  - %s\n", msg)
+	}
+}
+
+// flatten splits errors joined with errors.Join into the single problems.
+func flatten(err error) []error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var problems []error
+	for _, e := range joined.Unwrap() {
+		problems = append(problems, flatten(e)...)
+	}
+	return problems
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/config"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/scannerapp"
+	"github.com/collect-sound-devices/win-sound-scanner-go/pkg/appinfo"
+)
+
+const (
+	reloadCommand = "reload"
+	// reloadRequestFileName is created in the data directory by the reload
+	// command; the running scanner removes it and reloads.
+	reloadRequestFileName = "reload.request"
+	// configPollInterval is how often the configuration file and the reload
+	// request are checked.
+	configPollInterval = 2 * time.Second
+)
+
+func reloadRequestPath() (string, error) {
+	dir, err := appinfo.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, reloadRequestFileName), nil
+}
+
+// requestReload asks the running scanner, service or console, to reload its
+// configuration and rebuild the request enqueuers.
+func requestReload() (string, error) {
+	path, err := reloadRequestPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(time.Now().UTC().Format(time.RFC3339)+"\n"), 0o644); err != nil {
+		return "", fmt.Errorf("write reload request: %w", err)
+	}
+	return path, nil
+}
+
+// fileStamp identifies a version of the configuration file.
+type fileStamp struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+func stampOf(path string) fileStamp {
+	stamp := fileStamp{path: path}
+	if info, err := os.Stat(path); err == nil {
+		stamp.size, stamp.modTime = info.Size(), info.ModTime()
+	}
+	return stamp
+}
+
+// watchConfig reloads the configuration when the file changes, appears or
+// disappears, or when a reload is requested, until ctx is done.
+func watchConfig(ctx context.Context, logger *slog.Logger, layers *config.Layers, reload chan<- scannerapp.ReloadRequest) {
+	requestPath, err := reloadRequestPath()
+	if err != nil {
+		logger.Warn("Reload command is not available", "err", err)
+	} else {
+		// A request left from before the start is already satisfied.
+		_ = os.Remove(requestPath)
+	}
+
+	last := stampOf(layers.Path())
+	ticker := time.NewTicker(configPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		requested := false
+		if requestPath != "" {
+			if _, err := os.Stat(requestPath); err == nil {
+				_ = os.Remove(requestPath)
+				requested = true
+			}
+		}
+		current := stampOf(layers.Path())
+		if current == last && !requested {
+			continue
+		}
+		last = current
+		reloadConfig(ctx, logger, layers, reload, requested)
+	}
+}
+
+// reloadConfig applies the configuration again and rebuilds the request
+// enqueuers when an enqueuer setting changed, or always when requested. A
+// configuration that is invalid or cannot connect is rolled back, so the
+// scanner keeps publishing with the previous one.
+func reloadConfig(ctx context.Context, logger *slog.Logger, layers *config.Layers, reload chan<- scannerapp.ReloadRequest, requested bool) {
+	previous := layers.Entries()
+	entries, err := layers.Apply()
+	if err != nil {
+		logger.Error("Configuration reload rejected, keeping the running configuration", "err", err)
+		return
+	}
+
+	changed, startupOnly := changedSettings(previous, entries)
+	if len(startupOnly) > 0 {
+		logger.Warn("Changed settings take effect after a restart", "settings", startupOnly)
+	}
+	if len(changed) == 0 && !requested {
+		logger.Info("Configuration file changed, request enqueuer settings are unchanged", "path", layers.Path())
+		return
+	}
+	if _, err := scannerapp.ResolveConfig(); err != nil {
+		logger.Error("Configuration reload rejected, keeping the running configuration", "err", err)
+		restoreConfig(logger, layers, previous)
+		return
+	}
+
+	logger.Info("Reloading configuration", "changed", changed, "requested", requested)
+	if err := rebuildEnqueuers(ctx, reload); err != nil {
+		logger.Error("Request enqueuer rebuild failed, restoring the previous configuration", "err", err)
+		if !restoreConfig(logger, layers, previous) {
+			return
+		}
+		if err := rebuildEnqueuers(ctx, reload); err != nil {
+			logger.Error("Previous request enqueuer could not be restored", "err", err)
+		}
+		return
+	}
+	logger.Info("Configuration reloaded")
+}
+
+func restoreConfig(logger *slog.Logger, layers *config.Layers, previous []config.Entry) bool {
+	if err := layers.Restore(previous); err != nil {
+		logger.Error("Previous configuration could not be restored", "err", err)
+		return false
+	}
+	return true
+}
+
+// changedSettings returns the keys whose value differs, split into those a
+// reload applies and those that need a restart.
+func changedSettings(previous, current []config.Entry) (changed, startupOnly []string) {
+	before := make(map[string]string, len(previous))
+	for _, entry := range previous {
+		before[entry.Setting.Env] = entry.Value
+	}
+	for _, entry := range current {
+		if before[entry.Setting.Env] == entry.Value {
+			continue
+		}
+		if entry.Setting.StartupOnly {
+			startupOnly = append(startupOnly, entry.Setting.Key)
+		} else {
+			changed = append(changed, entry.Setting.Key)
+		}
+	}
+	return changed, startupOnly
+}
+
+func rebuildEnqueuers(ctx context.Context, reload chan<- scannerapp.ReloadRequest) error {
+	done := make(chan error, 1)
+	select {
+	case reload <- scannerapp.ReloadRequest{Done: done}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -10,8 +10,6 @@ import (
 	"strings"
 	"sync"
 	"time"
-
-	"golang.org/x/sys/windows"
 )
 
 const appLogTimeLayout = "2006/01/02 15:04:05.000000-07:00"
@@ -84,7 +82,7 @@ func (h *appLogHandler) Handle(_ context.Context, record slog.Record) error {
 	builder.WriteString(" [")
 	builder.WriteString(component)
 	builder.WriteString("] [")
-	builder.WriteString(strconv.FormatUint(uint64(windows.GetCurrentThreadId()), 10))
+	builder.WriteString(strconv.FormatUint(currentThreadID(), 10))
 	builder.WriteString("] ")
 	builder.WriteString(record.Message)
 
@@ -2,7 +2,6 @@ package main
 
 import (
 	"os"
-	"strings"
 
 	"github.com/kardianos/service"
 )
@@ -10,13 +9,30 @@ import (
 func main() {
 	stderrLogger := newAppLogger(os.Stderr)
 
-	if len(os.Args) > 1 {
-		cmd := strings.ToLower(strings.TrimSpace(os.Args[1]))
+	cmd, layers, err := parseCommandLine(os.Args[1:])
+	if err != nil {
+		fatalLog(stderrLogger, "invalid command line", "err", err)
+	}
+
+	if isConfigCommand(cmd) {
+		os.Exit(runConfigCommand(cmd, layers, os.Stdout, os.Stderr))
+	}
+
+	if cmd == reloadCommand {
+		path, err := requestReload()
+		if err != nil {
+			fatalLog(stderrLogger, "reload request failed", "err", err)
+		}
+		stderrLogger.Info("Reload requested", "path", path)
+		return
+	}
+
+	if cmd != "" {
 		if !isServiceCommand(cmd) {
-			fatalLog(stderrLogger, "unsupported command", "command", cmd, "supported", "install, uninstall, start, stop, restart")
+			fatalLog(stderrLogger, "unsupported command", "command", cmd, "supported", "install, uninstall, start, stop, restart, reload, config show, config validate")
 		}
 
-		svc, err := newService()
+		svc, err := newService(layers)
 		if err != nil {
 			fatalLog(stderrLogger, "service initialization failed", "err", err)
 		}
@@ -27,13 +43,13 @@ func main() {
 	}
 
 	if service.Interactive() {
-		if err := runConsole(); err != nil {
+		if err := runConsole(layers); err != nil {
 			fatalLog(stderrLogger, "exit with error", "err", err)
 		}
 		return
 	}
 
-	svc, err := newService()
+	svc, err := newService(layers)
 	if err != nil {
 		fatalLog(stderrLogger, "service initialization failed", "err", err)
 	}
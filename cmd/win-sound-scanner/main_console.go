@@ -8,15 +8,10 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/config"
 	"github.com/collect-sound-devices/win-sound-scanner-go/internal/scannerapp"
 )
 
-var (
-	modOle32           = syscall.NewLazyDLL("ole32.dll")
-	procCoInitializeEx = modOle32.NewProc("CoInitializeEx")
-	procCoUninitialize = modOle32.NewProc("CoUninitialize")
-)
-
 //goland:noinspection ALL
 const (
 	COINIT_APARTMENTTHREADED = 0x2 // Single-threaded apartment
@@ -27,40 +22,57 @@ const (
 var _ = COINIT_APARTMENTTHREADED
 var _ = COINIT_MULTITHREADED
 
-func CoInitializeEx(coInit uintptr) error {
-	ret, _, _ := procCoInitializeEx.Call(0, coInit)
-	if ret != 0 {
-		return syscall.Errno(ret)
-	}
-	return nil
-}
-
-func CoUninitialize() {
-	//goland:noinspection GoUnhandledErrorResult
-	procCoUninitialize.Call() // best-effort cleanup; failure is ignored
-}
-
-func runScanner(ctx context.Context, logger *slog.Logger) error {
+func runScanner(ctx context.Context, logger *slog.Logger, layers *config.Layers) error {
 	if ctx == nil {
 		panic("nil context")
 	}
 	if logger == nil {
 		panic("nil logger")
 	}
+	if layers == nil {
+		panic("nil config layers")
+	}
+
+	if err := applyConfig(logger, layers); err != nil {
+		return err
+	}
 
 	if err := CoInitializeEx(COINIT_MULTITHREADED); err != nil {
 		return fmt.Errorf("COM initialization failed: %w", err)
 	}
 	defer CoUninitialize()
 
-	if err := scannerapp.Run(ctx, logger); err != nil {
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	defer stopWatch()
+	reload := make(chan scannerapp.ReloadRequest)
+	go watchConfig(watchCtx, scannerapp.WithComponent(logger, "config_watcher"), layers, reload)
+
+	if err := scannerapp.Run(ctx, logger, reload); err != nil {
 		return fmt.Errorf("scanner run failed: %w", err)
 	}
 	return nil
 }
 
-func runConsole() error {
+// applyConfig exports the configuration file and flag overrides to the
+// environment the transports read.
+func applyConfig(logger *slog.Logger, layers *config.Layers) error {
+	entries, err := layers.Apply()
+	if err != nil {
+		return fmt.Errorf("configuration: %w", err)
+	}
+	counts := make(map[config.Source]int)
+	for _, entry := range entries {
+		counts[entry.Source]++
+	}
+	if path := layers.Path(); path != "" {
+		logger.Info("Configuration file loaded", "path", path, "fromFile", counts[config.SourceFile],
+			"fromEnv", counts[config.SourceEnv], "fromFlags", counts[config.SourceFlag])
+	}
+	return nil
+}
+
+func runConsole(layers *config.Layers) error {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
-	return runScanner(ctx, newAppLogger(os.Stdout))
+	return runScanner(ctx, newAppLogger(os.Stdout), layers)
 }
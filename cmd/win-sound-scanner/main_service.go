@@ -8,12 +8,13 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
 
 	"github.com/kardianos/service"
 
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/config"
 	"github.com/collect-sound-devices/win-sound-scanner-go/internal/scannerapp"
+	"github.com/collect-sound-devices/win-sound-scanner-go/pkg/appinfo"
 )
 
 const (
@@ -24,7 +25,10 @@ const (
 )
 
 var serviceEnvKeys = []string{
+	scannerapp.EnvWinSoundSource,
+	scannerapp.EnvWinSoundScenarioFile,
 	scannerapp.EnvWinSoundEnqueuer,
+	scannerapp.EnvWinSoundEnqueuerBestEffort,
 	scannerapp.EnvWinSoundRabbitMQHost,
 	scannerapp.EnvWinSoundRabbitMQPort,
 	scannerapp.EnvWinSoundRabbitMQVHost,
@@ -33,13 +37,84 @@ var serviceEnvKeys = []string{
 	scannerapp.EnvWinSoundRabbitMQExchange,
 	scannerapp.EnvWinSoundRabbitMQQueue,
 	scannerapp.EnvWinSoundRabbitMQRoutingKey,
+	scannerapp.EnvWinSoundRabbitMQTLSEnabled,
+	scannerapp.EnvWinSoundRabbitMQTLSCAFile,
+	scannerapp.EnvWinSoundRabbitMQTLSCertFile,
+	scannerapp.EnvWinSoundRabbitMQTLSKeyFile,
+	scannerapp.EnvWinSoundRabbitMQTLSServerName,
+	scannerapp.EnvWinSoundRabbitMQAuthMechanism,
+	scannerapp.EnvWinSoundRabbitMQConnThreshold,
+	scannerapp.EnvWinSoundRabbitMQMaxReconnects,
+	scannerapp.EnvWinSoundRabbitMQRetryInitial,
+	scannerapp.EnvWinSoundRabbitMQRetryMax,
+	scannerapp.EnvWinSoundRabbitMQConfirmWait,
+	scannerapp.EnvWinSoundCloudEventsMode,
+	scannerapp.EnvWinSoundSchemaValidation,
+	scannerapp.EnvWinSoundRejectedDir,
 	scannerapp.EnvWinSoundKafkaBrokers,
 	scannerapp.EnvWinSoundKafkaTopic,
 	scannerapp.EnvWinSoundKafkaClientID,
 	scannerapp.EnvWinSoundKafkaWriteTimeout,
+	scannerapp.EnvWinSoundKafkaHealthWindow,
+	scannerapp.EnvWinSoundKafkaTLSEnabled,
+	scannerapp.EnvWinSoundKafkaTLSCAFile,
+	scannerapp.EnvWinSoundKafkaTLSCertFile,
+	scannerapp.EnvWinSoundKafkaTLSKeyFile,
+	scannerapp.EnvWinSoundKafkaTLSSkipVerify,
+	scannerapp.EnvWinSoundKafkaSASLMechanism,
+	scannerapp.EnvWinSoundKafkaSASLUsername,
+	scannerapp.EnvWinSoundKafkaSASLPassword,
+	scannerapp.EnvWinSoundKafkaEncoding,
+	scannerapp.EnvWinSoundKafkaRegistryURL,
+	scannerapp.EnvWinSoundKafkaRegistryUsername,
+	scannerapp.EnvWinSoundKafkaRegistryPassword,
+	scannerapp.EnvWinSoundKafkaRegistryAutoReg,
+	scannerapp.EnvWinSoundHTTPBaseURL,
+	scannerapp.EnvWinSoundHTTPTimeout,
+	scannerapp.EnvWinSoundHTTPMaxRetries,
+	scannerapp.EnvWinSoundHTTPRetryInitial,
+	scannerapp.EnvWinSoundHTTPRetryMax,
+	scannerapp.EnvWinSoundHTTPHeaders,
+	scannerapp.EnvWinSoundMQTTBroker,
+	scannerapp.EnvWinSoundMQTTClientID,
+	scannerapp.EnvWinSoundMQTTUser,
+	scannerapp.EnvWinSoundMQTTPassword,
+	scannerapp.EnvWinSoundMQTTProtocolVersion,
+	scannerapp.EnvWinSoundMQTTTopic,
+	scannerapp.EnvWinSoundMQTTStatusTopic,
+	scannerapp.EnvWinSoundMQTTQoS,
+	scannerapp.EnvWinSoundMQTTRetained,
+	scannerapp.EnvWinSoundMQTTConnectTimeout,
+	scannerapp.EnvWinSoundMQTTPublishTimeout,
+	scannerapp.EnvWinSoundNATSURL,
+	scannerapp.EnvWinSoundNATSUser,
+	scannerapp.EnvWinSoundNATSPassword,
+	scannerapp.EnvWinSoundNATSClientName,
+	scannerapp.EnvWinSoundNATSSubjectPrefix,
+	scannerapp.EnvWinSoundNATSJetStream,
+	scannerapp.EnvWinSoundNATSStream,
+	scannerapp.EnvWinSoundNATSPublishTimeout,
+	scannerapp.EnvWinSoundFileDir,
+	scannerapp.EnvWinSoundFileMaxSizeMB,
+	scannerapp.EnvWinSoundFileRotateDaily,
+	scannerapp.EnvWinSoundFileFsyncInterval,
+	scannerapp.EnvWinSoundOutboxEnabled,
+	scannerapp.EnvWinSoundOutboxDir,
+	scannerapp.EnvWinSoundOutboxRetryInitial,
+	scannerapp.EnvWinSoundOutboxRetryMax,
+	scannerapp.EnvWinSoundOutboxBestEffortMax,
+	scannerapp.EnvWinSoundQueueCapacity,
+	scannerapp.EnvWinSoundQueueOverflow,
+	scannerapp.EnvWinSoundVolumeSettleWindow,
+	scannerapp.EnvWinSoundVolumeMaxDelay,
+	scannerapp.EnvWinSoundMetricsListen,
+	scannerapp.EnvWinSoundHealthListen,
+	scannerapp.EnvWinSoundOTelEndpoint,
 }
 
 type scannerProgram struct {
+	layers *config.Layers
+
 	mu      sync.Mutex
 	cancel  context.CancelFunc
 	done    chan struct{}
@@ -69,7 +144,7 @@ func (p *scannerProgram) Start(_ service.Service) error {
 
 	go func(logger *slog.Logger) {
 		defer close(done)
-		if err := runScanner(ctx, logger); err != nil && !errors.Is(err, context.Canceled) {
+		if err := runScanner(ctx, logger, p.layers); err != nil && !errors.Is(err, context.Canceled) {
 			logger.Error("scanner failed", "err", err)
 			os.Exit(1)
 		}
@@ -100,7 +175,9 @@ func (p *scannerProgram) Stop(_ service.Service) error {
 	return nil
 }
 
-func newService() (service.Service, error) {
+// newService describes the service. The --config and --set flags given to
+// install become the service's start arguments.
+func newService(layers *config.Layers) (service.Service, error) {
 	envVars := collectServiceEnvVars()
 	if len(envVars) == 0 {
 		envVars = nil
@@ -114,10 +191,11 @@ func newService() (service.Service, error) {
 			"StartType": "automatic",
 			"OnFailure": "restart",
 		},
-		EnvVars: envVars,
+		EnvVars:   envVars,
+		Arguments: layers.Arguments(),
 	}
 
-	return service.New(&scannerProgram{}, cfg)
+	return service.New(&scannerProgram{layers: layers}, cfg)
 }
 
 func collectServiceEnvVars() map[string]string {
@@ -139,23 +217,12 @@ func isServiceCommand(cmd string) bool {
 	}
 }
 
-func programDataDir() (string, error) {
-	if v, ok := os.LookupEnv("ProgramData"); ok && strings.TrimSpace(v) != "" {
-		return v, nil
-	}
-	if v, ok := os.LookupEnv("ALLUSERSPROFILE"); ok && strings.TrimSpace(v) != "" {
-		return v, nil
-	}
-	return "", errors.New("ProgramData is not available in environment")
-}
-
 func configureServiceFileLogging() (*os.File, error) {
-	baseDir, err := programDataDir()
+	logDir, err := appinfo.DataDir()
 	if err != nil {
 		return nil, err
 	}
 
-	logDir := filepath.Join(baseDir, serviceName)
 	if err := os.MkdirAll(logDir, 0o755); err != nil {
 		return nil, fmt.Errorf("create service log directory: %w", err)
 	}
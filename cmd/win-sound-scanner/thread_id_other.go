@@ -0,0 +1,10 @@
+//go:build !windows
+
+package main
+
+import "os"
+
+// currentThreadID falls back to the process id where no portable thread id exists.
+func currentThreadID() uint64 {
+	return uint64(os.Getpid())
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package main
+
+import "golang.org/x/sys/windows"
+
+func currentThreadID() uint64 {
+	return uint64(windows.GetCurrentThreadId())
+}
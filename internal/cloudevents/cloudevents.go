@@ -0,0 +1,177 @@
+// Package cloudevents wraps request payloads in CloudEvents 1.0 envelopes.
+package cloudevents
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+const (
+	SpecVersion = "1.0"
+	// ContentTypeStructured is the media type of a structured-mode event.
+	ContentTypeStructured = "application/cloudevents+json"
+
+	typePrefix = "com.collect-sound-devices."
+	sourceHost = "scanner://"
+
+	envCloudEventsMode = "WIN_SOUND_CLOUDEVENTS_MODE"
+)
+
+// Mode selects how requests are put on the wire.
+type Mode string
+
+const (
+	// ModeOff publishes the plain request payload.
+	ModeOff Mode = ""
+	// ModeStructured publishes the whole event, data included, as one JSON document.
+	ModeStructured Mode = "structured"
+	// ModeBinary publishes the payload as message body and the event
+	// attributes as protocol headers. Transports without a binary binding
+	// fall back to ModeStructured.
+	ModeBinary Mode = "binary"
+)
+
+func ParseMode(raw string) (Mode, error) {
+	switch m := Mode(strings.ToLower(strings.TrimSpace(raw))); m {
+	case ModeOff, ModeStructured, ModeBinary:
+		return m, nil
+	case "off", "none":
+		return ModeOff, nil
+	default:
+		return "", fmt.Errorf("unsupported CloudEvents mode %q (supported: empty, %s, %s)", raw, ModeStructured, ModeBinary)
+	}
+}
+
+// LoadModeFromEnv reads WIN_SOUND_CLOUDEVENTS_MODE; empty disables CloudEvents.
+func LoadModeFromEnv() (Mode, error) {
+	mode, err := ParseMode(os.Getenv(envCloudEventsMode))
+	if err != nil {
+		return ModeOff, fmt.Errorf("invalid %s: %w", envCloudEventsMode, err)
+	}
+	return mode, nil
+}
+
+// Event holds the CloudEvents context attributes of one request and the
+// request payload as data.
+type Event struct {
+	ID              string
+	Source          string
+	Type            string
+	Subject         string
+	Time            time.Time
+	DataContentType string
+	Data            json.RawMessage
+}
+
+// Attribute is a context attribute in its string form, as binary-mode
+// protocol bindings carry it.
+type Attribute struct {
+	Name  string
+	Value string
+}
+
+// FromPayload describes a built request payload as an event.
+func FromPayload(payload enqueuer.RequestPayload) Event {
+	host := payload.HostName
+	if host == "" {
+		host = "unknown"
+	}
+	return Event{
+		ID:              payload.EventID,
+		Source:          sourceHost + host,
+		Type:            TypeOf(payload.Event),
+		Subject:         payload.PnpID,
+		Time:            payload.EventTime,
+		DataContentType: enqueuer.PayloadContentType,
+		Data:            payload.Body,
+	}
+}
+
+// Attributes returns specversion, id, source, type and the optional
+// subject and time. datacontenttype is left out, since the bindings map it
+// to the protocol's own content-type.
+func (e Event) Attributes() []Attribute {
+	attributes := []Attribute{
+		{Name: "specversion", Value: SpecVersion},
+		{Name: "id", Value: e.ID},
+		{Name: "source", Value: e.Source},
+		{Name: "type", Value: e.Type},
+	}
+	if e.Subject != "" {
+		attributes = append(attributes, Attribute{Name: "subject", Value: e.Subject})
+	}
+	if !e.Time.IsZero() {
+		attributes = append(attributes, Attribute{Name: "time", Value: e.Time.UTC().Format(time.RFC3339Nano)})
+	}
+	return attributes
+}
+
+type structuredEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            string          `json:"time,omitempty"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// MarshalStructured returns the structured-mode JSON document.
+func (e Event) MarshalStructured() ([]byte, error) {
+	envelope := structuredEvent{
+		SpecVersion:     SpecVersion,
+		ID:              e.ID,
+		Source:          e.Source,
+		Type:            e.Type,
+		Subject:         e.Subject,
+		DataContentType: e.DataContentType,
+		Data:            e.Data,
+	}
+	if !e.Time.IsZero() {
+		envelope.Time = e.Time.UTC().Format(time.RFC3339Nano)
+	}
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("marshal cloudevent: %w", err)
+	}
+	return body, nil
+}
+
+// Structured replaces the payload body by its structured-mode event.
+func Structured(payload enqueuer.RequestPayload) (enqueuer.RequestPayload, error) {
+	body, err := FromPayload(payload).MarshalStructured()
+	if err != nil {
+		return enqueuer.RequestPayload{}, err
+	}
+	payload.Body = body
+	return payload, nil
+}
+
+var eventTypes = map[contract.EventType]string{
+	contract.EventTypeRenderDeviceConfirmed:   "render.device-confirmed",
+	contract.EventTypeCaptureDeviceConfirmed:  "capture.device-confirmed",
+	contract.EventTypeRenderDeviceDiscovered:  "render.device-discovered",
+	contract.EventTypeCaptureDeviceDiscovered: "capture.device-discovered",
+	contract.EventTypeRenderVolumeChanged:     "render.volume-changed",
+	contract.EventTypeCaptureVolumeChanged:    "capture.volume-changed",
+	contract.EventTypeRenderDeviceDetached:    "render.device-detached",
+	contract.EventTypeCaptureDeviceDetached:   "capture.device-detached",
+	contract.EventTypeDefaultRenderChanged:    "render.default-changed",
+	contract.EventTypeDefaultCaptureChanged:   "capture.default-changed",
+}
+
+// TypeOf returns the CloudEvents type, e.g.
+// com.collect-sound-devices.render.volume-changed.
+func TypeOf(event contract.EventType) string {
+	if name, ok := eventTypes[event]; ok {
+		return typePrefix + name
+	}
+	return typePrefix + strings.ToLower(event.String())
+}
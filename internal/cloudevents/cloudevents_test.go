@@ -0,0 +1,96 @@
+package cloudevents
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+func buildPayload(t *testing.T, event contract.EventType, fields map[string]string) enqueuer.RequestPayload {
+	t.Helper()
+	payload, err := enqueuer.BuildRequestPayload(enqueuer.Request{
+		Timestamp: time.Date(2026, 5, 26, 10, 0, 0, 500, time.UTC),
+		Event:     event,
+		Fields:    fields,
+	})
+	if err != nil {
+		t.Fatalf("BuildRequestPayload failed: %v", err)
+	}
+	return payload
+}
+
+func TestStructured_WrapsPayloadInEnvelope(t *testing.T) {
+	payload := buildPayload(t, contract.EventTypeRenderVolumeChanged, map[string]string{
+		contract.FieldPnpID:        "pnp-1",
+		contract.FieldHostName:     "host-1",
+		contract.FieldRenderVolume: "42",
+	})
+
+	wrapped, err := Structured(payload)
+	if err != nil {
+		t.Fatalf("Structured failed: %v", err)
+	}
+	var event map[string]any
+	if err := json.Unmarshal(wrapped.Body, &event); err != nil {
+		t.Fatalf("event is not valid JSON: %v", err)
+	}
+
+	want := map[string]string{
+		"specversion":     "1.0",
+		"id":              payload.EventID,
+		"source":          "scanner://host-1",
+		"type":            "com.collect-sound-devices.render.volume-changed",
+		"subject":         "pnp-1",
+		"time":            "2026-05-26T10:00:00.0000005Z",
+		"datacontenttype": "application/json",
+	}
+	for key, value := range want {
+		if event[key] != value {
+			t.Fatalf("%s: expected %q, got %#v", key, value, event[key])
+		}
+	}
+	data, ok := event["data"].(map[string]any)
+	if !ok || data[contract.FieldRenderVolume] != float64(42) {
+		t.Fatalf("expected the payload as data, got %#v", event["data"])
+	}
+	if wrapped.URLSuffix != payload.URLSuffix || wrapped.DeviceKey != payload.DeviceKey {
+		t.Fatal("expected the routing metadata to be kept")
+	}
+}
+
+func TestAttributes_OmitEmptyOptionalAttributes(t *testing.T) {
+	attributes := Event{ID: "id-1", Source: "scanner://host-1", Type: TypeOf(contract.EventTypeNothing)}.Attributes()
+
+	if len(attributes) != 4 {
+		t.Fatalf("expected only the required attributes, got %#v", attributes)
+	}
+	if attributes[3].Value != "com.collect-sound-devices.nothing" {
+		t.Fatalf("unexpected fallback type %q", attributes[3].Value)
+	}
+}
+
+func TestTypeOf_CoversEveryEventType(t *testing.T) {
+	for event := contract.EventTypeRenderDeviceConfirmed; event <= contract.EventTypeDefaultCaptureChanged; event++ {
+		if _, ok := eventTypes[event]; !ok {
+			t.Fatalf("no CloudEvents type for %s", event)
+		}
+	}
+	if got := TypeOf(contract.EventTypeDefaultCaptureChanged); got != "com.collect-sound-devices.capture.default-changed" {
+		t.Fatalf("unexpected type %q", got)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for raw, want := range map[string]Mode{"": ModeOff, "off": ModeOff, " Structured ": ModeStructured, "BINARY": ModeBinary} {
+		got, err := ParseMode(raw)
+		if err != nil || got != want {
+			t.Fatalf("ParseMode(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+	if _, err := ParseMode("batched"); err == nil {
+		t.Fatal("expected error for unsupported mode")
+	}
+}
@@ -0,0 +1,263 @@
+// Package config layers a YAML configuration file and command-line
+// overrides over the WIN_SOUND_* environment variables.
+//
+// Every transport keeps reading its settings with LoadConfigFromEnv; this
+// package only decides which value each variable ends up with. Precedence,
+// lowest first: built-in defaults, the file, the environment, flags.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/pkg/appinfo"
+)
+
+// DefaultFileName is looked up in %ProgramData%\WinSoundScanner when no
+// file is given.
+const DefaultFileName = "config.yaml"
+
+// Kind is the value type a setting accepts in the file.
+type Kind uint8
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindBool
+	// KindList is a sequence, or a comma-separated string.
+	KindList
+	// KindHeaders is a mapping of header names to values, or a
+	// "Name: value; Name: value" string.
+	KindHeaders
+)
+
+// Setting ties a file key to the environment variable it configures.
+type Setting struct {
+	// Key is the dotted path in the file, e.g. "rabbitmq.tls.caFile".
+	Key  string
+	Env  string
+	Kind Kind
+	// Secret values are redacted whenever they are shown.
+	Secret bool
+	// StartupOnly settings are read once at startup; a reload does not
+	// apply them.
+	StartupOnly bool
+}
+
+// Source tells which layer a value comes from.
+type Source uint8
+
+const (
+	SourceDefault Source = iota
+	SourceFile
+	SourceEnv
+	SourceFlag
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceFile:
+		return "file"
+	case SourceEnv:
+		return "env"
+	case SourceFlag:
+		return "flag"
+	default:
+		return "default"
+	}
+}
+
+// Entry is the resolved value of one setting. Value is empty and Source is
+// SourceDefault when no layer sets it, leaving the default to the transport.
+type Entry struct {
+	Setting Setting
+	Value   string
+	Source  Source
+	// Line is the line of the value in the file, for SourceFile.
+	Line int
+}
+
+// Layers resolves settings from the file, the environment captured when
+// Layers was created, and the flags. Apply can be called again to pick up
+// a changed file.
+type Layers struct {
+	settings []Setting
+	byName   map[string]Setting
+	path     string
+	// required is set when the file was named explicitly; the default file
+	// is optional.
+	required bool
+	flags    map[string]string
+	environ  map[string]string
+
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// DefaultPath returns %ProgramData%\WinSoundScanner\config.yaml, or "" when
+// ProgramData is not available.
+func DefaultPath() string {
+	dir, err := appinfo.DataDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, DefaultFileName)
+}
+
+// New captures the environment and validates the flag overrides. path is the
+// file given with --config; empty selects DefaultPath. overrides are
+// "key=value" pairs where key is a file key or an environment variable name.
+func New(settings []Setting, path string, overrides []string) (*Layers, error) {
+	l := &Layers{
+		settings: settings,
+		byName:   make(map[string]Setting, 2*len(settings)),
+		flags:    make(map[string]string),
+		environ:  make(map[string]string),
+	}
+	for _, s := range settings {
+		l.byName[s.Key] = s
+		l.byName[s.Env] = s
+		if v, ok := os.LookupEnv(s.Env); ok {
+			l.environ[s.Env] = v
+		}
+	}
+
+	if strings.TrimSpace(path) != "" {
+		abs, err := filepath.Abs(strings.TrimSpace(path))
+		if err != nil {
+			return nil, fmt.Errorf("config file %q: %w", path, err)
+		}
+		l.path = abs
+		l.required = true
+	} else {
+		l.path = DefaultPath()
+	}
+
+	var errs []error
+	for _, override := range overrides {
+		name, value, ok := strings.Cut(override, "=")
+		setting, known := l.byName[strings.TrimSpace(name)]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("--set %q: expected key=value", override))
+		case !known:
+			errs = append(errs, fmt.Errorf("--set %q: unknown setting %q", override, strings.TrimSpace(name)))
+		default:
+			l.flags[setting.Env] = value
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Path returns the configuration file in use, or "" when there is none.
+func (l *Layers) Path() string {
+	if l.required {
+		return l.path
+	}
+	if l.path == "" {
+		return ""
+	}
+	if _, err := os.Stat(l.path); err != nil {
+		return ""
+	}
+	return l.path
+}
+
+// Arguments returns the command-line flags that reproduce these layers, so
+// an installed service reads the same file and overrides.
+func (l *Layers) Arguments() []string {
+	var args []string
+	if l.required {
+		args = append(args, "--config", l.path)
+	}
+	envs := make([]string, 0, len(l.flags))
+	for env := range l.flags {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
+	for _, env := range envs {
+		args = append(args, "--set", env+"="+l.flags[env])
+	}
+	return args
+}
+
+// Apply reads the file and exports the winning value of every setting to the
+// process environment, where the transports read it. On error nothing is
+// changed, so a broken file never half-applies. All problems of the file are
+// reported at once, each with its line.
+func (l *Layers) Apply() ([]Entry, error) {
+	values, err := l.readFile()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(l.settings))
+	for _, s := range l.settings {
+		entry := Entry{Setting: s}
+		if v, ok := l.flags[s.Env]; ok {
+			entry.Value, entry.Source = v, SourceFlag
+		} else if v, ok := l.environ[s.Env]; ok {
+			entry.Value, entry.Source = v, SourceEnv
+		} else if v, ok := values[s.Env]; ok {
+			entry.Value, entry.Source, entry.Line = v.value, SourceFile, v.line
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := l.export(entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Restore exports entries returned by an earlier Apply again, e.g. when the
+// configuration a reload applied turned out not to work.
+func (l *Layers) Restore(entries []Entry) error {
+	return l.export(entries)
+}
+
+func (l *Layers) export(entries []Entry) error {
+	for _, entry := range entries {
+		var err error
+		if entry.Source == SourceDefault {
+			err = os.Unsetenv(entry.Setting.Env)
+		} else {
+			err = os.Setenv(entry.Setting.Env, entry.Value)
+		}
+		if err != nil {
+			return fmt.Errorf("export %s: %w", entry.Setting.Env, err)
+		}
+	}
+
+	l.mu.Lock()
+	l.entries = entries
+	l.mu.Unlock()
+	return nil
+}
+
+// Entries returns the values of the last successful Apply.
+func (l *Layers) Entries() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Entry(nil), l.entries...)
+}
+
+func (l *Layers) readFile() (map[string]fileValue, error) {
+	path := l.Path()
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+	return parse(path, data, l.settings)
+}
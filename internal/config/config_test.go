@@ -0,0 +1,261 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	envHost     = "WIN_SOUND_TEST_HOST"
+	envPort     = "WIN_SOUND_TEST_PORT"
+	envTLS      = "WIN_SOUND_TEST_TLS"
+	envBrokers  = "WIN_SOUND_TEST_BROKERS"
+	envHeaders  = "WIN_SOUND_TEST_HEADERS"
+	envPassword = "WIN_SOUND_TEST_PASSWORD"
+)
+
+var testSettings = []Setting{
+	{Key: "broker.host", Env: envHost, Kind: KindString},
+	{Key: "broker.port", Env: envPort, Kind: KindInt},
+	{Key: "broker.tls.enabled", Env: envTLS, Kind: KindBool},
+	{Key: "brokers", Env: envBrokers, Kind: KindList},
+	{Key: "headers", Env: envHeaders, Kind: KindHeaders, Secret: true},
+	{Key: "broker.password", Env: envPassword, Kind: KindString, Secret: true},
+}
+
+// unsetEnv clears the test variables and restores them after the test.
+func unsetEnv(t *testing.T) {
+	t.Helper()
+	for _, s := range testSettings {
+		t.Setenv(s.Env, "")
+		if err := os.Unsetenv(s.Env); err != nil {
+			t.Fatalf("unset %s: %v", s.Env, err)
+		}
+	}
+}
+
+func writeConfig(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "scanner.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func entryOf(t *testing.T, entries []Entry, env string) Entry {
+	t.Helper()
+	for _, e := range entries {
+		if e.Setting.Env == env {
+			return e
+		}
+	}
+	t.Fatalf("no entry for %s", env)
+	return Entry{}
+}
+
+func TestApply_LayersFileEnvAndFlags(t *testing.T) {
+	unsetEnv(t)
+	path := writeConfig(t, t.TempDir(), `
+broker:
+  host: file-host
+  port: 5671
+  tls:
+    enabled: true
+brokers: [kafka-1:9092, kafka-2:9092]
+headers:
+  Authorization: Bearer abc
+  X-Site: lab-1
+`)
+	t.Setenv(envPort, "5672")
+	layers, err := New(testSettings, path, []string{"broker.host=flag-host"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	entries, err := layers.Apply()
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	expect := map[string]struct {
+		value  string
+		source Source
+	}{
+		envHost:     {"flag-host", SourceFlag},
+		envPort:     {"5672", SourceEnv},
+		envTLS:      {"true", SourceFile},
+		envBrokers:  {"kafka-1:9092,kafka-2:9092", SourceFile},
+		envHeaders:  {"Authorization: Bearer abc; X-Site: lab-1", SourceFile},
+		envPassword: {"", SourceDefault},
+	}
+	for env, want := range expect {
+		entry := entryOf(t, entries, env)
+		if entry.Value != want.value || entry.Source != want.source {
+			t.Fatalf("%s: got %q from %s, want %q from %s", env, entry.Value, entry.Source, want.value, want.source)
+		}
+		if got, ok := os.LookupEnv(env); ok != (want.source != SourceDefault) || got != want.value {
+			t.Fatalf("%s: environment is %q (set %v)", env, got, ok)
+		}
+	}
+	if line := entryOf(t, entries, envTLS).Line; line != 6 {
+		t.Fatalf("expected TLS value on line 6, got %d", line)
+	}
+}
+
+func TestApply_ReportsAllProblemsWithLines(t *testing.T) {
+	unsetEnv(t)
+	path := writeConfig(t, t.TempDir(), `broker:
+  host: [a, b]
+  port: fifty
+  colour: blue
+brokers: kafka:9092
+brokers: kafka:9093
+headers: 42
+`)
+	layers, err := New(testSettings, path, nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	_, err = layers.Apply()
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, want := range []string{
+		"scanner.yaml:2: broker.host: expected a single value",
+		"scanner.yaml:3: broker.port: expected an integer",
+		"scanner.yaml:4: broker.colour: unknown setting",
+		"scanner.yaml:6: brokers: duplicate key",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in:\n%v", want, err)
+		}
+	}
+	if _, ok := os.LookupEnv(envBrokers); ok {
+		t.Fatal("a failed Apply must not change the environment")
+	}
+}
+
+func TestApply_ReloadUnsetsRemovedKeys(t *testing.T) {
+	unsetEnv(t)
+	dir := t.TempDir()
+	path := writeConfig(t, dir, "broker:\n  host: first\n  port: 1\n")
+	layers, err := New(testSettings, path, nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := layers.Apply(); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	writeConfig(t, dir, "broker:\n  host: second\n")
+	if _, err := layers.Apply(); err != nil {
+		t.Fatalf("second Apply failed: %v", err)
+	}
+	if os.Getenv(envHost) != "second" {
+		t.Fatalf("expected reloaded host, got %q", os.Getenv(envHost))
+	}
+	if _, ok := os.LookupEnv(envPort); ok {
+		t.Fatal("expected removed port to be unset")
+	}
+}
+
+func TestNew_FileAndFlagErrors(t *testing.T) {
+	unsetEnv(t)
+	dir := t.TempDir()
+	t.Setenv("ProgramData", dir)
+
+	// The default file is optional ...
+	layers, err := New(testSettings, "", nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if layers.Path() != "" {
+		t.Fatalf("expected no file, got %q", layers.Path())
+	}
+	if _, err := layers.Apply(); err != nil {
+		t.Fatalf("Apply without a file failed: %v", err)
+	}
+
+	// ... an explicit one is not.
+	layers, err = New(testSettings, filepath.Join(dir, "missing.yaml"), nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := layers.Apply(); err == nil {
+		t.Fatal("expected error for a missing explicit file")
+	}
+
+	if _, err := New(testSettings, "", []string{"broker.host", "nope=1"}); err == nil ||
+		!strings.Contains(err.Error(), "expected key=value") || !strings.Contains(err.Error(), `unknown setting "nope"`) {
+		t.Fatalf("expected both flag errors, got %v", err)
+	}
+}
+
+func TestArguments_ReproduceFileAndOverrides(t *testing.T) {
+	unsetEnv(t)
+	layers, err := New(testSettings, "scanner.yaml", []string{envPort + "=1", "broker.host=h"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	args := strings.Join(layers.Arguments(), " ")
+	abs, _ := filepath.Abs("scanner.yaml")
+	want := "--config " + abs + " --set " + envHost + "=h --set " + envPort + "=1"
+	if args != want {
+		t.Fatalf("got %q, want %q", args, want)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	byEnv := make(map[string]Setting)
+	for _, s := range testSettings {
+		byEnv[s.Env] = s
+	}
+	for _, tc := range []struct {
+		env, value, want string
+	}{
+		{envPassword, "s3cret", Redacted},
+		{envPassword, "", ""},
+		{envHeaders, "Authorization: Bearer abc; X-Site: lab-1", "Authorization: " + Redacted + "; X-Site: " + Redacted},
+		{envHost, "plain-host", "plain-host"},
+		{envBrokers, "nats://a:pw@n1:4222,nats://n2:4222", "nats://a:xxxxx@n1:4222,nats://n2:4222"},
+	} {
+		if got := Redact(byEnv[tc.env], tc.value); got != tc.want {
+			t.Fatalf("Redact(%s, %q) = %q, want %q", tc.env, tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestRestore_ExportsPreviousEntries(t *testing.T) {
+	unsetEnv(t)
+	dir := t.TempDir()
+	path := writeConfig(t, dir, "broker:\n  host: first\n")
+	layers, err := New(testSettings, path, nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	previous, err := layers.Apply()
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	writeConfig(t, dir, "broker:\n  host: second\n  port: 2\n")
+	if _, err := layers.Apply(); err != nil {
+		t.Fatalf("second Apply failed: %v", err)
+	}
+	if err := layers.Restore(previous); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if os.Getenv(envHost) != "first" {
+		t.Fatalf("expected restored host, got %q", os.Getenv(envHost))
+	}
+	if _, ok := os.LookupEnv(envPort); ok {
+		t.Fatal("expected port to be unset again")
+	}
+	if entryOf(t, layers.Entries(), envHost).Value != "first" {
+		t.Fatal("expected Entries to return the restored values")
+	}
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+type fileValue struct {
+	value string
+	line  int
+}
+
+// parser walks the YAML document and collects every problem instead of
+// stopping at the first one.
+type parser struct {
+	file     string
+	byKey    map[string]Setting
+	sections map[string]bool
+	values   map[string]fileValue
+	errs     []error
+}
+
+func parse(path string, data []byte, settings []Setting) (map[string]fileValue, error) {
+	p := &parser{
+		file:     filepath.Base(path),
+		byKey:    make(map[string]Setting, len(settings)),
+		sections: make(map[string]bool),
+		values:   make(map[string]fileValue),
+	}
+	for _, s := range settings {
+		p.byKey[s.Key] = s
+		parts := strings.Split(s.Key, ".")
+		for i := 1; i < len(parts); i++ {
+			p.sections[strings.Join(parts[:i], ".")] = true
+		}
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", p.file, err)
+	}
+	// An empty file configures nothing.
+	if len(doc.Content) == 0 {
+		return p.values, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		p.fail(root, "", "the document must be a mapping of settings")
+	} else {
+		p.mapping(root, "")
+	}
+	if err := errors.Join(p.errs...); err != nil {
+		return nil, err
+	}
+	return p.values, nil
+}
+
+func (p *parser) fail(node *yaml.Node, key, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if key != "" {
+		msg = key + ": " + msg
+	}
+	p.errs = append(p.errs, fmt.Errorf("%s:%d: %s", p.file, node.Line, msg))
+}
+
+func (p *parser) mapping(node *yaml.Node, prefix string) {
+	seen := make(map[string]bool, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		key := keyNode.Value
+		if prefix != "" {
+			key = prefix + "." + keyNode.Value
+		}
+		if seen[key] {
+			p.fail(keyNode, key, "duplicate key")
+			continue
+		}
+		seen[key] = true
+
+		if setting, ok := p.byKey[key]; ok {
+			p.setting(setting, valueNode)
+			continue
+		}
+		if !p.sections[key] {
+			p.fail(keyNode, key, "unknown setting")
+			continue
+		}
+		if valueNode.Kind != yaml.MappingNode {
+			if isNull(valueNode) {
+				continue
+			}
+			p.fail(valueNode, key, "expected a mapping of settings")
+			continue
+		}
+		p.mapping(valueNode, key)
+	}
+}
+
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+func (p *parser) setting(s Setting, node *yaml.Node) {
+	// "key:" without a value leaves the setting to the next layer.
+	if isNull(node) {
+		return
+	}
+	value, err := scalarValue(s.Kind, node)
+	if err != nil {
+		p.fail(node, s.Key, "%s", err)
+		return
+	}
+	p.values[s.Env] = fileValue{value: value, line: node.Line}
+}
+
+func scalarValue(kind Kind, node *yaml.Node) (string, error) {
+	switch kind {
+	case KindList:
+		if node.Kind == yaml.SequenceNode {
+			items := make([]string, 0, len(node.Content))
+			for _, item := range node.Content {
+				if item.Kind != yaml.ScalarNode {
+					return "", errors.New("expected a list of strings")
+				}
+				items = append(items, item.Value)
+			}
+			return strings.Join(items, ","), nil
+		}
+	case KindHeaders:
+		if node.Kind == yaml.MappingNode {
+			pairs := make([]string, 0, len(node.Content)/2)
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i+1].Kind != yaml.ScalarNode {
+					return "", errors.New("expected header values to be strings")
+				}
+				pairs = append(pairs, node.Content[i].Value+": "+node.Content[i+1].Value)
+			}
+			return strings.Join(pairs, "; "), nil
+		}
+	}
+
+	if node.Kind != yaml.ScalarNode {
+		return "", errors.New("expected a single value")
+	}
+	switch kind {
+	case KindInt:
+		if _, err := strconv.Atoi(node.Value); err != nil {
+			return "", fmt.Errorf("expected an integer, got %q", node.Value)
+		}
+	case KindBool:
+		b, err := strconv.ParseBool(node.Value)
+		if err != nil {
+			return "", fmt.Errorf("expected true or false, got %q", node.Value)
+		}
+		return strconv.FormatBool(b), nil
+	}
+	return node.Value, nil
+}
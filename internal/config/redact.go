@@ -0,0 +1,42 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Redacted replaces secret values wherever they are shown.
+const Redacted = "********"
+
+// Redact returns value as it may be shown for setting s: secrets are
+// replaced, header values are replaced but their names kept, and passwords
+// in URLs are masked the way url.URL.Redacted does in any setting.
+func Redact(s Setting, value string) string {
+	if value == "" {
+		return ""
+	}
+	if s.Secret && s.Kind == KindHeaders {
+		pairs := strings.Split(value, ";")
+		for i, pair := range pairs {
+			name, _, _ := strings.Cut(pair, ":")
+			pairs[i] = strings.TrimSpace(name) + ": " + Redacted
+		}
+		return strings.Join(pairs, "; ")
+	}
+	if s.Secret {
+		return Redacted
+	}
+
+	// Lists such as a NATS server list may hold several URLs.
+	parts := strings.Split(value, ",")
+	for i, part := range parts {
+		u, err := url.Parse(strings.TrimSpace(part))
+		if err != nil || u.User == nil {
+			continue
+		}
+		if _, ok := u.User.Password(); ok {
+			parts[i] = u.Redacted()
+		}
+	}
+	return strings.Join(parts, ",")
+}
@@ -1,5 +1,7 @@
 package contract
 
+import "strconv"
+
 type EventType uint8
 
 //goland:noinspection GoUnusedConst
@@ -11,15 +13,41 @@ const (
 	EventTypeCaptureDeviceDiscovered
 	EventTypeRenderVolumeChanged
 	EventTypeCaptureVolumeChanged
+	EventTypeRenderDeviceDetached
+	EventTypeCaptureDeviceDetached
+	EventTypeDefaultRenderChanged
+	EventTypeDefaultCaptureChanged
 )
 
+var eventTypeNames = [...]string{
+	EventTypeNothing:                 "Nothing",
+	EventTypeRenderDeviceConfirmed:   "RenderDeviceConfirmed",
+	EventTypeCaptureDeviceConfirmed:  "CaptureDeviceConfirmed",
+	EventTypeRenderDeviceDiscovered:  "RenderDeviceDiscovered",
+	EventTypeCaptureDeviceDiscovered: "CaptureDeviceDiscovered",
+	EventTypeRenderVolumeChanged:     "RenderVolumeChanged",
+	EventTypeCaptureVolumeChanged:    "CaptureVolumeChanged",
+	EventTypeRenderDeviceDetached:    "RenderDeviceDetached",
+	EventTypeCaptureDeviceDetached:   "CaptureDeviceDetached",
+	EventTypeDefaultRenderChanged:    "DefaultRenderChanged",
+	EventTypeDefaultCaptureChanged:   "DefaultCaptureChanged",
+}
+
+// String returns the event name, e.g. "RenderVolumeChanged".
+func (e EventType) String() string {
+	if int(e) < len(eventTypeNames) {
+		return eventTypeNames[e]
+	}
+	return "EventType(" + strconv.Itoa(int(e)) + ")"
+}
+
 type MessageType uint8
 
 //goland:noinspection GoUnusedConst
 const (
-	MessageTypeConfirmed  = 0
-	MessageTypeDiscovered = 1
-	//	MessageTypeDetached                          = 2
+	MessageTypeConfirmed             MessageType = 0
+	MessageTypeDiscovered            MessageType = 1
+	MessageTypeDetached              MessageType = 2
 	MessageTypeVolumeRenderChanged   MessageType = 3
 	MessageTypeVolumeCaptureChanged  MessageType = 4
 	MessageTypeDefaultRenderChanged  MessageType = 5
@@ -39,6 +67,7 @@ const (
 	FieldFlowType            = "flowType"
 	FieldName                = "name"
 	FieldPnpID               = "pnpId"
+	FieldPreviousPnpID       = "previousPnpId"
 	FieldRenderVolume        = "renderVolume"
 	FieldCaptureVolume       = "captureVolume"
 	FieldVolume              = "volume"
@@ -46,4 +75,5 @@ const (
 	FieldOperationSystemName = "operationSystemName"
 	FieldHTTPRequest         = "httpRequest"
 	FieldURLSuffix           = "urlSuffix"
+	FieldSchemaVersion       = "schemaVersion"
 )
@@ -0,0 +1,123 @@
+package encoding
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hamba/avro/v2"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+// avroEncoder writes the JSON body as the Avro record of its event.
+// events.avsc is an array of standalone records; each is parsed and
+// registered on its own.
+type avroEncoder struct {
+	records map[contract.EventType]*avro.RecordSchema
+	schemas map[contract.EventType]*Schema
+}
+
+func newAvroEncoder() (*avroEncoder, error) {
+	source, err := readFile(avroFile)
+	if err != nil {
+		return nil, err
+	}
+	var definitions []json.RawMessage
+	if err := json.Unmarshal(source, &definitions); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", avroFile, err)
+	}
+
+	byName := make(map[string]*Schema, len(definitions))
+	parsed := make(map[string]*avro.RecordSchema, len(definitions))
+	for _, definition := range definitions {
+		s, err := avro.ParseBytesWithCache(definition, "", &avro.SchemaCache{})
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", avroFile, err)
+		}
+		record, ok := s.(*avro.RecordSchema)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected records, got %s", avroFile, s.Type())
+		}
+		var text bytes.Buffer
+		if err := json.Compact(&text, definition); err != nil {
+			return nil, err
+		}
+		byName[record.Name()] = &Schema{Name: record.FullName(), Text: text.String()}
+		parsed[record.Name()] = record
+	}
+
+	e := &avroEncoder{
+		records: make(map[contract.EventType]*avro.RecordSchema),
+		schemas: make(map[contract.EventType]*Schema),
+	}
+	for event := contract.EventTypeRenderDeviceConfirmed; event <= contract.EventTypeDefaultCaptureChanged; event++ {
+		name, err := schemaName(event)
+		if err != nil {
+			return nil, err
+		}
+		record, ok := parsed[name]
+		if !ok {
+			return nil, fmt.Errorf("%s has no record %s", avroFile, name)
+		}
+		e.records[event] = record
+		e.schemas[event] = byName[name]
+	}
+	return e, nil
+}
+
+func (e *avroEncoder) Format() Format {
+	return FormatAvro
+}
+
+func (e *avroEncoder) Schemas() []Schema {
+	return orderedSchemas(e.schemas)
+}
+
+func (e *avroEncoder) Encode(payload enqueuer.RequestPayload) (Encoded, error) {
+	record, ok := e.records[payload.Event]
+	if !ok {
+		return Encoded{}, fmt.Errorf("no avro record for event %s", payload.Event)
+	}
+	value, err := avroValue(record, payload.Body)
+	if err != nil {
+		return Encoded{}, fmt.Errorf("map payload to %s: %w", record.FullName(), err)
+	}
+	body, err := avro.Marshal(record, value)
+	if err != nil {
+		return Encoded{}, fmt.Errorf("marshal %s: %w", record.FullName(), err)
+	}
+	return Encoded{Body: body, Schema: e.schemas[payload.Event]}, nil
+}
+
+// avroValue decodes the JSON body into the generic form the Avro codec
+// expects. Fields the record does not define are rejected rather than
+// silently dropped.
+func avroValue(record *avro.RecordSchema, body []byte) (map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value map[string]any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(record.Fields()))
+	for _, field := range record.Fields() {
+		known[field.Name()] = true
+	}
+	for key, v := range value {
+		if !known[key] {
+			return nil, fmt.Errorf("unknown field %q", key)
+		}
+		// Every numeric payload field is an Avro int.
+		if number, ok := v.(json.Number); ok {
+			n, err := number.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %w", key, err)
+			}
+			value[key] = int(n)
+		}
+	}
+	return value, nil
+}
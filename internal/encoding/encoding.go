@@ -0,0 +1,138 @@
+// Package encoding turns request payloads into their wire format: the JSON
+// body as built by the enqueuer, or a Protobuf or Avro record described by
+// the embedded schemas.
+package encoding
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+// Namespace is the Protobuf package and Avro namespace of the payload schemas.
+const Namespace = "collectsounddevices.winsoundscanner.v1"
+
+const (
+	protoFile = "events.proto"
+	avroFile  = "events.avsc"
+)
+
+//go:embed v1/events.proto v1/events.avsc
+var files embed.FS
+
+// Format names a payload encoding.
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatProtobuf Format = "protobuf"
+	FormatAvro     Format = "avro"
+)
+
+// ParseFormat accepts the format names case-insensitively; empty means JSON.
+func ParseFormat(raw string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(raw))); f {
+	case "":
+		return FormatJSON, nil
+	case FormatJSON, FormatProtobuf, FormatAvro:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported payload encoding %q (supported: %s, %s, %s)", raw, FormatJSON, FormatProtobuf, FormatAvro)
+	}
+}
+
+// ContentType returns the media type of a body in this format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatProtobuf:
+		return "application/x-protobuf"
+	case FormatAvro:
+		return "application/avro"
+	default:
+		return enqueuer.PayloadContentType
+	}
+}
+
+// Schema describes an encoded body, so it can be registered with a schema
+// registry.
+type Schema struct {
+	// Name is the fully qualified message or record name.
+	Name string
+	// Text is the schema document: the whole .proto file for Protobuf, the
+	// record definition for Avro.
+	Text string
+	// MessageIndexes locate a Protobuf message within Text; nil for Avro.
+	MessageIndexes []int
+}
+
+// Encoded is a payload body in an encoder's format.
+type Encoded struct {
+	Body []byte
+	// Schema is nil for JSON, which is published without a registry.
+	Schema *Schema
+}
+
+// Encoder converts request payloads into one format.
+type Encoder interface {
+	Format() Format
+	Encode(payload enqueuer.RequestPayload) (Encoded, error)
+	// Schemas returns the schema of every event type, in event order;
+	// nil for JSON.
+	Schemas() []Schema
+}
+
+// New returns the encoder of a format. The Protobuf and Avro schemas are
+// compiled here, so a broken definition fails on startup.
+func New(format Format) (Encoder, error) {
+	switch format {
+	case FormatJSON, "":
+		return jsonEncoder{}, nil
+	case FormatProtobuf:
+		return newProtobufEncoder()
+	case FormatAvro:
+		return newAvroEncoder()
+	default:
+		return nil, fmt.Errorf("unsupported payload encoding %q", format)
+	}
+}
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) Format() Format {
+	return FormatJSON
+}
+
+func (jsonEncoder) Encode(payload enqueuer.RequestPayload) (Encoded, error) {
+	return Encoded{Body: payload.Body}, nil
+}
+
+func (jsonEncoder) Schemas() []Schema {
+	return nil
+}
+
+// orderedSchemas lists the schemas of an encoder in event order.
+func orderedSchemas(schemas map[contract.EventType]*Schema) []Schema {
+	ordered := make([]Schema, 0, len(schemas))
+	for event := contract.EventTypeRenderDeviceConfirmed; event <= contract.EventTypeDefaultCaptureChanged; event++ {
+		if schema, ok := schemas[event]; ok {
+			ordered = append(ordered, *schema)
+		}
+	}
+	return ordered
+}
+
+// schemaName returns the message or record name of an event, e.g.
+// "RenderVolumeChanged".
+func schemaName(event contract.EventType) (string, error) {
+	if event == contract.EventTypeNothing || strings.HasPrefix(event.String(), "EventType(") {
+		return "", fmt.Errorf("no payload schema for event %s", event)
+	}
+	return event.String(), nil
+}
+
+func readFile(name string) ([]byte, error) {
+	return files.ReadFile("v1/" + name)
+}
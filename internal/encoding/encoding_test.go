@@ -0,0 +1,203 @@
+package encoding
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+const updateDate = "2026-05-26T10:00:00.000000Z"
+
+// scannerPayloads builds one payload per event type with the fields the
+// scanner sends.
+func scannerPayloads(t *testing.T) []enqueuer.RequestPayload {
+	t.Helper()
+	device := map[string]string{
+		contract.FieldUpdateDate:          updateDate,
+		contract.FieldName:                "Speakers",
+		contract.FieldPnpID:               "pnp-1",
+		contract.FieldRenderVolume:        "30",
+		contract.FieldCaptureVolume:       "0",
+		contract.FieldOperationSystemName: "Windows 11",
+		contract.FieldHostName:            "host-1",
+	}
+	switched := map[string]string{contract.FieldPreviousPnpID: "pnp-0"}
+	for k, v := range device {
+		switched[k] = v
+	}
+	volume := map[string]string{
+		contract.FieldUpdateDate: updateDate,
+		contract.FieldVolume:     "40",
+		contract.FieldHostName:   "host-1",
+		contract.FieldPnpID:      "pnp-1",
+	}
+	detached := map[string]string{
+		contract.FieldUpdateDate: updateDate,
+		contract.FieldName:       "Speakers",
+		contract.FieldPnpID:      "pnp-1",
+		contract.FieldHostName:   "host-1",
+	}
+	fields := map[contract.EventType]map[string]string{
+		contract.EventTypeRenderDeviceConfirmed:   device,
+		contract.EventTypeCaptureDeviceConfirmed:  device,
+		contract.EventTypeRenderDeviceDiscovered:  device,
+		contract.EventTypeCaptureDeviceDiscovered: device,
+		contract.EventTypeRenderVolumeChanged:     volume,
+		contract.EventTypeCaptureVolumeChanged:    volume,
+		contract.EventTypeRenderDeviceDetached:    detached,
+		contract.EventTypeCaptureDeviceDetached:   detached,
+		contract.EventTypeDefaultRenderChanged:    switched,
+		contract.EventTypeDefaultCaptureChanged:   switched,
+	}
+
+	payloads := make([]enqueuer.RequestPayload, 0, len(fields))
+	for event, f := range fields {
+		payload, err := enqueuer.BuildRequestPayload(enqueuer.Request{Timestamp: time.Now(), Event: event, Fields: f})
+		if err != nil {
+			t.Fatalf("BuildRequestPayload failed: %v", err)
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads
+}
+
+func decodeJSON(t *testing.T, body []byte) map[string]any {
+	t.Helper()
+	var value map[string]any
+	if err := json.Unmarshal(body, &value); err != nil {
+		t.Fatalf("decode JSON: %v", err)
+	}
+	return value
+}
+
+// assertSameFields compares a decoded record with the JSON payload. Zero
+// values are allowed to be absent, as proto3 does not write them.
+func assertSameFields(t *testing.T, event contract.EventType, want, got map[string]any) {
+	t.Helper()
+	for key, value := range want {
+		gotValue, ok := got[key]
+		if !ok && (value == "" || value == float64(0)) {
+			continue
+		}
+		if !ok || gotValue != value {
+			t.Fatalf("%s: field %s = %v, want %v", event, key, gotValue, value)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	cases := map[string]Format{"": FormatJSON, "JSON": FormatJSON, " protobuf ": FormatProtobuf, "Avro": FormatAvro}
+	for raw, want := range cases {
+		got, err := ParseFormat(raw)
+		if err != nil || got != want {
+			t.Fatalf("ParseFormat(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Fatal("expected error for unsupported format")
+	}
+}
+
+func TestJSONEncoder_ReturnsBodyWithoutSchema(t *testing.T) {
+	encoder, err := New(FormatJSON)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	payload := scannerPayloads(t)[0]
+	encoded, err := encoder.Encode(payload)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if string(encoded.Body) != string(payload.Body) || encoded.Schema != nil {
+		t.Fatalf("unexpected JSON encoding: %+v", encoded)
+	}
+}
+
+func TestProtobufEncoder_RoundTripsEveryEventType(t *testing.T) {
+	encoder, err := newProtobufEncoder()
+	if err != nil {
+		t.Fatalf("newProtobufEncoder failed: %v", err)
+	}
+	for _, payload := range scannerPayloads(t) {
+		encoded, err := encoder.Encode(payload)
+		if err != nil {
+			t.Fatalf("%s: Encode failed: %v", payload.Event, err)
+		}
+		descriptor := encoder.messages[payload.Event]
+		if encoded.Schema.Name != Namespace+"."+payload.Event.String() ||
+			len(encoded.Schema.MessageIndexes) != 1 || encoded.Schema.MessageIndexes[0] != descriptor.Index() {
+			t.Fatalf("%s: unexpected schema %s %v", payload.Event, encoded.Schema.Name, encoded.Schema.MessageIndexes)
+		}
+
+		message := dynamicpb.NewMessage(descriptor)
+		if err := proto.Unmarshal(encoded.Body, message); err != nil {
+			t.Fatalf("%s: Unmarshal failed: %v", payload.Event, err)
+		}
+		decoded, err := protojson.Marshal(message)
+		if err != nil {
+			t.Fatalf("%s: protojson failed: %v", payload.Event, err)
+		}
+		assertSameFields(t, payload.Event, decodeJSON(t, payload.Body), decodeJSON(t, decoded))
+	}
+}
+
+func TestAvroEncoder_RoundTripsEveryEventType(t *testing.T) {
+	encoder, err := newAvroEncoder()
+	if err != nil {
+		t.Fatalf("newAvroEncoder failed: %v", err)
+	}
+	for _, payload := range scannerPayloads(t) {
+		encoded, err := encoder.Encode(payload)
+		if err != nil {
+			t.Fatalf("%s: Encode failed: %v", payload.Event, err)
+		}
+		if encoded.Schema.Name != Namespace+"."+payload.Event.String() || encoded.Schema.MessageIndexes != nil {
+			t.Fatalf("%s: unexpected schema %+v", payload.Event, encoded.Schema)
+		}
+
+		// Decode with the registered text, as a consumer would.
+		writer, err := avro.ParseWithCache(encoded.Schema.Text, "", &avro.SchemaCache{})
+		if err != nil {
+			t.Fatalf("%s: parse registered schema: %v", payload.Event, err)
+		}
+		var decoded map[string]any
+		if err := avro.Unmarshal(writer, encoded.Body, &decoded); err != nil {
+			t.Fatalf("%s: Unmarshal failed: %v", payload.Event, err)
+		}
+		for key, value := range decoded {
+			if n, ok := value.(int); ok {
+				decoded[key] = float64(n)
+			}
+		}
+		assertSameFields(t, payload.Event, decodeJSON(t, payload.Body), decoded)
+	}
+}
+
+func TestEncoders_RejectFieldsMissingFromTheSchema(t *testing.T) {
+	payload := scannerPayloads(t)[0]
+	value := decodeJSON(t, payload.Body)
+	value["color"] = "blue"
+	body, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	payload.Body = body
+
+	for _, format := range []Format{FormatProtobuf, FormatAvro} {
+		encoder, err := New(format)
+		if err != nil {
+			t.Fatalf("New(%s) failed: %v", format, err)
+		}
+		if _, err := encoder.Encode(payload); err == nil {
+			t.Fatalf("%s: expected error for unknown field", format)
+		}
+	}
+}
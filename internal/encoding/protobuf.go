@@ -0,0 +1,86 @@
+package encoding
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+// protobufEncoder compiles events.proto at runtime and fills dynamic
+// messages from the JSON body through the proto3 JSON mapping, so the
+// .proto file stays the only definition of the messages.
+type protobufEncoder struct {
+	messages map[contract.EventType]protoreflect.MessageDescriptor
+	schemas  map[contract.EventType]*Schema
+}
+
+func newProtobufEncoder() (*protobufEncoder, error) {
+	source, err := readFile(protoFile)
+	if err != nil {
+		return nil, err
+	}
+	compiler := protocompile.Compiler{
+		Resolver: &protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(map[string]string{protoFile: string(source)}),
+		},
+	}
+	compiled, err := compiler.Compile(context.Background(), protoFile)
+	if err != nil {
+		return nil, fmt.Errorf("compile %s: %w", protoFile, err)
+	}
+	file := compiled[0]
+
+	e := &protobufEncoder{
+		messages: make(map[contract.EventType]protoreflect.MessageDescriptor),
+		schemas:  make(map[contract.EventType]*Schema),
+	}
+	for event := contract.EventTypeRenderDeviceConfirmed; event <= contract.EventTypeDefaultCaptureChanged; event++ {
+		name, err := schemaName(event)
+		if err != nil {
+			return nil, err
+		}
+		message := file.Messages().ByName(protoreflect.Name(name))
+		if message == nil {
+			return nil, fmt.Errorf("%s has no message %s", protoFile, name)
+		}
+		e.messages[event] = message
+		e.schemas[event] = &Schema{
+			Name:           string(message.FullName()),
+			Text:           string(source),
+			MessageIndexes: []int{message.Index()},
+		}
+	}
+	return e, nil
+}
+
+func (e *protobufEncoder) Format() Format {
+	return FormatProtobuf
+}
+
+func (e *protobufEncoder) Schemas() []Schema {
+	return orderedSchemas(e.schemas)
+}
+
+func (e *protobufEncoder) Encode(payload enqueuer.RequestPayload) (Encoded, error) {
+	descriptor, ok := e.messages[payload.Event]
+	if !ok {
+		return Encoded{}, fmt.Errorf("no protobuf message for event %s", payload.Event)
+	}
+	message := dynamicpb.NewMessage(descriptor)
+	if err := protojson.Unmarshal(payload.Body, message); err != nil {
+		return Encoded{}, fmt.Errorf("map payload to %s: %w", descriptor.FullName(), err)
+	}
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+	if err != nil {
+		return Encoded{}, fmt.Errorf("marshal %s: %w", descriptor.FullName(), err)
+	}
+	return Encoded{Body: body, Schema: e.schemas[payload.Event]}, nil
+}
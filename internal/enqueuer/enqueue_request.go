@@ -1,15 +1,23 @@
 package enqueuer
 
 import (
+	"errors"
 	"time"
 
 	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
 )
 
+// ErrRejected marks a request the destination refused permanently;
+// retrying the same request can not succeed.
+var ErrRejected = errors.New("request rejected")
+
 type Request struct {
 	Timestamp time.Time
 	Event     contract.EventType
 	Fields    map[string]string
+	// TraceParent is the W3C traceparent of the span that enqueued the
+	// request; empty when tracing is off.
+	TraceParent string
 }
 
 type EnqueueRequest interface {
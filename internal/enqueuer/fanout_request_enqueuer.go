@@ -0,0 +1,68 @@
+package enqueuer
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// Sink is one destination of a FanOutRequestEnqueuer.
+type Sink struct {
+	Name     string
+	Enqueuer EnqueueRequest
+	// Required sinks make EnqueueRequest fail; failures of best-effort
+	// sinks are only logged. Behind an outbox EnqueueRequest is the local
+	// append, so delivery itself is governed by the outbox configuration.
+	Required bool
+}
+
+// FanOutRequestEnqueuer hands every request to all sinks in parallel, so a
+// slow sink delays the call but never the delivery to the other sinks.
+type FanOutRequestEnqueuer struct {
+	sinks  []Sink
+	logger *slog.Logger
+}
+
+func NewFanOutRequestEnqueuer(sinks []Sink, logger *slog.Logger) *FanOutRequestEnqueuer {
+	if logger == nil {
+		panic("nil logger")
+	}
+	if len(sinks) == 0 {
+		panic("no sinks")
+	}
+	for _, sink := range sinks {
+		if sink.Enqueuer == nil {
+			panic("nil sink enqueuer")
+		}
+	}
+	return &FanOutRequestEnqueuer{sinks: append([]Sink(nil), sinks...), logger: logger}
+}
+
+// EnqueueRequest returns the joined errors of the required sinks, each
+// prefixed with the sink name.
+func (f *FanOutRequestEnqueuer) EnqueueRequest(request Request) error {
+	errs := make([]error, len(f.sinks))
+	var wg sync.WaitGroup
+	for i, sink := range f.sinks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = sink.Enqueuer.EnqueueRequest(request)
+		}()
+	}
+	wg.Wait()
+
+	var required []error
+	for i, sink := range f.sinks {
+		if errs[i] == nil {
+			continue
+		}
+		if !sink.Required {
+			f.logger.Warn("Best-effort sink failed", "sink", sink.Name, "event", request.Event, "err", errs[i])
+			continue
+		}
+		required = append(required, fmt.Errorf("%s: %w", sink.Name, errs[i]))
+	}
+	return errors.Join(required...)
+}
@@ -0,0 +1,76 @@
+package enqueuer
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+)
+
+type funcEnqueuer func(Request) error
+
+func (f funcEnqueuer) EnqueueRequest(request Request) error {
+	return f(request)
+}
+
+func TestFanOut_DeliversToAllSinksInParallel(t *testing.T) {
+	var delivered atomic.Int32
+	slow := funcEnqueuer(func(Request) error {
+		time.Sleep(50 * time.Millisecond)
+		delivered.Add(1)
+		return nil
+	})
+	sut := NewFanOutRequestEnqueuer([]Sink{
+		{Name: "a", Enqueuer: slow, Required: true},
+		{Name: "b", Enqueuer: slow, Required: true},
+		{Name: "c", Enqueuer: slow},
+	}, slog.Default())
+
+	start := time.Now()
+	if err := sut.EnqueueRequest(Request{Event: contract.EventTypeRenderVolumeChanged}); err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+	if delivered.Load() != 3 {
+		t.Fatalf("expected 3 deliveries, got %d", delivered.Load())
+	}
+	if elapsed := time.Since(start); elapsed > 140*time.Millisecond {
+		t.Fatalf("sinks were not called in parallel, took %s", elapsed)
+	}
+}
+
+func TestFanOut_AggregatesRequiredErrorsOnly(t *testing.T) {
+	ok := funcEnqueuer(func(Request) error { return nil })
+	sut := NewFanOutRequestEnqueuer([]Sink{
+		{Name: "rabbitmq", Enqueuer: funcEnqueuer(func(Request) error { return errors.New("broker down") }), Required: true},
+		{Name: "kafka", Enqueuer: funcEnqueuer(func(Request) error { return ErrRejected }), Required: true},
+		{Name: "file", Enqueuer: funcEnqueuer(func(Request) error { return errors.New("disk full") })},
+		{Name: "http", Enqueuer: ok, Required: true},
+	}, slog.Default())
+
+	err := sut.EnqueueRequest(Request{Event: contract.EventTypeRenderVolumeChanged})
+	if err == nil {
+		t.Fatal("expected aggregated error")
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "rabbitmq: broker down") || !strings.Contains(msg, "kafka: ") || strings.Contains(msg, "disk full") {
+		t.Fatalf("unexpected aggregated error: %q", msg)
+	}
+	if !errors.Is(err, ErrRejected) {
+		t.Fatalf("expected aggregated error to wrap ErrRejected: %v", err)
+	}
+}
+
+func TestFanOut_BestEffortFailureIsNotReturned(t *testing.T) {
+	sut := NewFanOutRequestEnqueuer([]Sink{
+		{Name: "rabbitmq", Enqueuer: funcEnqueuer(func(Request) error { return nil }), Required: true},
+		{Name: "file", Enqueuer: funcEnqueuer(func(Request) error { return errors.New("disk full") })},
+	}, slog.Default())
+
+	if err := sut.EnqueueRequest(Request{Event: contract.EventTypeRenderVolumeChanged}); err != nil {
+		t.Fatalf("expected best-effort failure to be ignored, got %v", err)
+	}
+}
@@ -1,12 +1,23 @@
 package enqueuer
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/schema"
+)
+
+const (
+	// PayloadContentType is the media type of RequestPayload.Body.
+	PayloadContentType = "application/json"
+	// PayloadSchemaVersion is the version of the JSON Schemas in package
+	// schema; it is stamped into every body as schemaVersion.
+	PayloadSchemaVersion = schema.Version
 )
 
 type RequestPayload struct {
@@ -15,15 +26,29 @@ type RequestPayload struct {
 	URLSuffix     string
 	DeviceKey     string
 	UpdateDateUtc string
+
+	// Routing metadata, so transports can describe a message without
+	// parsing Body.
+	Event       contract.EventType
+	MessageType contract.MessageType
+	FlowType    contract.FlowType
+	HostName    string
+	PnpID       string
+	// EventTime is when the scanner observed the event.
+	EventTime time.Time
+	// EventID is stable across retries of the same request, so consumers
+	// can deduplicate redeliveries.
+	EventID string
 }
 
 func BuildRequestPayload(request Request) (RequestPayload, error) {
-	payload := make(map[string]any, len(request.Fields)+4)
+	payload := make(map[string]any, len(request.Fields)+5)
 	for key, value := range request.Fields {
 		payload[key] = normalizeValue(key, value)
 	}
+	payload[contract.FieldSchemaVersion] = PayloadSchemaVersion
 
-	deviceKey := buildDeviceKey(request.Fields)
+	deviceKey := DeviceKey(request.Fields)
 	flowType, messageType := calculateFlowAndMessageType(request.Event)
 	payload[contract.FieldDeviceMessageType] = messageType
 
@@ -52,9 +77,26 @@ func BuildRequestPayload(request Request) (RequestPayload, error) {
 		URLSuffix:     urlSuffix,
 		DeviceKey:     deviceKey,
 		UpdateDateUtc: updateDateUtc,
+		Event:         request.Event,
+		MessageType:   messageType,
+		FlowType:      flowType,
+		HostName:      strings.TrimSpace(request.Fields[contract.FieldHostName]),
+		PnpID:         strings.TrimSpace(request.Fields[contract.FieldPnpID]),
+		EventTime:     request.Timestamp,
+		EventID:       eventID(deviceKey, request.Event, updateDateUtc),
 	}, nil
 }
 
+// eventID derives a UUID-formatted (version 8, RFC 9562) identifier from the
+// device, the event and its update time.
+func eventID(deviceKey string, event contract.EventType, updateDateUtc string) string {
+	sum := sha256.Sum256([]byte(deviceKey + "|" + event.String() + "|" + updateDateUtc))
+	id := sum[:16]
+	id[6] = id[6]&0x0f | 0x80
+	id[8] = id[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16])
+}
+
 func resolveHttpRequest(request Request, payload map[string]any) (string, string) {
 	var httpRequest string
 
@@ -62,14 +104,19 @@ func resolveHttpRequest(request Request, payload map[string]any) (string, string
 	case contract.EventTypeRenderDeviceDiscovered,
 		contract.EventTypeCaptureDeviceDiscovered,
 		contract.EventTypeRenderDeviceConfirmed,
-		contract.EventTypeCaptureDeviceConfirmed:
+		contract.EventTypeCaptureDeviceConfirmed,
+		contract.EventTypeDefaultRenderChanged,
+		contract.EventTypeDefaultCaptureChanged:
 		httpRequest = "POST"
+	case contract.EventTypeRenderDeviceDetached,
+		contract.EventTypeCaptureDeviceDetached:
+		httpRequest = "DELETE"
 	default:
 		httpRequest = "PUT"
 	}
 
 	urlSuffix := readStringField(payload, contract.FieldURLSuffix)
-	if urlSuffix == "" && httpRequest == "PUT" {
+	if urlSuffix == "" && httpRequest != "POST" {
 		pnpID := readStringField(payload, contract.FieldPnpID)
 		hostName := readStringField(payload, contract.FieldHostName)
 
@@ -95,9 +142,11 @@ func calculateFlowAndMessageType(event contract.EventType) (contract.FlowType, c
 	var message contract.MessageType
 
 	switch event {
-	case contract.EventTypeRenderDeviceConfirmed, contract.EventTypeRenderDeviceDiscovered, contract.EventTypeRenderVolumeChanged:
+	case contract.EventTypeRenderDeviceConfirmed, contract.EventTypeRenderDeviceDiscovered, contract.EventTypeRenderVolumeChanged,
+		contract.EventTypeRenderDeviceDetached, contract.EventTypeDefaultRenderChanged:
 		flow = contract.FlowTypeRender
-	case contract.EventTypeCaptureDeviceConfirmed, contract.EventTypeCaptureDeviceDiscovered, contract.EventTypeCaptureVolumeChanged:
+	case contract.EventTypeCaptureDeviceConfirmed, contract.EventTypeCaptureDeviceDiscovered, contract.EventTypeCaptureVolumeChanged,
+		contract.EventTypeCaptureDeviceDetached, contract.EventTypeDefaultCaptureChanged:
 		flow = contract.FlowTypeCapture
 	default:
 		flow = 0
@@ -112,6 +161,12 @@ func calculateFlowAndMessageType(event contract.EventType) (contract.FlowType, c
 		message = contract.MessageTypeVolumeRenderChanged
 	case contract.EventTypeCaptureVolumeChanged:
 		message = contract.MessageTypeVolumeCaptureChanged
+	case contract.EventTypeRenderDeviceDetached, contract.EventTypeCaptureDeviceDetached:
+		message = contract.MessageTypeDetached
+	case contract.EventTypeDefaultRenderChanged:
+		message = contract.MessageTypeDefaultRenderChanged
+	case contract.EventTypeDefaultCaptureChanged:
+		message = contract.MessageTypeDefaultCaptureChanged
 	default:
 		message = 0
 	}
@@ -130,7 +185,8 @@ func normalizeValue(key string, value string) any {
 	return value
 }
 
-func buildDeviceKey(fields map[string]string) string {
+// DeviceKey identifies a device across requests as "hostName|pnpId".
+func DeviceKey(fields map[string]string) string {
 	pnpID := strings.TrimSpace(fields[contract.FieldPnpID])
 	hostName := strings.TrimSpace(fields[contract.FieldHostName])
 	if pnpID != "" && hostName != "" {
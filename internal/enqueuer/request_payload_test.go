@@ -60,6 +60,40 @@ func TestBuildRequestPayload_VolumeEventProducesPutAndUrlSuffix(t *testing.T) {
 	if _, ok := payload[contract.FieldHostName]; ok {
 		t.Fatalf("expected %q to be removed from PUT payload", contract.FieldHostName)
 	}
+	if result.Event != request.Event || result.MessageType != contract.MessageTypeVolumeRenderChanged || result.FlowType != contract.FlowTypeRender {
+		t.Fatalf("unexpected routing metadata: %+v", result)
+	}
+	assertString(t, result.HostName, "host-1")
+	assertString(t, result.PnpID, "pnp-1")
+	if !result.EventTime.Equal(request.Timestamp) {
+		t.Fatalf("expected event time %s, got %s", request.Timestamp, result.EventTime)
+	}
+}
+
+func TestBuildRequestPayload_EventIDIsStablePerEvent(t *testing.T) {
+	request := Request{
+		Timestamp: time.Date(2026, 5, 26, 10, 0, 0, 0, time.UTC),
+		Event:     contract.EventTypeRenderVolumeChanged,
+		Fields:    map[string]string{contract.FieldPnpID: "pnp-1", contract.FieldHostName: "host-1"},
+	}
+
+	first, err := BuildRequestPayload(request)
+	if err != nil {
+		t.Fatalf("BuildRequestPayload failed: %v", err)
+	}
+	retry, _ := BuildRequestPayload(request)
+	request.Timestamp = request.Timestamp.Add(time.Second)
+	next, _ := BuildRequestPayload(request)
+
+	if len(first.EventID) != 36 || first.EventID[14] != '8' {
+		t.Fatalf("expected a version 8 UUID, got %q", first.EventID)
+	}
+	if first.EventID != retry.EventID {
+		t.Fatalf("expected retries to share the event ID: %q != %q", first.EventID, retry.EventID)
+	}
+	if first.EventID == next.EventID {
+		t.Fatalf("expected a new event ID for a later event, got %q twice", first.EventID)
+	}
 }
 
 func TestBuildRequestPayload_ExplicitUpdateDateIsPreserved(t *testing.T) {
@@ -82,6 +116,58 @@ func TestBuildRequestPayload_ExplicitUpdateDateIsPreserved(t *testing.T) {
 	assertNumber(t, payload[contract.FieldFlowType], float64(contract.FlowTypeCapture))
 }
 
+func TestBuildRequestPayload_DetachedEventProducesDelete(t *testing.T) {
+	request := Request{
+		Timestamp: time.Date(2026, 5, 26, 10, 0, 0, 0, time.UTC),
+		Event:     contract.EventTypeCaptureDeviceDetached,
+		Fields: map[string]string{
+			contract.FieldName:     "USB Microphone",
+			contract.FieldPnpID:    "pnp-2",
+			contract.FieldHostName: "host-1",
+		},
+	}
+
+	result, err := BuildRequestPayload(request)
+	if err != nil {
+		t.Fatalf("BuildRequestPayload failed: %v", err)
+	}
+	payload := decodePayload(t, result.Body)
+
+	assertString(t, result.HTTPRequest, "DELETE")
+	assertString(t, result.URLSuffix, "/pnp-2/host-1")
+	assertString(t, result.DeviceKey, "host-1|pnp-2")
+	assertNumber(t, payload[contract.FieldDeviceMessageType], float64(contract.MessageTypeDetached))
+	if _, ok := payload[contract.FieldHostName]; ok {
+		t.Fatalf("expected %q to be removed from DELETE payload", contract.FieldHostName)
+	}
+}
+
+func TestBuildRequestPayload_DefaultChangedEventCarriesPreviousDevice(t *testing.T) {
+	request := Request{
+		Timestamp: time.Date(2026, 5, 26, 10, 0, 0, 0, time.UTC),
+		Event:     contract.EventTypeDefaultRenderChanged,
+		Fields: map[string]string{
+			contract.FieldName:          "USB Headset",
+			contract.FieldPnpID:         "pnp-2",
+			contract.FieldPreviousPnpID: "pnp-1",
+			contract.FieldHostName:      "host-1",
+		},
+	}
+
+	result, err := BuildRequestPayload(request)
+	if err != nil {
+		t.Fatalf("BuildRequestPayload failed: %v", err)
+	}
+	payload := decodePayload(t, result.Body)
+
+	assertString(t, result.HTTPRequest, "POST")
+	assertString(t, result.URLSuffix, "")
+	assertString(t, result.DeviceKey, "host-1|pnp-2")
+	assertString(t, payload[contract.FieldPreviousPnpID], "pnp-1")
+	assertNumber(t, payload[contract.FieldDeviceMessageType], float64(contract.MessageTypeDefaultRenderChanged))
+	assertNumber(t, payload[contract.FieldFlowType], float64(contract.FlowTypeRender))
+}
+
 func decodePayload(t *testing.T, body []byte) map[string]any {
 	t.Helper()
 	var payload map[string]any
@@ -0,0 +1,42 @@
+package enqueuer
+
+import "sync"
+
+// SwappableRequestEnqueuer forwards requests to an enqueuer that can be
+// replaced while the scanner runs, e.g. after a configuration reload.
+type SwappableRequestEnqueuer struct {
+	mu   sync.RWMutex
+	next EnqueueRequest
+}
+
+func NewSwappableRequestEnqueuer(next EnqueueRequest) *SwappableRequestEnqueuer {
+	if next == nil {
+		panic("nil enqueuer")
+	}
+	return &SwappableRequestEnqueuer{next: next}
+}
+
+func (s *SwappableRequestEnqueuer) EnqueueRequest(request Request) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.next.EnqueueRequest(request)
+}
+
+// Swap holds back new requests, waits for the in-flight ones to return and
+// then replaces the enqueuer with the one replace returns, so replace can
+// close the current enqueuer safely. When replace fails the current enqueuer
+// is kept; a replace that closed it first should return a reopened one
+// instead of failing.
+func (s *SwappableRequestEnqueuer) Swap(replace func() (EnqueueRequest, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next, err := replace()
+	if err != nil {
+		return err
+	}
+	if next == nil {
+		panic("nil enqueuer")
+	}
+	s.next = next
+	return nil
+}
@@ -0,0 +1,63 @@
+package enqueuer
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSwappableEnqueuer_SwapWaitsForInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var oldDone, newCalls atomic.Int32
+	old := funcEnqueuer(func(Request) error {
+		close(started)
+		<-release
+		oldDone.Add(1)
+		return nil
+	})
+	sut := NewSwappableRequestEnqueuer(old)
+
+	go func() { _ = sut.EnqueueRequest(Request{}) }()
+	<-started
+
+	swapped := make(chan error, 1)
+	go func() {
+		swapped <- sut.Swap(func() (EnqueueRequest, error) {
+			if oldDone.Load() != 1 {
+				t.Error("replace ran before the in-flight request returned")
+			}
+			return funcEnqueuer(func(Request) error { newCalls.Add(1); return nil }), nil
+		})
+	}()
+	select {
+	case <-swapped:
+		t.Fatal("Swap returned while a request was in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	if err := <-swapped; err != nil {
+		t.Fatalf("Swap failed: %v", err)
+	}
+
+	if err := sut.EnqueueRequest(Request{}); err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+	if newCalls.Load() != 1 {
+		t.Fatalf("expected the request to reach the new enqueuer, got %d calls", newCalls.Load())
+	}
+}
+
+func TestSwappableEnqueuer_FailedSwapKeepsCurrent(t *testing.T) {
+	var calls int
+	sut := NewSwappableRequestEnqueuer(funcEnqueuer(func(Request) error { calls++; return nil }))
+
+	boom := errors.New("broker unreachable")
+	if err := sut.Swap(func() (EnqueueRequest, error) { return nil, boom }); !errors.Is(err, boom) {
+		t.Fatalf("expected the replace error, got %v", err)
+	}
+	if err := sut.EnqueueRequest(Request{}); err != nil || calls != 1 {
+		t.Fatalf("expected the current enqueuer to stay, calls=%d err=%v", calls, err)
+	}
+}
@@ -0,0 +1,49 @@
+package enqueuer
+
+import (
+	"log/slog"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/schema"
+)
+
+// RejectedEventWriter records requests that are never handed to a transport.
+type RejectedEventWriter interface {
+	WriteRejected(request Request, reason error) error
+}
+
+// ValidatingRequestEnqueuer checks every payload against its JSON Schema
+// before passing the request on. Invalid requests go to the rejected-events
+// log instead, since no retry can make them valid.
+type ValidatingRequestEnqueuer struct {
+	next     EnqueueRequest
+	rejected RejectedEventWriter
+	logger   *slog.Logger
+}
+
+func NewValidatingRequestEnqueuer(next EnqueueRequest, rejected RejectedEventWriter, logger *slog.Logger) *ValidatingRequestEnqueuer {
+	if next == nil {
+		panic("nil enqueuer")
+	}
+	if rejected == nil {
+		panic("nil rejected event writer")
+	}
+	if logger == nil {
+		panic("nil logger")
+	}
+	return &ValidatingRequestEnqueuer{next: next, rejected: rejected, logger: logger}
+}
+
+func (v *ValidatingRequestEnqueuer) EnqueueRequest(request Request) error {
+	payload, err := BuildRequestPayload(request)
+	if err == nil {
+		err = schema.Validate(payload.MessageType, payload.Body)
+	}
+	if err != nil {
+		v.logger.Error("Request failed schema validation, not published", "event", request.Event, "err", err)
+		if writeErr := v.rejected.WriteRejected(request, err); writeErr != nil {
+			v.logger.Error("Rejected event could not be written", "event", request.Event, "err", writeErr)
+		}
+		return nil
+	}
+	return v.next.EnqueueRequest(request)
+}
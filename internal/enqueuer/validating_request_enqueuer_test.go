@@ -0,0 +1,61 @@
+package enqueuer
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+)
+
+type rejectedRecorder struct {
+	requests []Request
+	reasons  []error
+}
+
+func (r *rejectedRecorder) WriteRejected(request Request, reason error) error {
+	r.requests = append(r.requests, request)
+	r.reasons = append(r.reasons, reason)
+	return nil
+}
+
+func TestValidatingEnqueuer_PassesValidRequests(t *testing.T) {
+	var passed int
+	rejected := &rejectedRecorder{}
+	sut := NewValidatingRequestEnqueuer(funcEnqueuer(func(Request) error { passed++; return nil }), rejected, slog.Default())
+
+	err := sut.EnqueueRequest(Request{
+		Timestamp: time.Now(),
+		Event:     contract.EventTypeRenderVolumeChanged,
+		Fields: map[string]string{
+			contract.FieldUpdateDate: "2026-05-26T10:00:00.000000Z",
+			contract.FieldVolume:     "40",
+			contract.FieldPnpID:      "pnp-1",
+			contract.FieldHostName:   "host-1",
+		},
+	})
+	if err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+	if passed != 1 || len(rejected.requests) != 0 {
+		t.Fatalf("expected the request to pass, passed=%d rejected=%d", passed, len(rejected.requests))
+	}
+}
+
+func TestValidatingEnqueuer_DivertsInvalidRequests(t *testing.T) {
+	var passed int
+	rejected := &rejectedRecorder{}
+	sut := NewValidatingRequestEnqueuer(funcEnqueuer(func(Request) error { passed++; return nil }), rejected, slog.Default())
+
+	err := sut.EnqueueRequest(Request{
+		Timestamp: time.Now(),
+		Event:     contract.EventTypeRenderVolumeChanged,
+		Fields:    map[string]string{contract.FieldVolume: "muted", contract.FieldHostName: "host-1"},
+	})
+	if err != nil {
+		t.Fatalf("expected the invalid request to be handled, got %v", err)
+	}
+	if passed != 0 || len(rejected.requests) != 1 || rejected.reasons[0] == nil {
+		t.Fatalf("expected the request to be rejected, passed=%d rejected=%d", passed, len(rejected.requests))
+	}
+}
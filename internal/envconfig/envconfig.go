@@ -0,0 +1,81 @@
+// Package envconfig reads typed settings from environment variables for the
+// LoadConfigFromEnv functions. An unset or blank variable yields the
+// fallback; a malformed one an error naming the variable.
+package envconfig
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// String returns the value as set, without trimming.
+func String(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// TrimmedString returns the value without surrounding whitespace.
+func TrimmedString(key, fallback string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func Int(key string, fallback int) (int, error) {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", key, v, err)
+	}
+
+	return n, nil
+}
+
+func NonNegativeInt(key string, fallback int) (int, error) {
+	n, err := Int(key, fallback)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("%s can not be negative %q", key, strings.TrimSpace(os.Getenv(key)))
+	}
+	return n, nil
+}
+
+// Millis reads a non-negative number of milliseconds.
+func Millis(key string, fallback time.Duration) (time.Duration, error) {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", key, v, err)
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("%s can not be negative %q", key, v)
+	}
+	return time.Duration(n) * time.Millisecond, nil
+}
+
+func Bool(key string, fallback bool) (bool, error) {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: %w", key, v, err)
+	}
+	return b, nil
+}
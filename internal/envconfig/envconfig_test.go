@@ -0,0 +1,56 @@
+package envconfig
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testKey = "WIN_SOUND_ENVCONFIG_TEST"
+
+func TestReaders_FallBackWhenBlank(t *testing.T) {
+	t.Setenv(testKey, "  ")
+
+	if got := String(testKey, "x"); got != "  " {
+		t.Fatalf("String: expected the untrimmed value, got %q", got)
+	}
+	if got := TrimmedString(testKey, "x"); got != "x" {
+		t.Fatalf("TrimmedString: expected the fallback, got %q", got)
+	}
+	if got, err := Int(testKey, 7); err != nil || got != 7 {
+		t.Fatalf("Int: got %d, %v", got, err)
+	}
+	if got, err := Millis(testKey, time.Second); err != nil || got != time.Second {
+		t.Fatalf("Millis: got %s, %v", got, err)
+	}
+	if got, err := Bool(testKey, true); err != nil || !got {
+		t.Fatalf("Bool: got %v, %v", got, err)
+	}
+}
+
+func TestReaders_ParseValues(t *testing.T) {
+	t.Setenv(testKey, " 250 ")
+	if got, err := Millis(testKey, 0); err != nil || got != 250*time.Millisecond {
+		t.Fatalf("Millis: got %s, %v", got, err)
+	}
+	if got, err := NonNegativeInt(testKey, 0); err != nil || got != 250 {
+		t.Fatalf("NonNegativeInt: got %d, %v", got, err)
+	}
+	t.Setenv(testKey, "false")
+	if got, err := Bool(testKey, true); err != nil || got {
+		t.Fatalf("Bool: got %v, %v", got, err)
+	}
+}
+
+func TestReaders_ReportMalformedValues(t *testing.T) {
+	t.Setenv(testKey, "-5")
+	for name, read := range map[string]func() error{
+		"NonNegativeInt": func() error { _, err := NonNegativeInt(testKey, 0); return err },
+		"Millis":         func() error { _, err := Millis(testKey, 0); return err },
+		"Bool":           func() error { _, err := Bool(testKey, false); return err },
+	} {
+		if err := read(); err == nil || !strings.Contains(err.Error(), testKey) {
+			t.Fatalf("%s: expected an error naming %s, got %v", name, testKey, err)
+		}
+	}
+}
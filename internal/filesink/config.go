@@ -0,0 +1,107 @@
+package filesink
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/pkg/appinfo"
+)
+
+const (
+	defaultDirName       = "requests"
+	defaultFilePrefix    = "requests"
+	defaultMaxSizeMB     = 64
+	defaultRotateDaily   = true
+	defaultFsyncInterval = 1 * time.Second
+	envFileDir           = "WIN_SOUND_FILE_DIR"
+	envFileMaxSizeMB     = "WIN_SOUND_FILE_MAX_SIZE_MB"
+	envFileRotateDaily   = "WIN_SOUND_FILE_ROTATE_DAILY"
+	envFileFsyncInterval = "WIN_SOUND_FILE_FSYNC_INTERVAL_MS"
+)
+
+// Config defines where request lines are written and how files rotate.
+type Config struct {
+	Dir string
+	// FilePrefix starts every file name; defaults to "requests".
+	FilePrefix string
+	// MaxSizeBytes starts a new file once the current one reaches it; 0 disables size rotation.
+	MaxSizeBytes int64
+	// RotateDaily starts a new file when the UTC date changes.
+	RotateDaily bool
+	// FsyncInterval is how often written lines are flushed to disk; 0 syncs after every line.
+	FsyncInterval time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		FilePrefix:    defaultFilePrefix,
+		MaxSizeBytes:  defaultMaxSizeMB << 20,
+		RotateDaily:   defaultRotateDaily,
+		FsyncInterval: defaultFsyncInterval,
+	}
+}
+
+func (c Config) withDefaults() Config {
+	if strings.TrimSpace(c.FilePrefix) == "" {
+		c.FilePrefix = defaultFilePrefix
+	}
+	if c.MaxSizeBytes < 0 {
+		c.MaxSizeBytes = 0
+	}
+	if c.FsyncInterval < 0 {
+		c.FsyncInterval = 0
+	}
+	return c
+}
+
+// LoadConfigFromEnv loads file sink configuration from environment variables.
+// The directory defaults to %ProgramData%\WinSoundScanner\requests.
+func LoadConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+
+	if v := strings.TrimSpace(os.Getenv(envFileDir)); v != "" {
+		cfg.Dir = v
+	} else {
+		dataDir, err := appinfo.DataDir()
+		if err != nil {
+			return Config{}, fmt.Errorf("resolve request file directory (set %s): %w", envFileDir, err)
+		}
+		cfg.Dir = filepath.Join(dataDir, defaultDirName)
+	}
+
+	if v := strings.TrimSpace(os.Getenv(envFileMaxSizeMB)); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s %q: %w", envFileMaxSizeMB, v, err)
+		}
+		if n < 0 {
+			return Config{}, fmt.Errorf("%s can not be negative %q", envFileMaxSizeMB, v)
+		}
+		cfg.MaxSizeBytes = int64(n) << 20
+	}
+
+	if v := strings.TrimSpace(os.Getenv(envFileRotateDaily)); v != "" {
+		daily, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s %q: %w", envFileRotateDaily, v, err)
+		}
+		cfg.RotateDaily = daily
+	}
+
+	if v := strings.TrimSpace(os.Getenv(envFileFsyncInterval)); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s %q: %w", envFileFsyncInterval, v, err)
+		}
+		if n < 0 {
+			return Config{}, fmt.Errorf("%s can not be negative %q", envFileFsyncInterval, v)
+		}
+		cfg.FsyncInterval = time.Duration(n) * time.Millisecond
+	}
+
+	return cfg.withDefaults(), nil
+}
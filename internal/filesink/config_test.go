@@ -0,0 +1,55 @@
+package filesink
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadConfigFromEnv_Overrides(t *testing.T) {
+	t.Setenv(envFileDir, `C:\rig\requests`)
+	t.Setenv(envFileMaxSizeMB, "8")
+	t.Setenv(envFileRotateDaily, "false")
+	t.Setenv(envFileFsyncInterval, "0")
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed: %v", err)
+	}
+
+	if cfg.Dir != `C:\rig\requests` || cfg.MaxSizeBytes != 8<<20 || cfg.RotateDaily || cfg.FsyncInterval != 0 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoadConfigFromEnv_Defaults(t *testing.T) {
+	t.Setenv(envFileDir, t.TempDir())
+	t.Setenv(envFileMaxSizeMB, "")
+	t.Setenv(envFileRotateDaily, "")
+	t.Setenv(envFileFsyncInterval, "")
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed: %v", err)
+	}
+
+	if cfg.MaxSizeBytes != defaultMaxSizeMB<<20 || !cfg.RotateDaily || cfg.FsyncInterval != time.Second {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoadConfigFromEnv_InvalidValues(t *testing.T) {
+	cases := map[string]string{
+		envFileMaxSizeMB:     "-1",
+		envFileRotateDaily:   "sometimes",
+		envFileFsyncInterval: "abc",
+	}
+	for key, value := range cases {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(envFileDir, t.TempDir())
+			t.Setenv(key, value)
+			if _, err := LoadConfigFromEnv(); err == nil {
+				t.Fatalf("expected error for %s=%q", key, value)
+			}
+		})
+	}
+}
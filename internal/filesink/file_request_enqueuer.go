@@ -0,0 +1,79 @@
+package filesink
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/cloudevents"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+// line is one JSON Lines record; Body is the request payload as published
+// by the broker transports, so files can be bulk-imported later.
+type line struct {
+	Timestamp string          `json:"timestamp"`
+	Event     string          `json:"event"`
+	Method    string          `json:"method"`
+	URLSuffix string          `json:"urlSuffix"`
+	DeviceKey string          `json:"deviceKey"`
+	Body      json.RawMessage `json:"body"`
+}
+
+type LineWriter interface {
+	WriteLine(line []byte) error
+	Close() error
+}
+
+// Enqueuer appends every request as a JSON line using the shared message-shaping.
+type Enqueuer struct {
+	writer      LineWriter
+	logger      *slog.Logger
+	cloudEvents cloudevents.Mode
+}
+
+func NewEnqueuer(writer LineWriter, logger *slog.Logger, cloudEvents cloudevents.Mode) *Enqueuer {
+	if writer == nil {
+		panic("nil writer")
+	}
+	if logger == nil {
+		panic("nil logger")
+	}
+	return &Enqueuer{writer: writer, logger: logger, cloudEvents: cloudEvents}
+}
+
+func (e *Enqueuer) EnqueueRequest(request enqueuer.Request) error {
+	e.logger.Info("Preparing request in file enqueuer", "event", request.Event, "fields", request.Fields)
+	payload, err := enqueuer.BuildRequestPayload(request)
+	if err != nil {
+		return fmt.Errorf("marshal file payload: %w", err)
+	}
+	// A file has no message headers, so both modes write structured events.
+	if e.cloudEvents != cloudevents.ModeOff {
+		if payload, err = cloudevents.Structured(payload); err != nil {
+			return fmt.Errorf("marshal file payload: %w", err)
+		}
+	}
+
+	data, err := json.Marshal(line{
+		Timestamp: request.Timestamp.UTC().Format(time.RFC3339Nano),
+		Event:     request.Event.String(),
+		Method:    payload.HTTPRequest,
+		URLSuffix: payload.URLSuffix,
+		DeviceKey: payload.DeviceKey,
+		Body:      payload.Body,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal file line: %w", err)
+	}
+
+	if err := e.writer.WriteLine(data); err != nil {
+		return fmt.Errorf("write request line: %w", err)
+	}
+	return nil
+}
+
+func (e *Enqueuer) Close() error {
+	return e.writer.Close()
+}
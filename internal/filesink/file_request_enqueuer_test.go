@@ -0,0 +1,91 @@
+package filesink
+
+import (
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/cloudevents"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+type recordingWriter struct {
+	lines [][]byte
+}
+
+func (w *recordingWriter) WriteLine(line []byte) error {
+	w.lines = append(w.lines, append([]byte(nil), line...))
+	return nil
+}
+
+func (w *recordingWriter) Close() error {
+	return nil
+}
+
+func TestEnqueueRequest_WritesOneLineWithRequestMetadata(t *testing.T) {
+	writer := &recordingWriter{}
+	sut := NewEnqueuer(writer, slog.Default(), cloudevents.ModeOff)
+
+	err := sut.EnqueueRequest(enqueuer.Request{
+		Timestamp: time.Date(2026, 5, 26, 10, 0, 0, 0, time.UTC),
+		Event:     contract.EventTypeRenderVolumeChanged,
+		Fields: map[string]string{
+			contract.FieldPnpID:        "pnp-1",
+			contract.FieldHostName:     "host-1",
+			contract.FieldRenderVolume: "40",
+		},
+	})
+	if err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+	if len(writer.lines) != 1 {
+		t.Fatalf("expected one line, got %d", len(writer.lines))
+	}
+
+	var got struct {
+		Timestamp string         `json:"timestamp"`
+		Event     string         `json:"event"`
+		Method    string         `json:"method"`
+		URLSuffix string         `json:"urlSuffix"`
+		DeviceKey string         `json:"deviceKey"`
+		Body      map[string]any `json:"body"`
+	}
+	if err := json.Unmarshal(writer.lines[0], &got); err != nil {
+		t.Fatalf("line is not valid JSON: %v", err)
+	}
+	if got.Timestamp != "2026-05-26T10:00:00Z" || got.Event != "RenderVolumeChanged" {
+		t.Fatalf("unexpected timestamp/event: %q/%q", got.Timestamp, got.Event)
+	}
+	if got.Method != "PUT" || got.URLSuffix != "/pnp-1/host-1" || got.DeviceKey != "host-1|pnp-1" {
+		t.Fatalf("unexpected request metadata: %+v", got)
+	}
+	if got.Body[contract.FieldPnpID] != "pnp-1" {
+		t.Fatalf("unexpected body: %#v", got.Body)
+	}
+}
+
+func TestEnqueueRequest_CloudEventsBinaryFallsBackToStructured(t *testing.T) {
+	writer := &recordingWriter{}
+	sut := NewEnqueuer(writer, slog.Default(), cloudevents.ModeBinary)
+
+	err := sut.EnqueueRequest(enqueuer.Request{
+		Timestamp: time.Date(2026, 5, 26, 10, 0, 0, 0, time.UTC),
+		Event:     contract.EventTypeCaptureDeviceDiscovered,
+		Fields:    map[string]string{contract.FieldPnpID: "pnp-1", contract.FieldHostName: "host-1"},
+	})
+	if err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+
+	var got struct {
+		Body map[string]any `json:"body"`
+	}
+	if err := json.Unmarshal(writer.lines[0], &got); err != nil {
+		t.Fatalf("line is not valid JSON: %v", err)
+	}
+	if got.Body["specversion"] != "1.0" || got.Body["type"] != "com.collect-sound-devices.capture.device-discovered" {
+		t.Fatalf("expected a structured CloudEvent as body, got %#v", got.Body)
+	}
+}
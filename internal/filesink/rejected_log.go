@@ -0,0 +1,54 @@
+package filesink
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+// RejectedFilePrefix names the rejected-events files, so they are told apart
+// from request files when both share a directory.
+const RejectedFilePrefix = "rejected"
+
+// rejectedLine keeps the raw request fields, since a payload that failed
+// validation may not be buildable at all.
+type rejectedLine struct {
+	Timestamp string            `json:"timestamp"`
+	Event     string            `json:"event"`
+	Fields    map[string]string `json:"fields"`
+	Error     string            `json:"error"`
+}
+
+// RejectedLog appends requests that failed validation as JSON lines.
+type RejectedLog struct {
+	writer LineWriter
+}
+
+func NewRejectedLog(writer LineWriter) *RejectedLog {
+	if writer == nil {
+		panic("nil writer")
+	}
+	return &RejectedLog{writer: writer}
+}
+
+func (l *RejectedLog) WriteRejected(request enqueuer.Request, reason error) error {
+	data, err := json.Marshal(rejectedLine{
+		Timestamp: request.Timestamp.UTC().Format(time.RFC3339Nano),
+		Event:     request.Event.String(),
+		Fields:    request.Fields,
+		Error:     reason.Error(),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal rejected line: %w", err)
+	}
+	if err := l.writer.WriteLine(data); err != nil {
+		return fmt.Errorf("write rejected line: %w", err)
+	}
+	return nil
+}
+
+func (l *RejectedLog) Close() error {
+	return l.writer.Close()
+}
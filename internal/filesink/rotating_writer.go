@@ -0,0 +1,255 @@
+package filesink
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	fileSuffix = ".jsonl"
+	dateLayout = "20060102"
+)
+
+// fileName is <prefix>-<UTC date>-<index>.jsonl, so files sort in write order.
+func fileName(prefix, day string, index int) string {
+	return fmt.Sprintf("%s-%s-%03d%s", prefix, day, index, fileSuffix)
+}
+
+func parseFileName(prefix, name string) (string, int, bool) {
+	prefix += "-"
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, fileSuffix) {
+		return "", 0, false
+	}
+	day, indexText, ok := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(name, prefix), fileSuffix), "-")
+	if !ok || len(day) != len(dateLayout) {
+		return "", 0, false
+	}
+	index, err := strconv.Atoi(indexText)
+	if err != nil || index <= 0 {
+		return "", 0, false
+	}
+	return day, index, true
+}
+
+// RotatingWriter appends lines to the newest file in a directory, starting a
+// new file by size or UTC date, and syncs to disk on an interval.
+type RotatingWriter struct {
+	cfg Config
+	now func() time.Time
+
+	mu     sync.Mutex
+	file   *os.File
+	day    string
+	index  int
+	size   int64
+	dirty  bool
+	closed bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewRotatingWriter(cfg Config) (*RotatingWriter, error) {
+	return newRotatingWriter(cfg, time.Now)
+}
+
+func newRotatingWriter(cfg Config, now func() time.Time) (*RotatingWriter, error) {
+	cfg = cfg.withDefaults()
+	if strings.TrimSpace(cfg.Dir) == "" {
+		return nil, errors.New("request file directory is empty")
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create request file directory: %w", err)
+	}
+
+	w := &RotatingWriter{
+		cfg:  cfg,
+		now:  now,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if err := w.openLatest(); err != nil {
+		return nil, err
+	}
+
+	if cfg.FsyncInterval > 0 {
+		go w.syncLoop()
+	} else {
+		close(w.done)
+	}
+	return w, nil
+}
+
+// Path returns the file lines are currently appended to.
+func (w *RotatingWriter) Path() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return filepath.Join(w.cfg.Dir, fileName(w.cfg.FilePrefix, w.day, w.index))
+}
+
+// WriteLine appends line followed by a newline.
+func (w *RotatingWriter) WriteLine(line []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return errors.New("request file is closed")
+	}
+	// A failed rotation leaves no open file; try again with the next line.
+	if w.file == nil {
+		if err := w.openLatest(); err != nil {
+			return err
+		}
+	}
+	record := make([]byte, 0, len(line)+1)
+	record = append(append(record, line...), '\n')
+	if err := w.rotateIfNeeded(int64(len(record))); err != nil {
+		return err
+	}
+
+	n, err := w.file.Write(record)
+	w.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("write request file: %w", err)
+	}
+	w.dirty = true
+	if w.cfg.FsyncInterval == 0 {
+		return w.syncLocked()
+	}
+	return nil
+}
+
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	if w.cfg.FsyncInterval > 0 {
+		close(w.stop)
+	}
+	<-w.done
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := errors.Join(w.syncLocked(), w.file.Close())
+	w.file = nil
+	return err
+}
+
+// openLatest continues the newest existing file when it still qualifies,
+// so restarts do not leave a trail of small files.
+func (w *RotatingWriter) openLatest() error {
+	today := w.now().UTC().Format(dateLayout)
+	day, index, ok := w.latestFile()
+	switch {
+	case !ok, w.cfg.RotateDaily && day != today:
+		return w.open(today, 1)
+	case w.cfg.MaxSizeBytes > 0 && w.fileSize(day, index) >= w.cfg.MaxSizeBytes:
+		return w.open(day, index+1)
+	default:
+		return w.open(day, index)
+	}
+}
+
+func (w *RotatingWriter) fileSize(day string, index int) int64 {
+	info, err := os.Stat(filepath.Join(w.cfg.Dir, fileName(w.cfg.FilePrefix, day, index)))
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+func (w *RotatingWriter) latestFile() (string, int, bool) {
+	entries, err := os.ReadDir(w.cfg.Dir)
+	if err != nil {
+		return "", 0, false
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if _, _, ok := parseFileName(w.cfg.FilePrefix, e.Name()); ok && !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	if len(names) == 0 {
+		return "", 0, false
+	}
+	sort.Strings(names)
+	return parseFileName(w.cfg.FilePrefix, names[len(names)-1])
+}
+
+func (w *RotatingWriter) rotateIfNeeded(next int64) error {
+	today := w.now().UTC().Format(dateLayout)
+	switch {
+	case w.cfg.RotateDaily && today != w.day:
+		return w.rotate(today, 1)
+	case w.cfg.MaxSizeBytes > 0 && w.size > 0 && w.size+next > w.cfg.MaxSizeBytes:
+		return w.rotate(w.day, w.index+1)
+	default:
+		return nil
+	}
+}
+
+func (w *RotatingWriter) rotate(day string, index int) error {
+	err := errors.Join(w.syncLocked(), w.file.Close())
+	w.file = nil
+	if err != nil {
+		return fmt.Errorf("close request file: %w", err)
+	}
+	return w.open(day, index)
+}
+
+func (w *RotatingWriter) open(day string, index int) error {
+	path := filepath.Join(w.cfg.Dir, fileName(w.cfg.FilePrefix, day, index))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open request file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("stat request file: %w", err)
+	}
+	w.file, w.day, w.index, w.size, w.dirty = file, day, index, info.Size(), false
+	return nil
+}
+
+func (w *RotatingWriter) syncLocked() error {
+	if !w.dirty || w.file == nil {
+		return nil
+	}
+	if err := w.file.Sync(); err != nil {
+		return fmt.Errorf("sync request file: %w", err)
+	}
+	w.dirty = false
+	return nil
+}
+
+func (w *RotatingWriter) syncLoop() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.cfg.FsyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.mu.Lock()
+			_ = w.syncLocked()
+			w.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package filesink
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestRotatingWriter_RotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{now: time.Date(2026, 5, 26, 10, 0, 0, 0, time.UTC)}
+	sut, err := newRotatingWriter(Config{Dir: dir, MaxSizeBytes: 10}, clock.Now)
+	if err != nil {
+		t.Fatalf("newRotatingWriter failed: %v", err)
+	}
+
+	for _, line := range []string{"line-1", "line-2", "line-3"} {
+		if err := sut.WriteLine([]byte(line)); err != nil {
+			t.Fatalf("WriteLine failed: %v", err)
+		}
+	}
+	if err := sut.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	for i, want := range []string{"line-1", "line-2", "line-3"} {
+		got := readLines(t, filepath.Join(dir, fileName(defaultFilePrefix, "20260526", i+1)))
+		if len(got) != 1 || got[0] != want {
+			t.Fatalf("file %d: unexpected lines %#v", i+1, got)
+		}
+	}
+}
+
+func TestRotatingWriter_RotatesByDate(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{now: time.Date(2026, 5, 26, 23, 59, 0, 0, time.UTC)}
+	sut, err := newRotatingWriter(Config{Dir: dir, RotateDaily: true}, clock.Now)
+	if err != nil {
+		t.Fatalf("newRotatingWriter failed: %v", err)
+	}
+	defer func() { _ = sut.Close() }()
+
+	if err := sut.WriteLine([]byte("before-midnight")); err != nil {
+		t.Fatalf("WriteLine failed: %v", err)
+	}
+	clock.now = clock.now.Add(2 * time.Minute)
+	if err := sut.WriteLine([]byte("after-midnight")); err != nil {
+		t.Fatalf("WriteLine failed: %v", err)
+	}
+
+	if got := readLines(t, filepath.Join(dir, fileName(defaultFilePrefix, "20260526", 1))); len(got) != 1 || got[0] != "before-midnight" {
+		t.Fatalf("unexpected first-day lines: %#v", got)
+	}
+	if got := readLines(t, sut.Path()); filepath.Base(sut.Path()) != fileName(defaultFilePrefix, "20260527", 1) || got[0] != "after-midnight" {
+		t.Fatalf("unexpected second-day file %s: %#v", sut.Path(), got)
+	}
+}
+
+func TestRotatingWriter_ContinuesLatestFileAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{now: time.Date(2026, 5, 26, 10, 0, 0, 0, time.UTC)}
+	cfg := Config{Dir: dir, MaxSizeBytes: 1 << 20, RotateDaily: true, FsyncInterval: time.Millisecond}
+
+	for _, line := range []string{"first-run", "second-run"} {
+		sut, err := newRotatingWriter(cfg, clock.Now)
+		if err != nil {
+			t.Fatalf("newRotatingWriter failed: %v", err)
+		}
+		if err := sut.WriteLine([]byte(line)); err != nil {
+			t.Fatalf("WriteLine failed: %v", err)
+		}
+		if err := sut.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+	}
+
+	got := readLines(t, filepath.Join(dir, fileName(defaultFilePrefix, "20260526", 1)))
+	if len(got) != 2 || got[0] != "first-run" || got[1] != "second-run" {
+		t.Fatalf("unexpected lines: %#v", got)
+	}
+}
+
+func TestRotatingWriter_PrefixKeepsFilesApartInSharedDirectory(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{now: time.Date(2026, 5, 26, 10, 0, 0, 0, time.UTC)}
+
+	for _, prefix := range []string{defaultFilePrefix, RejectedFilePrefix} {
+		sut, err := newRotatingWriter(Config{Dir: dir, FilePrefix: prefix}, clock.Now)
+		if err != nil {
+			t.Fatalf("newRotatingWriter failed: %v", err)
+		}
+		if err := sut.WriteLine([]byte(prefix)); err != nil {
+			t.Fatalf("WriteLine failed: %v", err)
+		}
+		if err := sut.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+	}
+
+	for _, prefix := range []string{defaultFilePrefix, RejectedFilePrefix} {
+		got := readLines(t, filepath.Join(dir, fileName(prefix, "20260526", 1)))
+		if len(got) != 1 || got[0] != prefix {
+			t.Fatalf("%s file: unexpected lines %#v", prefix, got)
+		}
+	}
+}
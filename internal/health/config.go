@@ -0,0 +1,35 @@
+package health
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+const envHealthListen = "WIN_SOUND_HEALTH_LISTEN"
+
+// Config selects the address of the health listener.
+type Config struct {
+	// ListenAddress is host:port, e.g. ":9465"; empty leaves the endpoints
+	// to the metrics listener.
+	ListenAddress string
+}
+
+// Enabled reports whether the health listener is started.
+func (c Config) Enabled() bool {
+	return c.ListenAddress != ""
+}
+
+// LoadConfigFromEnv loads the listener address from WIN_SOUND_HEALTH_LISTEN.
+// The listener is off by default.
+func LoadConfigFromEnv() (Config, error) {
+	cfg := Config{ListenAddress: strings.TrimSpace(os.Getenv(envHealthListen))}
+	if !cfg.Enabled() {
+		return cfg, nil
+	}
+	if _, _, err := net.SplitHostPort(cfg.ListenAddress); err != nil {
+		return Config{}, fmt.Errorf("invalid %s %q: %w", envHealthListen, cfg.ListenAddress, err)
+	}
+	return cfg, nil
+}
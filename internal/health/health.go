@@ -0,0 +1,161 @@
+// Package health reports whether the scanner is alive and ready to deliver
+// device events.
+//
+// Like the metrics, the checks are process-wide: the device source and each
+// transport register a check while they run and remove it when they are
+// closed, so a reload that replaces the transports also replaces their checks.
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/pkg/appinfo"
+)
+
+const (
+	// LivePath answers as long as the process serves requests.
+	LivePath = "/healthz"
+	// ReadyPath answers 200 only when every registered component is up.
+	ReadyPath = "/readyz"
+)
+
+// Status is the state of the scanner or one of its components.
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// Component is the result of one check.
+type Component struct {
+	Status Status `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Up returns a healthy component with an optional detail.
+func Up(detail string) Component {
+	return Component{Status: StatusUp, Detail: detail}
+}
+
+// Down returns an unhealthy component; detail says why.
+func Down(detail string) Component {
+	return Component{Status: StatusDown, Detail: detail}
+}
+
+// Check reports the current state of a component. It is called on every
+// readiness request, so it must not block.
+type Check func() Component
+
+// Report is the JSON body of both endpoints.
+type Report struct {
+	Status     Status               `json:"status"`
+	Version    string               `json:"version"`
+	StartedAt  time.Time            `json:"startedAt"`
+	Components map[string]Component `json:"components,omitempty"`
+}
+
+type registration struct {
+	name  string
+	check Check
+}
+
+var (
+	startedAt = time.Now().UTC()
+
+	mu     sync.Mutex
+	checks []*registration
+)
+
+// Register adds a readiness check under name and returns the function that
+// removes it again. A later registration under the same name hides the
+// earlier one until it is removed, so the transports of a reload can
+// register before the ones they replace are closed.
+func Register(name string, check Check) (unregister func()) {
+	if check == nil {
+		panic("nil check")
+	}
+	r := &registration{name: name, check: check}
+	mu.Lock()
+	checks = append(checks, r)
+	mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			mu.Lock()
+			defer mu.Unlock()
+			for i, c := range checks {
+				if c == r {
+					checks = append(checks[:i], checks[i+1:]...)
+					return
+				}
+			}
+		})
+	}
+}
+
+// Liveness reports the process as up; the components are left to Readiness,
+// so a broker outage never gets the scanner restarted.
+func Liveness() Report {
+	return Report{Status: StatusUp, Version: appinfo.Version, StartedAt: startedAt}
+}
+
+// Readiness runs every registered check. The scanner is ready when there is
+// at least one component and all of them are up.
+func Readiness() Report {
+	mu.Lock()
+	current := make([]*registration, len(checks))
+	copy(current, checks)
+	mu.Unlock()
+
+	report := Report{Status: StatusDown, Version: appinfo.Version, StartedAt: startedAt, Components: make(map[string]Component)}
+	for _, r := range current {
+		report.Components[r.name] = r.check()
+	}
+	if len(report.Components) > 0 {
+		report.Status = StatusUp
+		for _, c := range report.Components {
+			if c.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}
+	}
+	return report
+}
+
+// LiveHandler serves Liveness.
+func LiveHandler() http.Handler {
+	return reportHandler(Liveness)
+}
+
+// ReadyHandler serves Readiness with 503 Service Unavailable when the
+// scanner is not ready.
+func ReadyHandler() http.Handler {
+	return reportHandler(Readiness)
+}
+
+func reportHandler(report func() Report) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		rep := report()
+		status := http.StatusOK
+		if rep.Status != StatusUp {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		if r.Method == http.MethodHead {
+			return
+		}
+		_ = json.NewEncoder(w).Encode(rep)
+	})
+}
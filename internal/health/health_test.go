@@ -0,0 +1,111 @@
+package health
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func get(t *testing.T, handler http.Handler, path string) (int, Report) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	if ct := recorder.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	var report Report
+	if err := json.NewDecoder(recorder.Body).Decode(&report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	return recorder.Code, report
+}
+
+func TestReadyHandler_ReportsComponents(t *testing.T) {
+	code, report := get(t, ReadyHandler(), ReadyPath)
+	if code != http.StatusServiceUnavailable || report.Status != StatusDown {
+		t.Fatalf("expected not ready without components, got %d %+v", code, report)
+	}
+
+	source := Down("not initialized")
+	unregisterSource := Register("deviceSource", func() Component { return source })
+	defer unregisterSource()
+	unregisterBroker := Register("rabbitmq", func() Component { return Up("connected") })
+	defer unregisterBroker()
+
+	code, report = get(t, ReadyHandler(), ReadyPath)
+	if code != http.StatusServiceUnavailable || report.Components["deviceSource"] != source {
+		t.Fatalf("expected the down device source to fail readiness, got %d %+v", code, report)
+	}
+
+	source = Up("initialized")
+	code, report = get(t, ReadyHandler(), ReadyPath)
+	if code != http.StatusOK || report.Status != StatusUp || len(report.Components) != 2 {
+		t.Fatalf("expected ready, got %d %+v", code, report)
+	}
+
+	code, report = get(t, LiveHandler(), LivePath)
+	if code != http.StatusOK || report.Status != StatusUp || report.Components != nil {
+		t.Fatalf("expected live without components, got %d %+v", code, report)
+	}
+}
+
+func TestRegister_LaterRegistrationHidesEarlier(t *testing.T) {
+	unregisterOld := Register("kafka", func() Component { return Down("old") })
+	unregisterNew := Register("kafka", func() Component { return Up("new") })
+	defer unregisterNew()
+
+	if got := Readiness().Components["kafka"]; got.Detail != "new" {
+		t.Fatalf("expected the newer check, got %+v", got)
+	}
+	// Closing the replaced transport must not remove its successor.
+	unregisterOld()
+	unregisterOld()
+	if got := Readiness().Components["kafka"]; got.Detail != "new" {
+		t.Fatalf("expected the newer check to remain, got %+v", got)
+	}
+}
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	t.Setenv(envHealthListen, "")
+	cfg, err := LoadConfigFromEnv()
+	if err != nil || cfg.Enabled() {
+		t.Fatalf("expected the listener to be off by default, got %+v (err %v)", cfg, err)
+	}
+
+	t.Setenv(envHealthListen, ":9465")
+	if cfg, err := LoadConfigFromEnv(); err != nil || cfg.ListenAddress != ":9465" {
+		t.Fatalf("unexpected config %+v (err %v)", cfg, err)
+	}
+
+	t.Setenv(envHealthListen, "9465")
+	if _, err := LoadConfigFromEnv(); err == nil {
+		t.Fatal("expected error for an address without a port separator")
+	}
+}
+
+func TestServe_AnswersLiveness(t *testing.T) {
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	address := free.Addr().String()
+	_ = free.Close()
+
+	stop, err := Serve(Config{ListenAddress: address}, slog.Default())
+	if err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+	defer stop()
+
+	resp, err := http.Get("http://" + address + LivePath)
+	if err != nil {
+		t.Fatalf("GET %s: %v", LivePath, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+}
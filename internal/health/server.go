@@ -0,0 +1,59 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+// Routes maps LivePath and ReadyPath to their handlers, for a listener that
+// serves them next to other paths.
+func Routes() map[string]http.Handler {
+	return map[string]http.Handler{
+		LivePath:  LiveHandler(),
+		ReadyPath: ReadyHandler(),
+	}
+}
+
+// Serve starts a listener for the health endpoints alone and returns a
+// function that stops it. The address is bound before Serve returns, so a
+// port in use is reported at startup.
+func Serve(cfg Config, logger *slog.Logger) (func(), error) {
+	if logger == nil {
+		panic("nil logger")
+	}
+
+	listener, err := net.Listen("tcp", cfg.ListenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("health listener: %w", err)
+	}
+	mux := http.NewServeMux()
+	for path, handler := range Routes() {
+		mux.Handle(path, handler)
+	}
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Health listener failed", "err", err)
+		}
+	}()
+	logger.Info("Health listener started", "address", listener.Addr().String(), "paths", []string{LivePath, ReadyPath})
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			logger.Warn("Health listener shutdown failed", "err", err)
+		}
+		<-done
+	}, nil
+}
@@ -6,6 +6,11 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/encoding"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/envconfig"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/schemaregistry"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/tlsconfig"
 )
 
 const (
@@ -13,17 +18,73 @@ const (
 	defaultTopic         = "audio-device-events"
 	defaultClientID      = "win-sound-scanner"
 	defaultWriteTimeout  = 10 * time.Second
+	defaultHealthWindow  = 5 * time.Minute
 	envKafkaBrokers      = "WIN_SOUND_KAFKA_BROKERS"
 	envKafkaTopic        = "WIN_SOUND_KAFKA_TOPIC"
 	envKafkaClientID     = "WIN_SOUND_KAFKA_CLIENT_ID"
 	envKafkaWriteTimeout = "WIN_SOUND_KAFKA_WRITE_TIMEOUT_MS"
+	envKafkaHealthWindow = "WIN_SOUND_KAFKA_HEALTH_WINDOW_MS"
+
+	envKafkaTLSEnabled            = "WIN_SOUND_KAFKA_TLS_ENABLED"
+	envKafkaTLSCAFile             = "WIN_SOUND_KAFKA_TLS_CA_FILE"
+	envKafkaTLSCertFile           = "WIN_SOUND_KAFKA_TLS_CERT_FILE"
+	envKafkaTLSKeyFile            = "WIN_SOUND_KAFKA_TLS_KEY_FILE"
+	envKafkaTLSInsecureSkipVerify = "WIN_SOUND_KAFKA_TLS_INSECURE_SKIP_VERIFY"
+	envKafkaSASLMechanism         = "WIN_SOUND_KAFKA_SASL_MECHANISM"
+	envKafkaSASLUsername          = "WIN_SOUND_KAFKA_SASL_USERNAME"
+	envKafkaSASLPassword          = "WIN_SOUND_KAFKA_SASL_PASSWORD"
+
+	envKafkaEncoding                   = "WIN_SOUND_KAFKA_ENCODING"
+	envKafkaSchemaRegistryURL          = "WIN_SOUND_KAFKA_SCHEMA_REGISTRY_URL"
+	envKafkaSchemaRegistryUsername     = "WIN_SOUND_KAFKA_SCHEMA_REGISTRY_USERNAME"
+	envKafkaSchemaRegistryPassword     = "WIN_SOUND_KAFKA_SCHEMA_REGISTRY_PASSWORD"
+	envKafkaSchemaRegistryAutoRegister = "WIN_SOUND_KAFKA_SCHEMA_REGISTRY_AUTO_REGISTER"
+)
+
+// SASLMechanism names a Kafka SASL mechanism; empty disables SASL.
+type SASLMechanism string
+
+const (
+	SASLNone        SASLMechanism = ""
+	SASLPlain       SASLMechanism = "PLAIN"
+	SASLScramSHA256 SASLMechanism = "SCRAM-SHA-256"
+	SASLScramSHA512 SASLMechanism = "SCRAM-SHA-512"
 )
 
+// ParseSASLMechanism accepts the mechanism names case-insensitively.
+func ParseSASLMechanism(raw string) (SASLMechanism, error) {
+	switch m := SASLMechanism(strings.ToUpper(strings.TrimSpace(raw))); m {
+	case SASLNone, SASLPlain, SASLScramSHA256, SASLScramSHA512:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unsupported SASL mechanism %q (supported: %s, %s, %s)", raw, SASLPlain, SASLScramSHA256, SASLScramSHA512)
+	}
+}
+
 type Config struct {
 	Brokers      []string
 	Topic        string
 	ClientID     string
 	WriteTimeout time.Duration
+	// HealthWindow is how long a successful write keeps the publisher ready
+	// after later writes failed.
+	HealthWindow time.Duration
+
+	// TLSEnabled switches the broker connections to TLS; TLS holds the
+	// optional CA bundle, client certificate and verification settings.
+	TLSEnabled bool
+	TLS        tlsconfig.Config
+
+	SASLMechanism SASLMechanism
+	SASLUsername  string
+	SASLPassword  string
+
+	// Encoding is the format of the record values. Protobuf and Avro need a
+	// schema registry; AutoRegisterSchemas registers missing schemas instead
+	// of failing the publish.
+	Encoding            encoding.Format
+	SchemaRegistry      schemaregistry.Config
+	AutoRegisterSchemas bool
 }
 
 func DefaultConfig() Config {
@@ -32,6 +93,10 @@ func DefaultConfig() Config {
 		Topic:        defaultTopic,
 		ClientID:     defaultClientID,
 		WriteTimeout: defaultWriteTimeout,
+		HealthWindow: defaultHealthWindow,
+
+		Encoding:            encoding.FormatJSON,
+		AutoRegisterSchemas: true,
 	}
 }
 
@@ -49,6 +114,12 @@ func (c Config) withDefaults() Config {
 	if c.WriteTimeout <= 0 {
 		c.WriteTimeout = d.WriteTimeout
 	}
+	if c.HealthWindow <= 0 {
+		c.HealthWindow = d.HealthWindow
+	}
+	if c.Encoding == "" {
+		c.Encoding = d.Encoding
+	}
 	return c
 }
 
@@ -74,8 +145,97 @@ func LoadConfigFromEnv() (Config, error) {
 		}
 		cfg.WriteTimeout = time.Duration(n) * time.Millisecond
 	}
+	if v := strings.TrimSpace(os.Getenv(envKafkaHealthWindow)); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s %q: %w", envKafkaHealthWindow, v, err)
+		}
+		if n < 0 {
+			return Config{}, fmt.Errorf("%s can not be negative %q", envKafkaHealthWindow, v)
+		}
+		cfg.HealthWindow = time.Duration(n) * time.Millisecond
+	}
+
+	if err := loadSecurityFromEnv(&cfg); err != nil {
+		return Config{}, err
+	}
+	if err := loadEncodingFromEnv(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	cfg = cfg.withDefaults()
+	if err := cfg.validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func loadSecurityFromEnv(cfg *Config) error {
+	var err error
+	if cfg.TLSEnabled, err = envconfig.Bool(envKafkaTLSEnabled, cfg.TLSEnabled); err != nil {
+		return err
+	}
+	if cfg.TLS.InsecureSkipVerify, err = envconfig.Bool(envKafkaTLSInsecureSkipVerify, cfg.TLS.InsecureSkipVerify); err != nil {
+		return err
+	}
+	cfg.TLS.CAFile = strings.TrimSpace(os.Getenv(envKafkaTLSCAFile))
+	cfg.TLS.CertFile = strings.TrimSpace(os.Getenv(envKafkaTLSCertFile))
+	cfg.TLS.KeyFile = strings.TrimSpace(os.Getenv(envKafkaTLSKeyFile))
+
+	if cfg.SASLMechanism, err = ParseSASLMechanism(os.Getenv(envKafkaSASLMechanism)); err != nil {
+		return fmt.Errorf("invalid %s: %w", envKafkaSASLMechanism, err)
+	}
+	cfg.SASLUsername = strings.TrimSpace(os.Getenv(envKafkaSASLUsername))
+	cfg.SASLPassword = os.Getenv(envKafkaSASLPassword)
+	return nil
+}
 
-	return cfg.withDefaults(), nil
+func loadEncodingFromEnv(cfg *Config) error {
+	var err error
+	if cfg.Encoding, err = encoding.ParseFormat(os.Getenv(envKafkaEncoding)); err != nil {
+		return fmt.Errorf("invalid %s: %w", envKafkaEncoding, err)
+	}
+	if cfg.AutoRegisterSchemas, err = envconfig.Bool(envKafkaSchemaRegistryAutoRegister, cfg.AutoRegisterSchemas); err != nil {
+		return err
+	}
+	cfg.SchemaRegistry.URL = strings.TrimSpace(os.Getenv(envKafkaSchemaRegistryURL))
+	cfg.SchemaRegistry.Username = strings.TrimSpace(os.Getenv(envKafkaSchemaRegistryUsername))
+	cfg.SchemaRegistry.Password = os.Getenv(envKafkaSchemaRegistryPassword)
+	return nil
+}
+
+// validate rejects TLS and SASL settings that would be silently ignored or
+// fail only on the first write.
+func (c Config) validate() error {
+	if !c.TLSEnabled && !c.TLS.IsZero() {
+		return fmt.Errorf("kafka TLS options are set but %s is not true", envKafkaTLSEnabled)
+	}
+	if err := c.TLS.Validate(); err != nil {
+		return fmt.Errorf("invalid kafka TLS settings: %w", err)
+	}
+
+	if _, err := ParseSASLMechanism(string(c.SASLMechanism)); err != nil {
+		return err
+	}
+	hasCredentials := c.SASLUsername != "" || c.SASLPassword != ""
+	switch {
+	case c.SASLMechanism == SASLNone && hasCredentials:
+		return fmt.Errorf("kafka SASL credentials are set but %s is empty", envKafkaSASLMechanism)
+	case c.SASLMechanism != SASLNone && (c.SASLUsername == "" || c.SASLPassword == ""):
+		return fmt.Errorf("kafka SASL %s requires both %s and %s", c.SASLMechanism, envKafkaSASLUsername, envKafkaSASLPassword)
+	}
+
+	if _, err := encoding.ParseFormat(string(c.Encoding)); err != nil {
+		return err
+	}
+	hasRegistry := c.SchemaRegistry.URL != ""
+	switch {
+	case c.Encoding == encoding.FormatJSON && hasRegistry:
+		return fmt.Errorf("%s is set but %s is %s", envKafkaSchemaRegistryURL, envKafkaEncoding, encoding.FormatJSON)
+	case c.Encoding != encoding.FormatJSON && !hasRegistry:
+		return fmt.Errorf("kafka %s encoding requires %s", c.Encoding, envKafkaSchemaRegistryURL)
+	}
+	return nil
 }
 
 func splitCSV(raw string) []string {
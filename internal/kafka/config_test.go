@@ -3,6 +3,8 @@ package kafka
 import (
 	"testing"
 	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/encoding"
 )
 
 func TestLoadConfigFromEnv_Defaults(t *testing.T) {
@@ -10,6 +12,7 @@ func TestLoadConfigFromEnv_Defaults(t *testing.T) {
 	t.Setenv(envKafkaTopic, "")
 	t.Setenv(envKafkaClientID, "")
 	t.Setenv(envKafkaWriteTimeout, "")
+	t.Setenv(envKafkaHealthWindow, "")
 
 	cfg, err := LoadConfigFromEnv()
 	if err != nil {
@@ -28,6 +31,9 @@ func TestLoadConfigFromEnv_Defaults(t *testing.T) {
 	if cfg.WriteTimeout != defaultWriteTimeout {
 		t.Fatalf("expected timeout %s, got %s", defaultWriteTimeout, cfg.WriteTimeout)
 	}
+	if cfg.HealthWindow != defaultHealthWindow {
+		t.Fatalf("expected health window %s, got %s", defaultHealthWindow, cfg.HealthWindow)
+	}
 }
 
 func TestLoadConfigFromEnv_Overrides(t *testing.T) {
@@ -35,6 +41,7 @@ func TestLoadConfigFromEnv_Overrides(t *testing.T) {
 	t.Setenv(envKafkaTopic, "custom-topic")
 	t.Setenv(envKafkaClientID, "custom-client")
 	t.Setenv(envKafkaWriteTimeout, "2500")
+	t.Setenv(envKafkaHealthWindow, "60000")
 
 	cfg, err := LoadConfigFromEnv()
 	if err != nil {
@@ -53,6 +60,9 @@ func TestLoadConfigFromEnv_Overrides(t *testing.T) {
 	if cfg.WriteTimeout != 2500*time.Millisecond {
 		t.Fatalf("unexpected timeout: %s", cfg.WriteTimeout)
 	}
+	if cfg.HealthWindow != time.Minute {
+		t.Fatalf("unexpected health window: %s", cfg.HealthWindow)
+	}
 }
 
 func TestLoadConfigFromEnv_InvalidTimeout(t *testing.T) {
@@ -62,3 +72,100 @@ func TestLoadConfigFromEnv_InvalidTimeout(t *testing.T) {
 		t.Fatal("expected invalid timeout error")
 	}
 }
+
+func TestLoadConfigFromEnv_TLSAndSASL(t *testing.T) {
+	t.Setenv(envKafkaTLSEnabled, "true")
+	t.Setenv(envKafkaTLSCAFile, " /etc/kafka/ca.pem ")
+	t.Setenv(envKafkaTLSCertFile, "/etc/kafka/client.pem")
+	t.Setenv(envKafkaTLSKeyFile, "/etc/kafka/client.key")
+	t.Setenv(envKafkaSASLMechanism, "scram-sha-512")
+	t.Setenv(envKafkaSASLUsername, "scanner")
+	t.Setenv(envKafkaSASLPassword, "secret")
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed: %v", err)
+	}
+
+	if !cfg.TLSEnabled || cfg.TLS.CAFile != "/etc/kafka/ca.pem" || cfg.TLS.CertFile != "/etc/kafka/client.pem" || cfg.TLS.KeyFile != "/etc/kafka/client.key" {
+		t.Fatalf("unexpected TLS settings: enabled=%v %+v", cfg.TLSEnabled, cfg.TLS)
+	}
+	if cfg.SASLMechanism != SASLScramSHA512 || cfg.SASLUsername != "scanner" || cfg.SASLPassword != "secret" {
+		t.Fatalf("unexpected SASL settings: %q %q", cfg.SASLMechanism, cfg.SASLUsername)
+	}
+}
+
+func TestLoadConfigFromEnv_RejectsBadSecurityCombinations(t *testing.T) {
+	cases := map[string]map[string]string{
+		"TLS file without TLS":          {envKafkaTLSCAFile: "/etc/kafka/ca.pem"},
+		"skip verify without TLS":       {envKafkaTLSInsecureSkipVerify: "true"},
+		"invalid TLS flag":              {envKafkaTLSEnabled: "maybe"},
+		"cert without key":              {envKafkaTLSEnabled: "true", envKafkaTLSCertFile: "/etc/kafka/client.pem"},
+		"unknown mechanism":             {envKafkaSASLMechanism: "GSSAPI"},
+		"mechanism without password":    {envKafkaSASLMechanism: "PLAIN", envKafkaSASLUsername: "scanner"},
+		"credentials without mechanism": {envKafkaSASLUsername: "scanner", envKafkaSASLPassword: "secret"},
+	}
+	for name, env := range cases {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range []string{
+				envKafkaTLSEnabled, envKafkaTLSCAFile, envKafkaTLSCertFile, envKafkaTLSKeyFile,
+				envKafkaTLSInsecureSkipVerify, envKafkaSASLMechanism, envKafkaSASLUsername, envKafkaSASLPassword,
+			} {
+				t.Setenv(key, env[key])
+			}
+
+			if _, err := LoadConfigFromEnv(); err == nil {
+				t.Fatal("expected configuration error")
+			}
+		})
+	}
+}
+
+func TestLoadConfigFromEnv_Encoding(t *testing.T) {
+	t.Setenv(envKafkaEncoding, "Avro")
+	t.Setenv(envKafkaSchemaRegistryURL, " http://registry:8081 ")
+	t.Setenv(envKafkaSchemaRegistryAutoRegister, "false")
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed: %v", err)
+	}
+	if cfg.Encoding != encoding.FormatAvro || cfg.SchemaRegistry.URL != "http://registry:8081" || cfg.AutoRegisterSchemas {
+		t.Fatalf("unexpected encoding settings: %q %+v auto=%v", cfg.Encoding, cfg.SchemaRegistry, cfg.AutoRegisterSchemas)
+	}
+}
+
+func TestLoadConfigFromEnv_RejectsBadEncodingCombinations(t *testing.T) {
+	cases := map[string]map[string]string{
+		"unknown encoding":      {envKafkaEncoding: "xml"},
+		"protobuf without URL":  {envKafkaEncoding: "protobuf"},
+		"registry with JSON":    {envKafkaSchemaRegistryURL: "http://registry:8081"},
+		"invalid auto register": {envKafkaEncoding: "avro", envKafkaSchemaRegistryURL: "http://registry:8081", envKafkaSchemaRegistryAutoRegister: "sometimes"},
+	}
+	for name, env := range cases {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range []string{envKafkaEncoding, envKafkaSchemaRegistryURL, envKafkaSchemaRegistryAutoRegister} {
+				t.Setenv(key, env[key])
+			}
+
+			if _, err := LoadConfigFromEnv(); err == nil {
+				t.Fatal("expected configuration error")
+			}
+		})
+	}
+}
+
+func TestNewTransport_ConfiguresSASL(t *testing.T) {
+	for _, mechanism := range []SASLMechanism{SASLPlain, SASLScramSHA256, SASLScramSHA512} {
+		transport, err := newTransport(Config{SASLMechanism: mechanism, SASLUsername: "scanner", SASLPassword: "secret"})
+		if err != nil {
+			t.Fatalf("newTransport(%s) failed: %v", mechanism, err)
+		}
+		if transport.SASL == nil || transport.SASL.Name() != string(mechanism) {
+			t.Fatalf("unexpected SASL mechanism for %s: %#v", mechanism, transport.SASL)
+		}
+		if transport.TLS != nil {
+			t.Fatalf("expected no TLS for %s", mechanism)
+		}
+	}
+}
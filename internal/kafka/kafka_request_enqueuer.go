@@ -6,11 +6,15 @@ import (
 	"log/slog"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/cloudevents"
 	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/tracing"
 )
 
 type MessagePublisher interface {
-	Publish(ctx context.Context, key []byte, body []byte) error
+	Publish(ctx context.Context, message Message) error
 	Close() error
 }
 
@@ -19,9 +23,11 @@ type Enqueuer struct {
 	publisher      MessagePublisher
 	logger         *slog.Logger
 	publishTimeout time.Duration
+	cloudEvents    cloudevents.Mode
+	serializer     *Serializer
 }
 
-func NewEnqueuerWithContext(baseCtx context.Context, publisher MessagePublisher, logger *slog.Logger, publishTimeout time.Duration) *Enqueuer {
+func NewEnqueuerWithContext(baseCtx context.Context, publisher MessagePublisher, logger *slog.Logger, publishTimeout time.Duration, cloudEvents cloudevents.Mode, serializer *Serializer) *Enqueuer {
 	if baseCtx == nil {
 		panic("nil context")
 	}
@@ -31,6 +37,9 @@ func NewEnqueuerWithContext(baseCtx context.Context, publisher MessagePublisher,
 	if logger == nil {
 		panic("nil logger")
 	}
+	if serializer == nil {
+		panic("nil serializer")
+	}
 	if publishTimeout <= 0 {
 		publishTimeout = defaultWriteTimeout
 	}
@@ -40,21 +49,49 @@ func NewEnqueuerWithContext(baseCtx context.Context, publisher MessagePublisher,
 		publisher:      publisher,
 		logger:         logger,
 		publishTimeout: publishTimeout,
+		cloudEvents:    cloudEvents,
+		serializer:     serializer,
 	}
 }
 
-func (e *Enqueuer) EnqueueRequest(request enqueuer.Request) error {
+func (e *Enqueuer) EnqueueRequest(request enqueuer.Request) (err error) {
+	// The producer span continues the trace of the device callback; its
+	// traceparent goes into the record headers.
+	ctx, span := tracing.Start(tracing.ContextWithTraceParent(e.baseCtx, request.TraceParent), "kafka publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			tracing.AttrEventType.String(request.Event.String()),
+			tracing.AttrMessagingSystem.String("kafka"),
+			tracing.AttrMessagingOperationType.String("send"),
+		))
+	defer func() { tracing.End(span, err) }()
+
 	e.logger.Info("Preparing request in Kafka enqueuer", "event", request.Event, "fields", request.Fields)
+	_, buildSpan := tracing.Start(ctx, "BuildRequestPayload")
 	payload, err := enqueuer.BuildRequestPayload(request)
+	tracing.End(buildSpan, err)
 	if err != nil {
 		return fmt.Errorf("marshal kafka payload: %w", err)
 	}
 
 	e.logger.Info("publishing event", "method", payload.HTTPRequest, "urlSuffix", payload.URLSuffix, "key", payload.DeviceKey, "updated", payload.UpdateDateUtc)
 
-	ctx, cancel := context.WithTimeout(e.baseCtx, e.publishTimeout)
+	// The timeout also bounds the schema registry round trip of the first
+	// message of each event type.
+	ctx, cancel := context.WithTimeout(ctx, e.publishTimeout)
 	defer cancel()
-	if err := e.publisher.Publish(ctx, []byte(payload.DeviceKey), payload.Body); err != nil {
+
+	value, err := e.serializer.Serialize(ctx, payload)
+	if err != nil {
+		return fmt.Errorf("serialize kafka payload: %w", err)
+	}
+	message, err := newMessage(payload, value, e.serializer.Format(), e.cloudEvents)
+	if err != nil {
+		return fmt.Errorf("marshal kafka payload: %w", err)
+	}
+	tracing.Inject(ctx, headerCarrier{headers: &message.Headers})
+
+	if err := e.publisher.Publish(ctx, message); err != nil {
 		return fmt.Errorf("publish event: %w", err)
 	}
 
@@ -5,22 +5,32 @@ import (
 	"encoding/json"
 	"errors"
 	"log/slog"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/cloudevents"
 	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
 	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+	"github.com/collect-sound-devices/win-sound-scanner-go/pkg/appinfo"
 )
 
 type fakePublisher struct {
-	key  []byte
-	body []byte
-	err  error
+	key     []byte
+	body    []byte
+	headers map[string]string
+	time    time.Time
+	err     error
 }
 
-func (p *fakePublisher) Publish(_ context.Context, key []byte, body []byte) error {
-	p.key = append([]byte(nil), key...)
-	p.body = append([]byte(nil), body...)
+func (p *fakePublisher) Publish(_ context.Context, message Message) error {
+	p.key = append([]byte(nil), message.Key...)
+	p.body = append([]byte(nil), message.Body...)
+	p.headers = make(map[string]string, len(message.Headers))
+	for _, h := range message.Headers {
+		p.headers[h.Key] = string(h.Value)
+	}
+	p.time = message.Time
 	return p.err
 }
 
@@ -28,9 +38,18 @@ func (p *fakePublisher) Close() error {
 	return nil
 }
 
+func jsonSerializer(t *testing.T) *Serializer {
+	t.Helper()
+	serializer, err := NewSerializer(DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewSerializer failed: %v", err)
+	}
+	return serializer
+}
+
 func TestEnqueueRequest_PublishesPayloadWithDeviceKey(t *testing.T) {
 	publisher := &fakePublisher{}
-	sut := NewEnqueuerWithContext(context.Background(), publisher, slog.Default(), time.Second)
+	sut := NewEnqueuerWithContext(context.Background(), publisher, slog.Default(), time.Second, cloudevents.ModeOff, jsonSerializer(t))
 
 	err := sut.EnqueueRequest(enqueuer.Request{
 		Timestamp: time.Date(2026, 5, 26, 10, 0, 0, 0, time.UTC),
@@ -57,9 +76,111 @@ func TestEnqueueRequest_PublishesPayloadWithDeviceKey(t *testing.T) {
 	}
 }
 
+func TestEnqueueRequest_SetsHeadersAndEventTime(t *testing.T) {
+	publisher := &fakePublisher{}
+	sut := NewEnqueuerWithContext(context.Background(), publisher, slog.Default(), time.Second, cloudevents.ModeOff, jsonSerializer(t))
+	eventTime := time.Date(2026, 5, 26, 10, 0, 0, 0, time.UTC)
+
+	err := sut.EnqueueRequest(enqueuer.Request{
+		Timestamp: eventTime,
+		Event:     contract.EventTypeCaptureVolumeChanged,
+		Fields: map[string]string{
+			contract.FieldPnpID:         "pnp-1",
+			contract.FieldHostName:      "host-1",
+			contract.FieldCaptureVolume: "40",
+		},
+	})
+	if err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+
+	want := map[string]string{
+		HeaderEventType:      "CaptureVolumeChanged",
+		HeaderMessageType:    "4",
+		HeaderFlowType:       "2",
+		HeaderHTTPMethod:     "PUT",
+		HeaderURLSuffix:      "/pnp-1/host-1",
+		HeaderContentType:    "application/json",
+		HeaderSchemaVersion:  enqueuer.PayloadSchemaVersion,
+		HeaderScannerVersion: appinfo.Version,
+		HeaderHostName:       "host-1",
+	}
+	for key, value := range want {
+		if publisher.headers[key] != value {
+			t.Fatalf("header %s: expected %q, got %q", key, value, publisher.headers[key])
+		}
+	}
+	if !publisher.time.Equal(eventTime) {
+		t.Fatalf("expected message time %s, got %s", eventTime, publisher.time)
+	}
+}
+
+func TestEnqueueRequest_CloudEventsBinaryMode(t *testing.T) {
+	publisher := &fakePublisher{}
+	sut := NewEnqueuerWithContext(context.Background(), publisher, slog.Default(), time.Second, cloudevents.ModeBinary, jsonSerializer(t))
+
+	err := sut.EnqueueRequest(enqueuer.Request{
+		Timestamp: time.Date(2026, 5, 26, 10, 0, 0, 0, time.UTC),
+		Event:     contract.EventTypeRenderVolumeChanged,
+		Fields:    map[string]string{contract.FieldPnpID: "pnp-1", contract.FieldHostName: "host-1", contract.FieldRenderVolume: "42"},
+	})
+	if err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+
+	want := map[string]string{
+		"content-type":   "application/json",
+		"ce_specversion": "1.0",
+		"ce_source":      "scanner://host-1",
+		"ce_type":        "com.collect-sound-devices.render.volume-changed",
+		"ce_subject":     "pnp-1",
+		"ce_time":        "2026-05-26T10:00:00Z",
+	}
+	for key, value := range want {
+		if publisher.headers[key] != value {
+			t.Fatalf("header %s: expected %q, got %q", key, value, publisher.headers[key])
+		}
+	}
+	if publisher.headers["ce_id"] == "" {
+		t.Fatal("expected ce_id header")
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(publisher.body, &payload); err != nil || payload[contract.FieldHTTPRequest] != "PUT" {
+		t.Fatalf("expected the plain payload as body, got %s", publisher.body)
+	}
+}
+
+func TestEnqueueRequest_CloudEventsStructuredMode(t *testing.T) {
+	publisher := &fakePublisher{}
+	sut := NewEnqueuerWithContext(context.Background(), publisher, slog.Default(), time.Second, cloudevents.ModeStructured, jsonSerializer(t))
+
+	err := sut.EnqueueRequest(enqueuer.Request{
+		Timestamp: time.Date(2026, 5, 26, 10, 0, 0, 0, time.UTC),
+		Event:     contract.EventTypeRenderDeviceDiscovered,
+		Fields:    map[string]string{contract.FieldPnpID: "pnp-1", contract.FieldHostName: "host-1"},
+	})
+	if err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+
+	if publisher.headers["content-type"] != cloudevents.ContentTypeStructured || publisher.headers[HeaderContentType] != cloudevents.ContentTypeStructured {
+		t.Fatalf("unexpected content type headers: %#v", publisher.headers)
+	}
+	var event map[string]any
+	if err := json.Unmarshal(publisher.body, &event); err != nil {
+		t.Fatalf("event is not valid JSON: %v", err)
+	}
+	if event["specversion"] != "1.0" || event["type"] != "com.collect-sound-devices.render.device-discovered" {
+		t.Fatalf("unexpected event: %s", publisher.body)
+	}
+	if data, ok := event["data"].(map[string]any); !ok || data[contract.FieldHTTPRequest] != "POST" {
+		t.Fatalf("expected the payload as data, got %s", publisher.body)
+	}
+}
+
 func TestEnqueueRequest_PropagatesPublisherError(t *testing.T) {
 	publisher := &fakePublisher{err: errors.New("boom")}
-	sut := NewEnqueuerWithContext(context.Background(), publisher, slog.Default(), time.Second)
+	sut := NewEnqueuerWithContext(context.Background(), publisher, slog.Default(), time.Second, cloudevents.ModeOff, jsonSerializer(t))
 
 	err := sut.EnqueueRequest(enqueuer.Request{
 		Event:  contract.EventTypeRenderDeviceDiscovered,
@@ -69,3 +190,23 @@ func TestEnqueueRequest_PropagatesPublisherError(t *testing.T) {
 		t.Fatal("expected publisher error")
 	}
 }
+
+func TestEnqueueRequest_PropagatesTraceContext(t *testing.T) {
+	publisher := &fakePublisher{}
+	sut := NewEnqueuerWithContext(context.Background(), publisher, slog.Default(), time.Second, cloudevents.ModeOff, jsonSerializer(t))
+
+	err := sut.EnqueueRequest(enqueuer.Request{
+		Timestamp:   time.Date(2026, 5, 26, 10, 0, 0, 0, time.UTC),
+		Event:       contract.EventTypeRenderDeviceDiscovered,
+		Fields:      map[string]string{contract.FieldPnpID: "pnp-1", contract.FieldHostName: "host-1"},
+		TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	})
+	if err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+
+	// The header names the producer span, so only the trace ID is fixed.
+	if got := publisher.headers["traceparent"]; !strings.HasPrefix(got, "00-4bf92f3577b34da6a3ce929d0e0e4736-") {
+		t.Fatalf("expected the trace to continue in the traceparent header, got %q", got)
+	}
+}
@@ -0,0 +1,130 @@
+package kafka
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/cloudevents"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/encoding"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+	"github.com/collect-sound-devices/win-sound-scanner-go/pkg/appinfo"
+)
+
+// Header names set on every message, so stream processors can filter
+// without deserialising the body.
+const (
+	HeaderEventType      = "eventType"
+	HeaderMessageType    = "messageType"
+	HeaderFlowType       = "flowType"
+	HeaderHTTPMethod     = "httpMethod"
+	HeaderURLSuffix      = "urlSuffix"
+	HeaderContentType    = "contentType"
+	HeaderSchemaVersion  = "schemaVersion"
+	HeaderScannerVersion = "scannerVersion"
+	HeaderHostName       = "hostName"
+
+	// CloudEvents Kafka protocol binding: the content type travels in
+	// content-type and, in binary mode, each attribute in a ce_ header.
+	headerCloudEventsContentType = "content-type"
+	headerCloudEventsPrefix      = "ce_"
+)
+
+// Message is one Kafka record: the partition key, the serialized body and its metadata.
+type Message struct {
+	Key     []byte
+	Body    []byte
+	Headers []kafkago.Header
+	// Time is the event time; zero lets the writer use the publish time.
+	Time time.Time
+}
+
+// newMessage builds the record of a payload whose value is already
+// serialized in format. Structured CloudEvents embed the JSON payload, so
+// they need the JSON format.
+func newMessage(payload enqueuer.RequestPayload, value []byte, format encoding.Format, mode cloudevents.Mode) (Message, error) {
+	body := value
+	contentType := format.ContentType()
+	if mode == cloudevents.ModeStructured {
+		if format != encoding.FormatJSON {
+			return Message{}, errors.New("structured CloudEvents require the json encoding")
+		}
+		var err error
+		if body, err = cloudevents.FromPayload(payload).MarshalStructured(); err != nil {
+			return Message{}, err
+		}
+		contentType = cloudevents.ContentTypeStructured
+	}
+
+	headers := []kafkago.Header{
+		header(HeaderEventType, payload.Event.String()),
+		header(HeaderMessageType, strconv.Itoa(int(payload.MessageType))),
+	}
+	// Flow type is only meaningful for device-specific events.
+	if payload.FlowType != 0 {
+		headers = append(headers, header(HeaderFlowType, strconv.Itoa(int(payload.FlowType))))
+	}
+	headers = append(headers,
+		header(HeaderHTTPMethod, payload.HTTPRequest),
+		header(HeaderURLSuffix, payload.URLSuffix),
+		header(HeaderContentType, contentType),
+		header(HeaderSchemaVersion, enqueuer.PayloadSchemaVersion),
+		header(HeaderScannerVersion, appinfo.Version),
+		header(HeaderHostName, payload.HostName),
+	)
+
+	switch mode {
+	case cloudevents.ModeStructured:
+		headers = append(headers, header(headerCloudEventsContentType, contentType))
+	case cloudevents.ModeBinary:
+		headers = append(headers, header(headerCloudEventsContentType, contentType))
+		for _, attribute := range cloudevents.FromPayload(payload).Attributes() {
+			headers = append(headers, header(headerCloudEventsPrefix+attribute.Name, attribute.Value))
+		}
+	}
+
+	return Message{
+		Key:     []byte(payload.DeviceKey),
+		Body:    body,
+		Headers: headers,
+		Time:    payload.EventTime,
+	}, nil
+}
+
+func header(key, value string) kafkago.Header {
+	return kafkago.Header{Key: key, Value: []byte(value)}
+}
+
+// headerCarrier lets the trace context propagator write record headers.
+type headerCarrier struct {
+	headers *[]kafkago.Header
+}
+
+func (c headerCarrier) Get(key string) string {
+	for _, h := range *c.headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (c headerCarrier) Set(key, value string) {
+	for i, h := range *c.headers {
+		if h.Key == key {
+			(*c.headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c.headers = append(*c.headers, header(key, value))
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c.headers))
+	for _, h := range *c.headers {
+		keys = append(keys, h.Key)
+	}
+	return keys
+}
@@ -4,14 +4,27 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync"
+	"time"
 
 	kafkago "github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/health"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/tracing"
 )
 
 type RequestPublisher struct {
 	cfg    Config
 	logger *slog.Logger
 	writer *kafkago.Writer
+
+	mu          sync.Mutex
+	lastSuccess time.Time
+	lastErr     error
 }
 
 func NewRequestPublisher(cfg Config, logger *slog.Logger) (*RequestPublisher, error) {
@@ -23,6 +36,16 @@ func NewRequestPublisher(cfg Config, logger *slog.Logger) (*RequestPublisher, er
 	if len(cfg.Brokers) == 0 {
 		return nil, fmt.Errorf("kafka brokers are empty")
 	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SASLMechanism == SASLPlain && !cfg.TLSEnabled {
+		logger.Warn("Kafka SASL PLAIN without TLS sends the password in clear text")
+	}
 
 	writer := &kafkago.Writer{
 		Addr:         kafkago.TCP(cfg.Brokers...),
@@ -32,12 +55,11 @@ func NewRequestPublisher(cfg Config, logger *slog.Logger) (*RequestPublisher, er
 		RequiredAcks: kafkago.RequireAll,
 		WriteTimeout: cfg.WriteTimeout,
 		Async:        false,
-		Transport: &kafkago.Transport{
-			ClientID: cfg.ClientID,
-		},
+		Transport:    transport,
 	}
 
-	logger.Info("Kafka producer initialized", "brokers", cfg.Brokers, "topic", cfg.Topic, "clientId", cfg.ClientID)
+	logger.Info("Kafka producer initialized", "brokers", cfg.Brokers, "topic", cfg.Topic, "clientId", cfg.ClientID,
+		"tls", cfg.TLSEnabled, "sasl", string(cfg.SASLMechanism))
 	return &RequestPublisher{
 		cfg:    cfg,
 		logger: logger,
@@ -45,19 +67,104 @@ func NewRequestPublisher(cfg Config, logger *slog.Logger) (*RequestPublisher, er
 	}, nil
 }
 
-func (p *RequestPublisher) Publish(ctx context.Context, key []byte, body []byte) error {
+func newTransport(cfg Config) (*kafkago.Transport, error) {
+	transport := &kafkago.Transport{ClientID: cfg.ClientID}
+	if cfg.TLSEnabled {
+		tlsCfg, err := cfg.TLS.Build()
+		if err != nil {
+			return nil, fmt.Errorf("kafka TLS: %w", err)
+		}
+		transport.TLS = tlsCfg
+	}
+	mechanism, err := saslMechanism(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transport.SASL = mechanism
+	return transport, nil
+}
+
+func saslMechanism(cfg Config) (sasl.Mechanism, error) {
+	var algo scram.Algorithm
+	switch cfg.SASLMechanism {
+	case SASLNone:
+		return nil, nil
+	case SASLPlain:
+		return plain.Mechanism{Username: cfg.SASLUsername, Password: cfg.SASLPassword}, nil
+	case SASLScramSHA256:
+		algo = scram.SHA256
+	case SASLScramSHA512:
+		algo = scram.SHA512
+	default:
+		return nil, fmt.Errorf("unsupported SASL mechanism %q", cfg.SASLMechanism)
+	}
+	mechanism, err := scram.Mechanism(algo, cfg.SASLUsername, cfg.SASLPassword)
+	if err != nil {
+		return nil, fmt.Errorf("kafka SASL %s: %w", cfg.SASLMechanism, err)
+	}
+	return mechanism, nil
+}
+
+func (p *RequestPublisher) Publish(ctx context.Context, message Message) error {
 	if ctx == nil {
 		panic("nil context")
 	}
 
-	if err := p.writer.WriteMessages(ctx, kafkago.Message{Key: key, Value: body}); err != nil {
-		return fmt.Errorf("kafka write failed: %w", err)
+	record := kafkago.Message{
+		Key:     message.Key,
+		Value:   message.Body,
+		Headers: message.Headers,
+		Time:    message.Time,
+	}
+	trace.SpanFromContext(ctx).SetAttributes(tracing.AttrMessagingDestination.String(p.cfg.Topic))
+	// With RequireAll the write returns once all in-sync replicas acknowledged.
+	_, writeSpan := tracing.Start(ctx, "kafka write")
+	err := p.writer.WriteMessages(ctx, record)
+	tracing.End(writeSpan, err)
+	if err != nil {
+		err = fmt.Errorf("kafka write failed: %w", err)
+		p.recordWrite(time.Now(), err)
+		return err
 	}
+	p.recordWrite(time.Now(), nil)
 
-	p.logger.Info("Kafka message written", "topic", p.cfg.Topic, "key", string(key))
+	p.logger.Info("Kafka message written", "topic", p.cfg.Topic, "key", string(message.Key))
 	return nil
 }
 
+func (p *RequestPublisher) recordWrite(at time.Time, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lastErr = err
+	if err == nil {
+		p.lastSuccess = at
+	}
+}
+
+// Health reports the publisher as up unless the last write failed and no
+// write succeeded within the health window. Kafka has no connection to
+// watch, and a quiet scanner writes nothing for hours, so only failures
+// count.
+func (p *RequestPublisher) Health() health.Component {
+	return p.healthAt(time.Now())
+}
+
+func (p *RequestPublisher) healthAt(now time.Time) health.Component {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case p.lastErr == nil && p.lastSuccess.IsZero():
+		return health.Up("no writes yet")
+	case p.lastErr == nil:
+		return health.Up("last write succeeded at " + p.lastSuccess.UTC().Format(time.RFC3339))
+	case !p.lastSuccess.IsZero() && now.Sub(p.lastSuccess) <= p.cfg.HealthWindow:
+		return health.Up(fmt.Sprintf("last write failed, last success at %s is within %s: %v",
+			p.lastSuccess.UTC().Format(time.RFC3339), p.cfg.HealthWindow, p.lastErr))
+	default:
+		return health.Down(fmt.Sprintf("no successful write within %s: %v", p.cfg.HealthWindow, p.lastErr))
+	}
+}
+
 func (p *RequestPublisher) Close() error {
 	if p.writer == nil {
 		return nil
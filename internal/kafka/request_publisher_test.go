@@ -0,0 +1,34 @@
+package kafka
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/health"
+)
+
+func TestRequestPublisher_HealthWindow(t *testing.T) {
+	p := &RequestPublisher{cfg: Config{HealthWindow: time.Minute}}
+	start := time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)
+
+	if got := p.healthAt(start); got.Status != health.StatusUp {
+		t.Fatalf("expected up before the first write, got %+v", got)
+	}
+
+	p.recordWrite(start, nil)
+	p.recordWrite(start.Add(10*time.Second), errors.New("broker down"))
+	if got := p.healthAt(start.Add(time.Minute)); got.Status != health.StatusUp {
+		t.Fatalf("expected up within the window, got %+v", got)
+	}
+	got := p.healthAt(start.Add(time.Minute + time.Second))
+	if got.Status != health.StatusDown || !strings.Contains(got.Detail, "broker down") {
+		t.Fatalf("expected down with the last error after the window, got %+v", got)
+	}
+
+	p.recordWrite(start.Add(2*time.Minute), nil)
+	if got := p.healthAt(start.Add(time.Hour)); got.Status != health.StatusUp {
+		t.Fatalf("expected a quiet publisher to stay up, got %+v", got)
+	}
+}
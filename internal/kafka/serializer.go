@@ -0,0 +1,115 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/encoding"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/schemaregistry"
+)
+
+// Serializer produces record values in the configured encoding. JSON values
+// are the plain payload; Protobuf and Avro values carry the schema registry
+// wire format, so registry-aware consumers can decode them.
+type Serializer struct {
+	encoder      encoding.Encoder
+	registry     *schemaregistry.Client
+	topic        string
+	autoRegister bool
+}
+
+func NewSerializer(cfg Config) (*Serializer, error) {
+	cfg = cfg.withDefaults()
+	encoder, err := encoding.New(cfg.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	s := &Serializer{encoder: encoder, topic: cfg.Topic, autoRegister: cfg.AutoRegisterSchemas}
+	if encoder.Format() == encoding.FormatJSON {
+		return s, nil
+	}
+	if s.registry, err = schemaregistry.NewClient(cfg.SchemaRegistry); err != nil {
+		return nil, fmt.Errorf("kafka %s encoding: %w", encoder.Format(), err)
+	}
+	return s, nil
+}
+
+// Format returns the encoding of the record values.
+func (s *Serializer) Format() encoding.Format {
+	return s.encoder.Format()
+}
+
+// Serialize encodes a payload and, for schema-based formats, frames it with
+// the ID of its schema. Subjects follow the TopicRecordNameStrategy,
+// "<topic>-<fully qualified name>", because one topic carries every event type.
+func (s *Serializer) Serialize(ctx context.Context, payload enqueuer.RequestPayload) ([]byte, error) {
+	encoded, err := s.encoder.Encode(payload)
+	if err != nil {
+		return nil, err
+	}
+	if encoded.Schema == nil {
+		return encoded.Body, nil
+	}
+
+	resolve := s.registry.Lookup
+	if s.autoRegister {
+		resolve = s.registry.Register
+	}
+	id, err := resolve(ctx, s.subject(*encoded.Schema), s.schemaType(), encoded.Schema.Text)
+	if errors.Is(err, schemaregistry.ErrNotFound) {
+		// Without auto-registration the schema has to be registered by hand;
+		// retrying would hold every later Kafka event behind this one.
+		// CheckSchemas catches this on startup.
+		return nil, fmt.Errorf("%w: %w", err, enqueuer.ErrRejected)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if s.schemaType() == schemaregistry.SchemaTypeProtobuf {
+		return schemaregistry.FrameProtobuf(id, encoded.Schema.MessageIndexes, encoded.Body), nil
+	}
+	return schemaregistry.Frame(id, encoded.Body), nil
+}
+
+// CheckSchemas looks up the schema of every event type when schemas are not
+// registered automatically, so a missing one fails on startup instead of
+// discarding the events of its type. The error wraps
+// schemaregistry.ErrNotFound when schemas are missing.
+func (s *Serializer) CheckSchemas(ctx context.Context) error {
+	if s.registry == nil || s.autoRegister {
+		return nil
+	}
+	var missing []string
+	for _, schema := range s.encoder.Schemas() {
+		subject := s.subject(schema)
+		_, err := s.registry.Lookup(ctx, subject, s.schemaType(), schema.Text)
+		if errors.Is(err, schemaregistry.ErrNotFound) {
+			missing = append(missing, subject)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: register subjects %s or enable auto-registration",
+			schemaregistry.ErrNotFound, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// subject follows the TopicRecordNameStrategy.
+func (s *Serializer) subject(schema encoding.Schema) string {
+	return s.topic + "-" + schema.Name
+}
+
+func (s *Serializer) schemaType() schemaregistry.SchemaType {
+	if s.encoder.Format() == encoding.FormatProtobuf {
+		return schemaregistry.SchemaTypeProtobuf
+	}
+	return schemaregistry.SchemaTypeAvro
+}
@@ -0,0 +1,237 @@
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/cloudevents"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/encoding"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/outbox"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/schemaregistry"
+)
+
+// fakeRegistry answers schema registrations with one ID per subject.
+type fakeRegistry struct {
+	mu       sync.Mutex
+	subjects map[string]string
+}
+
+func newFakeRegistry(t *testing.T) (*fakeRegistry, *httptest.Server) {
+	t.Helper()
+	registry := &fakeRegistry{subjects: make(map[string]string)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			SchemaType string `json:"schemaType"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		registry.mu.Lock()
+		registry.subjects[r.URL.Path] = request.SchemaType
+		id := len(registry.subjects)
+		registry.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]int{"id": id})
+	}))
+	t.Cleanup(server.Close)
+	return registry, server
+}
+
+func volumeRequest() enqueuer.Request {
+	return enqueuer.Request{
+		Timestamp: time.Now(),
+		Event:     contract.EventTypeRenderVolumeChanged,
+		Fields: map[string]string{
+			contract.FieldPnpID:    "pnp-1",
+			contract.FieldHostName: "host-1",
+			contract.FieldVolume:   "40",
+		},
+	}
+}
+
+func TestEnqueueRequest_ProtobufUsesRegistryWireFormat(t *testing.T) {
+	registry, server := newFakeRegistry(t)
+	cfg := DefaultConfig()
+	cfg.Encoding = encoding.FormatProtobuf
+	cfg.SchemaRegistry.URL = server.URL
+	serializer, err := NewSerializer(cfg)
+	if err != nil {
+		t.Fatalf("NewSerializer failed: %v", err)
+	}
+	publisher := &fakePublisher{}
+	sut := NewEnqueuerWithContext(context.Background(), publisher, slog.Default(), time.Second, cloudevents.ModeBinary, serializer)
+
+	if err := sut.EnqueueRequest(volumeRequest()); err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+
+	subject := "/subjects/" + defaultTopic + "-" + encoding.Namespace + ".RenderVolumeChanged/versions"
+	if registry.subjects[subject] != "PROTOBUF" {
+		t.Fatalf("expected PROTOBUF registration of %s, got %v", subject, registry.subjects)
+	}
+	body := publisher.body
+	if len(body) < 7 || body[0] != 0 || binary.BigEndian.Uint32(body[1:5]) != 1 {
+		t.Fatalf("missing wire-format header: %v", body)
+	}
+	// RenderVolumeChanged is the fifth message of events.proto: count 1, index 4.
+	if body[5] != 2 || body[6] != 8 {
+		t.Fatalf("unexpected message indexes: %v", body[5:7])
+	}
+	if publisher.headers[HeaderContentType] != "application/x-protobuf" || publisher.headers["content-type"] != "application/x-protobuf" {
+		t.Fatalf("unexpected content type headers: %v", publisher.headers)
+	}
+}
+
+func TestEnqueueRequest_AvroLooksUpSchemaWithoutAutoRegister(t *testing.T) {
+	registry, server := newFakeRegistry(t)
+	cfg := DefaultConfig()
+	cfg.Encoding = encoding.FormatAvro
+	cfg.SchemaRegistry.URL = server.URL
+	cfg.AutoRegisterSchemas = false
+	serializer, err := NewSerializer(cfg)
+	if err != nil {
+		t.Fatalf("NewSerializer failed: %v", err)
+	}
+	publisher := &fakePublisher{}
+	sut := NewEnqueuerWithContext(context.Background(), publisher, slog.Default(), time.Second, cloudevents.ModeOff, serializer)
+
+	if err := sut.EnqueueRequest(volumeRequest()); err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+
+	subject := "/subjects/" + defaultTopic + "-" + encoding.Namespace + ".RenderVolumeChanged"
+	if schemaType, ok := registry.subjects[subject]; !ok || schemaType != "" {
+		t.Fatalf("expected AVRO lookup of %s, got %v", subject, registry.subjects)
+	}
+	if body := publisher.body; len(body) < 6 || body[0] != 0 || binary.BigEndian.Uint32(body[1:5]) != 1 {
+		t.Fatalf("missing wire-format header: %v", body)
+	}
+	if publisher.headers[HeaderContentType] != "application/avro" {
+		t.Fatalf("unexpected content type: %v", publisher.headers)
+	}
+}
+
+func TestEnqueueRequest_StructuredCloudEventsRequireJSON(t *testing.T) {
+	_, server := newFakeRegistry(t)
+	cfg := DefaultConfig()
+	cfg.Encoding = encoding.FormatAvro
+	cfg.SchemaRegistry.URL = server.URL
+	serializer, err := NewSerializer(cfg)
+	if err != nil {
+		t.Fatalf("NewSerializer failed: %v", err)
+	}
+	publisher := &fakePublisher{}
+	sut := NewEnqueuerWithContext(context.Background(), publisher, slog.Default(), time.Second, cloudevents.ModeStructured, serializer)
+
+	if err := sut.EnqueueRequest(volumeRequest()); err == nil {
+		t.Fatal("expected error for structured CloudEvents with avro")
+	}
+	if publisher.body != nil {
+		t.Fatal("expected nothing to be published")
+	}
+}
+
+func TestEnqueueRequest_UnregisteredSchemaIsDiscardedByOutbox(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".RenderVolumeChanged") {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":40401,"message":"Subject not found"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]int{"id": 7})
+	}))
+	t.Cleanup(server.Close)
+	cfg := DefaultConfig()
+	cfg.Encoding = encoding.FormatAvro
+	cfg.SchemaRegistry.URL = server.URL
+	cfg.AutoRegisterSchemas = false
+	serializer, err := NewSerializer(cfg)
+	if err != nil {
+		t.Fatalf("NewSerializer failed: %v", err)
+	}
+	publisher := &fakePublisher{}
+	transport := NewEnqueuerWithContext(context.Background(), publisher, slog.Default(), time.Second, cloudevents.ModeOff, serializer)
+	sut, err := outbox.New(outbox.Config{Enabled: true, Dir: t.TempDir(), RetryInitialDelay: time.Millisecond, RetryMaxDelay: time.Millisecond}, transport, slog.Default())
+	if err != nil {
+		t.Fatalf("outbox.New failed: %v", err)
+	}
+	defer func() { _ = sut.Close() }()
+
+	captureRequest := volumeRequest()
+	captureRequest.Event = contract.EventTypeCaptureVolumeChanged
+	for _, request := range []enqueuer.Request{volumeRequest(), captureRequest} {
+		if err := sut.EnqueueRequest(request); err != nil {
+			t.Fatalf("EnqueueRequest failed: %v", err)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for sut.Pending() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the unregistered event to be discarded, %d still pending", sut.Pending())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if body := publisher.body; len(body) < 6 || binary.BigEndian.Uint32(body[1:5]) != 7 {
+		t.Fatalf("expected the later event to be published, got %v", body)
+	}
+}
+
+func TestCheckSchemas_ReportsMissingSubjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".RenderVolumeChanged") || strings.HasSuffix(r.URL.Path, ".DefaultCaptureChanged") {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":40401,"message":"Subject not found"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]int{"id": 7})
+	}))
+	t.Cleanup(server.Close)
+	cfg := DefaultConfig()
+	cfg.Encoding = encoding.FormatProtobuf
+	cfg.SchemaRegistry.URL = server.URL
+	cfg.AutoRegisterSchemas = false
+	serializer, err := NewSerializer(cfg)
+	if err != nil {
+		t.Fatalf("NewSerializer failed: %v", err)
+	}
+
+	err = serializer.CheckSchemas(context.Background())
+	if !errors.Is(err, schemaregistry.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	for _, name := range []string{"RenderVolumeChanged", "DefaultCaptureChanged"} {
+		if !strings.Contains(err.Error(), defaultTopic+"-"+encoding.Namespace+"."+name) {
+			t.Fatalf("expected subject of %s in %q", name, err)
+		}
+	}
+	if strings.Contains(err.Error(), "CaptureVolumeChanged") {
+		t.Fatalf("registered subject reported as missing: %q", err)
+	}
+}
+
+func TestCheckSchemas_SkippedWithAutoRegister(t *testing.T) {
+	registry, server := newFakeRegistry(t)
+	cfg := DefaultConfig()
+	cfg.Encoding = encoding.FormatAvro
+	cfg.SchemaRegistry.URL = server.URL
+	serializer, err := NewSerializer(cfg)
+	if err != nil {
+		t.Fatalf("NewSerializer failed: %v", err)
+	}
+
+	if err := serializer.CheckSchemas(context.Background()); err != nil {
+		t.Fatalf("CheckSchemas failed: %v", err)
+	}
+	if len(registry.subjects) != 0 {
+		t.Fatalf("expected no registry calls, got %v", registry.subjects)
+	}
+}
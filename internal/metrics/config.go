@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+const (
+	// Path is where the metrics are served.
+	Path             = "/metrics"
+	envMetricsListen = "WIN_SOUND_METRICS_LISTEN"
+)
+
+// Config selects the address of the metrics listener.
+type Config struct {
+	// ListenAddress is host:port, e.g. ":9464"; empty disables the listener.
+	ListenAddress string
+}
+
+// Enabled reports whether the listener is started.
+func (c Config) Enabled() bool {
+	return c.ListenAddress != ""
+}
+
+// LoadConfigFromEnv loads the listener address from WIN_SOUND_METRICS_LISTEN.
+// The listener is off by default.
+func LoadConfigFromEnv() (Config, error) {
+	cfg := Config{ListenAddress: strings.TrimSpace(os.Getenv(envMetricsListen))}
+	if !cfg.Enabled() {
+		return cfg, nil
+	}
+	if _, _, err := net.SplitHostPort(cfg.ListenAddress); err != nil {
+		return Config{}, fmt.Errorf("invalid %s %q: %w", envMetricsListen, cfg.ListenAddress, err)
+	}
+	return cfg, nil
+}
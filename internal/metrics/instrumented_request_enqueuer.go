@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+// InstrumentedRequestEnqueuer records the result and the duration of every
+// request handed to a transport enqueuer.
+type InstrumentedRequestEnqueuer struct {
+	next      enqueuer.EnqueueRequest
+	transport string
+}
+
+func NewInstrumentedRequestEnqueuer(next enqueuer.EnqueueRequest, transport string) *InstrumentedRequestEnqueuer {
+	if next == nil {
+		panic("nil enqueuer")
+	}
+	if transport == "" {
+		panic("empty transport")
+	}
+	registerTransport(transport)
+	return &InstrumentedRequestEnqueuer{next: next, transport: transport}
+}
+
+func (e *InstrumentedRequestEnqueuer) EnqueueRequest(request enqueuer.Request) error {
+	started := time.Now()
+	err := e.next.EnqueueRequest(request)
+	ObservePublish(e.transport, started, err)
+	return err
+}
@@ -0,0 +1,152 @@
+// Package metrics exposes the scanner pipeline as Prometheus metrics.
+//
+// The collectors are process-wide, like the pipeline they describe, so the
+// transports record into them without having a registry passed around.
+// Serve publishes them on an optional HTTP listener.
+package metrics
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/pipeline"
+)
+
+const namespace = "win_sound_scanner"
+
+const (
+	resultSuccess = "success"
+	resultFailure = "failure"
+)
+
+var (
+	registry = prometheus.NewRegistry()
+
+	eventsReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_received_total",
+		Help:      "Device events received from the device source, by event type.",
+	}, []string{"event_type"})
+
+	enqueueResults = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "enqueue_total",
+		Help:      "Requests handed to a transport, by transport and result. Outbox retries count again.",
+	}, []string{"transport", "result"})
+
+	publishDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "publish_duration_seconds",
+		Help:      "Time a transport takes to publish one request, broker acknowledgement included.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+	}, []string{"transport"})
+
+	lastPublishSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "last_publish_success_timestamp_seconds",
+		Help:      "Unix time of the last request a transport published successfully.",
+	}, []string{"transport"})
+
+	confirmWait = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rabbitmq_confirm_wait_seconds",
+		Help:      "Time between a RabbitMQ publish and its publisher confirm.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+	})
+
+	reconnectAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconnect_attempts_total",
+		Help:      "Broker connection attempts, by transport and result.",
+	}, []string{"transport", "result"})
+
+	// queueStats reads the request queue of the running pipeline, if any.
+	queueStats atomic.Pointer[func() pipeline.QueueStats]
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		eventsReceived,
+		enqueueResults,
+		publishDuration,
+		lastPublishSuccess,
+		confirmWait,
+		reconnectAttempts,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "queue_depth",
+			Help:      "Requests waiting in the in-memory request queue.",
+		}, func() float64 { return float64(currentQueueStats().Depth) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "queue_dropped_total",
+			Help:      "Requests lost to the request queue overflow policy.",
+		}, func() float64 { return float64(currentQueueStats().Dropped()) }),
+	)
+
+	// Export every event type from the start, so rate() sees a zero rather
+	// than a missing series.
+	for event := contract.EventTypeRenderDeviceConfirmed; event <= contract.EventTypeDefaultCaptureChanged; event++ {
+		eventsReceived.WithLabelValues(event.String())
+	}
+}
+
+// EventReceived counts a device event before it enters the pipeline.
+func EventReceived(event contract.EventType) {
+	eventsReceived.WithLabelValues(event.String()).Inc()
+}
+
+// ObservePublish records one publish attempt of transport that started at
+// started and ended with err.
+func ObservePublish(transport string, started time.Time, err error) {
+	publishDuration.WithLabelValues(transport).Observe(time.Since(started).Seconds())
+	if err != nil {
+		enqueueResults.WithLabelValues(transport, resultFailure).Inc()
+		return
+	}
+	enqueueResults.WithLabelValues(transport, resultSuccess).Inc()
+	lastPublishSuccess.WithLabelValues(transport).SetToCurrentTime()
+}
+
+// ObserveConfirmWait records how long RabbitMQ took to confirm a publish.
+func ObserveConfirmWait(d time.Duration) {
+	confirmWait.Observe(d.Seconds())
+}
+
+// ReconnectAttempt counts one connection attempt of transport.
+func ReconnectAttempt(transport string, err error) {
+	result := resultSuccess
+	if err != nil {
+		result = resultFailure
+	}
+	reconnectAttempts.WithLabelValues(transport, result).Inc()
+}
+
+// SetQueue makes the queue metrics read stats; nil detaches the queue.
+func SetQueue(stats func() pipeline.QueueStats) {
+	if stats == nil {
+		queueStats.Store(nil)
+		return
+	}
+	queueStats.Store(&stats)
+}
+
+func currentQueueStats() pipeline.QueueStats {
+	if stats := queueStats.Load(); stats != nil {
+		return (*stats)()
+	}
+	return pipeline.QueueStats{}
+}
+
+// registerTransport exports the series of transport with zero values.
+func registerTransport(transport string) {
+	enqueueResults.WithLabelValues(transport, resultSuccess)
+	enqueueResults.WithLabelValues(transport, resultFailure)
+	publishDuration.WithLabelValues(transport)
+}
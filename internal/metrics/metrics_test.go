@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/pipeline"
+)
+
+type funcEnqueuer func(enqueuer.Request) error
+
+func (f funcEnqueuer) EnqueueRequest(request enqueuer.Request) error {
+	return f(request)
+}
+
+func scrape(t *testing.T) string {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, Path, nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", recorder.Code)
+	}
+	body, _ := io.ReadAll(recorder.Body)
+	return string(body)
+}
+
+func expectLines(t *testing.T, body string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if !strings.Contains(body, line) {
+			t.Fatalf("expected %q in:\n%s", line, body)
+		}
+	}
+}
+
+func TestInstrumentedEnqueuer_RecordsResults(t *testing.T) {
+	fail := false
+	sut := NewInstrumentedRequestEnqueuer(funcEnqueuer(func(enqueuer.Request) error {
+		if fail {
+			return errors.New("broker down")
+		}
+		return nil
+	}), "test-transport")
+
+	body := scrape(t)
+	expectLines(t, body, `win_sound_scanner_enqueue_total{result="failure",transport="test-transport"} 0`)
+	if strings.Contains(body, `last_publish_success_timestamp_seconds{transport="test-transport"}`) {
+		t.Fatal("expected no last publish time before the first success")
+	}
+
+	_ = sut.EnqueueRequest(enqueuer.Request{})
+	fail = true
+	if err := sut.EnqueueRequest(enqueuer.Request{}); err == nil {
+		t.Fatal("expected the transport error to be passed on")
+	}
+
+	body = scrape(t)
+	expectLines(t, body,
+		`win_sound_scanner_enqueue_total{result="success",transport="test-transport"} 1`,
+		`win_sound_scanner_enqueue_total{result="failure",transport="test-transport"} 1`,
+		`win_sound_scanner_publish_duration_seconds_count{transport="test-transport"} 2`,
+		`win_sound_scanner_last_publish_success_timestamp_seconds{transport="test-transport"}`,
+	)
+}
+
+func TestEventsAndQueue(t *testing.T) {
+	expectLines(t, scrape(t), `win_sound_scanner_events_received_total{event_type="DefaultCaptureChanged"} 0`)
+
+	EventReceived(contract.EventTypeRenderVolumeChanged)
+	SetQueue(func() pipeline.QueueStats { return pipeline.QueueStats{Depth: 7, DroppedOldest: 2} })
+	defer SetQueue(nil)
+
+	expectLines(t, scrape(t),
+		`win_sound_scanner_events_received_total{event_type="RenderVolumeChanged"} 1`,
+		"win_sound_scanner_queue_depth 7",
+		"win_sound_scanner_queue_dropped_total 2",
+	)
+}
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	t.Setenv(envMetricsListen, "")
+	cfg, err := LoadConfigFromEnv()
+	if err != nil || cfg.Enabled() {
+		t.Fatalf("expected the listener to be off by default, got %+v (err %v)", cfg, err)
+	}
+
+	t.Setenv(envMetricsListen, ":9464")
+	if cfg, err := LoadConfigFromEnv(); err != nil || cfg.ListenAddress != ":9464" {
+		t.Fatalf("unexpected config %+v (err %v)", cfg, err)
+	}
+
+	t.Setenv(envMetricsListen, "9464")
+	if _, err := LoadConfigFromEnv(); err == nil {
+		t.Fatal("expected error for an address without a port separator")
+	}
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// Serve starts the metrics listener and returns a function that stops it.
+// The address is bound before Serve returns, so a port in use is reported
+// at startup. routes maps further paths, such as the health endpoints, to
+// their handlers on the same listener.
+func Serve(cfg Config, routes map[string]http.Handler, logger *slog.Logger) (func(), error) {
+	if logger == nil {
+		panic("nil logger")
+	}
+
+	listener, err := net.Listen("tcp", cfg.ListenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("metrics listener: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(Path, Handler())
+	paths := []string{Path}
+	for path, handler := range routes {
+		mux.Handle(path, handler)
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Metrics listener failed", "err", err)
+		}
+	}()
+	logger.Info("Metrics listener started", "address", listener.Addr().String(), "paths", paths)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			logger.Warn("Metrics listener shutdown failed", "err", err)
+		}
+		<-done
+	}, nil
+}
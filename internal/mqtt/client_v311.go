@@ -0,0 +1,62 @@
+package mqtt
+
+import (
+	"context"
+	"errors"
+
+	paho3 "github.com/eclipse/paho.mqtt.golang"
+)
+
+const disconnectQuiesceMillis = 250
+
+// v311Client publishes through the Eclipse Paho MQTT 3.1.1 client.
+type v311Client struct {
+	client paho3.Client
+}
+
+func connectV311(ctx context.Context, s session) (*v311Client, error) {
+	c := &v311Client{}
+	opts := paho3.NewClientOptions().
+		AddBroker(s.cfg.Broker).
+		SetClientID(s.cfg.ClientID).
+		SetUsername(s.cfg.User).
+		SetPassword(s.cfg.Password).
+		SetProtocolVersion(uint(ProtocolVersion311)).
+		SetKeepAlive(s.cfg.KeepAlive).
+		SetConnectTimeout(s.cfg.ConnectTimeout).
+		SetBinaryWill(s.statusTopic, []byte(statusOffline), s.cfg.QoS, true).
+		SetAutoReconnect(true).
+		SetOnConnectHandler(func(paho3.Client) {
+			s.announceOnline(c)
+		}).
+		SetConnectionLostHandler(func(_ paho3.Client, err error) {
+			s.logger.Warn("MQTT connection lost; reconnecting", "err", err)
+		})
+	c.client = paho3.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.ConnectTimeout)
+	defer cancel()
+	if err := waitToken(ctx, c.client.Connect()); err != nil {
+		c.client.Disconnect(0)
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *v311Client) publish(ctx context.Context, topic string, qos byte, retained bool, payload []byte) error {
+	return waitToken(ctx, c.client.Publish(topic, qos, retained, payload))
+}
+
+func (c *v311Client) disconnect(context.Context) error {
+	c.client.Disconnect(disconnectQuiesceMillis)
+	return nil
+}
+
+func waitToken(ctx context.Context, token paho3.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return errors.Join(ctx.Err(), token.Error())
+	}
+}
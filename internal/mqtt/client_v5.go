@@ -0,0 +1,79 @@
+package mqtt
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/eclipse/paho.golang/autopaho"
+	"github.com/eclipse/paho.golang/paho"
+)
+
+// v5Client publishes through the Eclipse Paho MQTT 5 connection manager,
+// which reconnects in the background.
+type v5Client struct {
+	cm     *autopaho.ConnectionManager
+	cancel context.CancelFunc
+}
+
+func connectV5(ctx context.Context, s session) (*v5Client, error) {
+	brokerURL, err := url.Parse(s.cfg.Broker)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &v5Client{}
+	cfg := autopaho.ClientConfig{
+		ServerUrls:                    []*url.URL{brokerURL},
+		KeepAlive:                     uint16(s.cfg.KeepAlive.Seconds()),
+		CleanStartOnInitialConnection: true,
+		ConnectTimeout:                s.cfg.ConnectTimeout,
+		ConnectUsername:               s.cfg.User,
+		ConnectPassword:               []byte(s.cfg.Password),
+		WillMessage: &paho.WillMessage{
+			Retain:  true,
+			QoS:     s.cfg.QoS,
+			Topic:   s.statusTopic,
+			Payload: []byte(statusOffline),
+		},
+		OnConnectionUp: func(*autopaho.ConnectionManager, *paho.Connack) {
+			go s.announceOnline(c)
+		},
+		OnConnectError: func(err error) {
+			s.logger.Warn("MQTT connection attempt failed", "err", err)
+		},
+		ClientConfig: paho.ClientConfig{ClientID: s.cfg.ClientID},
+	}
+
+	// The connection outlives ctx; it is stopped by disconnect.
+	runCtx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	cm, err := autopaho.NewConnection(runCtx, cfg)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	c.cm = cm
+
+	waitCtx, cancelWait := context.WithTimeout(ctx, s.cfg.ConnectTimeout)
+	defer cancelWait()
+	if err := cm.AwaitConnection(waitCtx); err != nil {
+		cancel()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *v5Client) publish(ctx context.Context, topic string, qos byte, retained bool, payload []byte) error {
+	_, err := c.cm.Publish(ctx, &paho.Publish{
+		QoS:     qos,
+		Retain:  retained,
+		Topic:   topic,
+		Payload: payload,
+	})
+	return err
+}
+
+func (c *v5Client) disconnect(ctx context.Context) error {
+	defer c.cancel()
+	return c.cm.Disconnect(ctx)
+}
@@ -0,0 +1,216 @@
+package mqtt
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/envconfig"
+)
+
+// ProtocolVersion is the MQTT protocol level sent in CONNECT.
+type ProtocolVersion uint
+
+const (
+	ProtocolVersion311 ProtocolVersion = 4
+	ProtocolVersion5   ProtocolVersion = 5
+)
+
+func (v ProtocolVersion) String() string {
+	switch v {
+	case ProtocolVersion311:
+		return "3.1.1"
+	case ProtocolVersion5:
+		return "5"
+	default:
+		return strconv.FormatUint(uint64(v), 10)
+	}
+}
+
+// ParseProtocolVersion accepts "3.1.1" (or "4") and "5" (or "5.0").
+func ParseProtocolVersion(raw string) (ProtocolVersion, error) {
+	switch strings.TrimSpace(raw) {
+	case "3.1.1", "4":
+		return ProtocolVersion311, nil
+	case "5", "5.0":
+		return ProtocolVersion5, nil
+	default:
+		return 0, fmt.Errorf("unsupported MQTT protocol version %q (supported: 3.1.1, 5)", raw)
+	}
+}
+
+const (
+	defaultBroker          = "tcp://localhost:1883"
+	defaultClientIDPrefix  = "win-sound-scanner"
+	defaultTopic           = "win-sound-scanner/{host}/{pnpId}"
+	defaultStatusTopic     = "win-sound-scanner/{host}/status"
+	defaultQoS             = 1
+	defaultConnectTimeout  = 10 * time.Second
+	defaultPublishTimeout  = 10 * time.Second
+	defaultKeepAlive       = 30 * time.Second
+	envMqttBroker          = "WIN_SOUND_MQTT_BROKER"
+	envMqttClientID        = "WIN_SOUND_MQTT_CLIENT_ID"
+	envMqttUser            = "WIN_SOUND_MQTT_USER"
+	envMqttPassword        = "WIN_SOUND_MQTT_PASSWORD"
+	envMqttProtocolVersion = "WIN_SOUND_MQTT_PROTOCOL_VERSION"
+	envMqttTopic           = "WIN_SOUND_MQTT_TOPIC"
+	envMqttStatusTopic     = "WIN_SOUND_MQTT_STATUS_TOPIC"
+	envMqttQoS             = "WIN_SOUND_MQTT_QOS"
+	envMqttRetained        = "WIN_SOUND_MQTT_RETAINED"
+	envMqttConnectTimeout  = "WIN_SOUND_MQTT_CONNECT_TIMEOUT_MS"
+	envMqttPublishTimeout  = "WIN_SOUND_MQTT_PUBLISH_TIMEOUT_MS"
+)
+
+// Config defines MQTT broker connection, topic and delivery settings.
+type Config struct {
+	Broker          string
+	ClientID        string
+	User            string
+	Password        string
+	ProtocolVersion ProtocolVersion
+	// Topic is the request topic template, see TopicTemplate.
+	Topic TopicTemplate
+	// StatusTopic receives retained "online"/"offline" messages; "offline"
+	// is also registered as the last-will message.
+	StatusTopic    TopicTemplate
+	QoS            byte
+	Retained       bool
+	ConnectTimeout time.Duration
+	PublishTimeout time.Duration
+	KeepAlive      time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Broker:          defaultBroker,
+		ClientID:        defaultClientID(),
+		ProtocolVersion: ProtocolVersion311,
+		Topic:           defaultTopic,
+		StatusTopic:     defaultStatusTopic,
+		QoS:             defaultQoS,
+		ConnectTimeout:  defaultConnectTimeout,
+		PublishTimeout:  defaultPublishTimeout,
+		KeepAlive:       defaultKeepAlive,
+	}
+}
+
+// defaultClientID makes the client id unique per machine, since brokers
+// disconnect the older session when two clients share an id.
+func defaultClientID() string {
+	if hostName, err := os.Hostname(); err == nil && strings.TrimSpace(hostName) != "" {
+		return defaultClientIDPrefix + "-" + strings.TrimSpace(hostName)
+	}
+	return defaultClientIDPrefix
+}
+
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	c.Broker = defaultTrimmedString(c.Broker, d.Broker)
+	c.ClientID = defaultTrimmedString(c.ClientID, d.ClientID)
+	if c.ProtocolVersion == 0 {
+		c.ProtocolVersion = d.ProtocolVersion
+	}
+	c.Topic = TopicTemplate(defaultTrimmedString(string(c.Topic), string(d.Topic)))
+	c.StatusTopic = TopicTemplate(defaultTrimmedString(string(c.StatusTopic), string(d.StatusTopic)))
+	if c.ConnectTimeout <= 0 {
+		c.ConnectTimeout = d.ConnectTimeout
+	}
+	if c.PublishTimeout <= 0 {
+		c.PublishTimeout = d.PublishTimeout
+	}
+	if c.KeepAlive <= 0 {
+		c.KeepAlive = d.KeepAlive
+	}
+	return c
+}
+
+func (c Config) validate() error {
+	u, err := url.Parse(c.Broker)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", envMqttBroker, c.Broker, err)
+	}
+	switch u.Scheme {
+	case "tcp", "mqtt", "ssl", "tls", "mqtts", "ws", "wss":
+	default:
+		return fmt.Errorf("invalid %s %q: unsupported scheme %q", envMqttBroker, c.Broker, u.Scheme)
+	}
+	if c.ProtocolVersion != ProtocolVersion311 && c.ProtocolVersion != ProtocolVersion5 {
+		return fmt.Errorf("unsupported MQTT protocol version %s", c.ProtocolVersion)
+	}
+	if c.QoS > 2 {
+		return fmt.Errorf("invalid MQTT QoS %d (supported: 0, 1, 2)", c.QoS)
+	}
+	if err := c.Topic.validate(); err != nil {
+		return fmt.Errorf("invalid %s: %w", envMqttTopic, err)
+	}
+	if err := c.StatusTopic.validate(); err != nil {
+		return fmt.Errorf("invalid %s: %w", envMqttStatusTopic, err)
+	}
+	return nil
+}
+
+// LoadConfigFromEnv loads MQTT configuration from environment variables.
+// Empty values are replaced by defaults.
+func LoadConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+
+	cfg.Broker = envconfig.TrimmedString(envMqttBroker, cfg.Broker)
+	cfg.ClientID = envconfig.TrimmedString(envMqttClientID, cfg.ClientID)
+	cfg.User = envconfig.String(envMqttUser, cfg.User)
+	cfg.Password = envconfig.String(envMqttPassword, cfg.Password)
+	cfg.Topic = TopicTemplate(envconfig.TrimmedString(envMqttTopic, string(cfg.Topic)))
+	cfg.StatusTopic = TopicTemplate(envconfig.TrimmedString(envMqttStatusTopic, string(cfg.StatusTopic)))
+
+	if v := strings.TrimSpace(os.Getenv(envMqttProtocolVersion)); v != "" {
+		version, err := ParseProtocolVersion(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s: %w", envMqttProtocolVersion, err)
+		}
+		cfg.ProtocolVersion = version
+	}
+
+	qos, err := envconfig.Int(envMqttQoS, int(cfg.QoS))
+	if err != nil {
+		return Config{}, err
+	}
+	if qos < 0 || qos > 2 {
+		return Config{}, fmt.Errorf("invalid %s %q: must be 0, 1 or 2", envMqttQoS, strings.TrimSpace(os.Getenv(envMqttQoS)))
+	}
+	cfg.QoS = byte(qos)
+
+	if v := strings.TrimSpace(os.Getenv(envMqttRetained)); v != "" {
+		retained, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s %q: %w", envMqttRetained, v, err)
+		}
+		cfg.Retained = retained
+	}
+
+	connectTimeoutMillis, err := envconfig.NonNegativeInt(envMqttConnectTimeout, int(cfg.ConnectTimeout/time.Millisecond))
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.ConnectTimeout = time.Duration(connectTimeoutMillis) * time.Millisecond
+
+	publishTimeoutMillis, err := envconfig.NonNegativeInt(envMqttPublishTimeout, int(cfg.PublishTimeout/time.Millisecond))
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.PublishTimeout = time.Duration(publishTimeoutMillis) * time.Millisecond
+
+	cfg = cfg.withDefaults()
+	if err := cfg.validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func defaultTrimmedString(v, fallback string) string {
+	if strings.TrimSpace(v) == "" {
+		return fallback
+	}
+	return strings.TrimSpace(v)
+}
@@ -0,0 +1,85 @@
+package mqtt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadConfigFromEnv_Defaults(t *testing.T) {
+	for _, key := range []string{envMqttBroker, envMqttClientID, envMqttProtocolVersion, envMqttTopic, envMqttStatusTopic, envMqttQoS, envMqttRetained, envMqttConnectTimeout, envMqttPublishTimeout} {
+		t.Setenv(key, "")
+	}
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed: %v", err)
+	}
+
+	if cfg.Broker != defaultBroker {
+		t.Fatalf("expected broker %q, got %q", defaultBroker, cfg.Broker)
+	}
+	if cfg.ProtocolVersion != ProtocolVersion311 {
+		t.Fatalf("expected protocol 3.1.1, got %s", cfg.ProtocolVersion)
+	}
+	if cfg.Topic != defaultTopic || cfg.StatusTopic != defaultStatusTopic {
+		t.Fatalf("unexpected topics: %q, %q", cfg.Topic, cfg.StatusTopic)
+	}
+	if cfg.QoS != defaultQoS || cfg.Retained {
+		t.Fatalf("unexpected qos/retained: %d/%t", cfg.QoS, cfg.Retained)
+	}
+	if cfg.ClientID == "" {
+		t.Fatal("expected a default client id")
+	}
+}
+
+func TestLoadConfigFromEnv_Overrides(t *testing.T) {
+	t.Setenv(envMqttBroker, "ssl://mosquitto:8883")
+	t.Setenv(envMqttClientID, "scanner-1")
+	t.Setenv(envMqttProtocolVersion, "5")
+	t.Setenv(envMqttTopic, "site-a/audio/{host}/{pnpId}")
+	t.Setenv(envMqttStatusTopic, "site-a/scanners/{host}")
+	t.Setenv(envMqttQoS, "2")
+	t.Setenv(envMqttRetained, "true")
+	t.Setenv(envMqttConnectTimeout, "1500")
+	t.Setenv(envMqttPublishTimeout, "2500")
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed: %v", err)
+	}
+
+	if cfg.Broker != "ssl://mosquitto:8883" || cfg.ClientID != "scanner-1" {
+		t.Fatalf("unexpected broker/client id: %q/%q", cfg.Broker, cfg.ClientID)
+	}
+	if cfg.ProtocolVersion != ProtocolVersion5 {
+		t.Fatalf("expected protocol 5, got %s", cfg.ProtocolVersion)
+	}
+	if cfg.Topic != "site-a/audio/{host}/{pnpId}" || cfg.StatusTopic != "site-a/scanners/{host}" {
+		t.Fatalf("unexpected topics: %q, %q", cfg.Topic, cfg.StatusTopic)
+	}
+	if cfg.QoS != 2 || !cfg.Retained {
+		t.Fatalf("unexpected qos/retained: %d/%t", cfg.QoS, cfg.Retained)
+	}
+	if cfg.ConnectTimeout != 1500*time.Millisecond || cfg.PublishTimeout != 2500*time.Millisecond {
+		t.Fatalf("unexpected timeouts: %s/%s", cfg.ConnectTimeout, cfg.PublishTimeout)
+	}
+}
+
+func TestLoadConfigFromEnv_InvalidValues(t *testing.T) {
+	cases := map[string]string{
+		envMqttBroker:          "http://mosquitto:1883",
+		envMqttProtocolVersion: "3.1",
+		envMqttTopic:           "audio/+/{pnpId}",
+		envMqttQoS:             "3",
+		envMqttRetained:        "maybe",
+		envMqttPublishTimeout:  "-1",
+	}
+	for key, value := range cases {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			if _, err := LoadConfigFromEnv(); err == nil {
+				t.Fatalf("expected error for %s=%q", key, value)
+			}
+		})
+	}
+}
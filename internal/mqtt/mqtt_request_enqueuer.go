@@ -0,0 +1,84 @@
+package mqtt
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/cloudevents"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+// MessagePublisher is the publishing contract expected from an MQTT publisher.
+type MessagePublisher interface {
+	Publish(ctx context.Context, topic string, body []byte) error
+	Close() error
+}
+
+// Enqueuer publishes requests to per-device MQTT topics using the shared message-shaping.
+type Enqueuer struct {
+	baseCtx        context.Context
+	publisher      MessagePublisher
+	logger         *slog.Logger
+	topic          TopicTemplate
+	publishTimeout time.Duration
+	cloudEvents    cloudevents.Mode
+}
+
+func NewEnqueuerWithContext(baseCtx context.Context, publisher MessagePublisher, logger *slog.Logger, topic TopicTemplate, publishTimeout time.Duration, cloudEvents cloudevents.Mode) *Enqueuer {
+	if baseCtx == nil {
+		panic("nil context")
+	}
+	if publisher == nil {
+		panic("nil publisher")
+	}
+	if logger == nil {
+		panic("nil logger")
+	}
+	if topic == "" {
+		topic = defaultTopic
+	}
+	if publishTimeout <= 0 {
+		publishTimeout = defaultPublishTimeout
+	}
+
+	return &Enqueuer{
+		baseCtx:        baseCtx,
+		publisher:      publisher,
+		logger:         logger,
+		topic:          topic,
+		publishTimeout: publishTimeout,
+		cloudEvents:    cloudEvents,
+	}
+}
+
+func (e *Enqueuer) EnqueueRequest(request enqueuer.Request) error {
+	e.logger.Info("Preparing request in MQTT enqueuer", "event", request.Event, "fields", request.Fields)
+	payload, err := enqueuer.BuildRequestPayload(request)
+	if err != nil {
+		return fmt.Errorf("marshal mqtt payload: %w", err)
+	}
+	// There is no binary-mode binding here, so both modes publish structured events.
+	if e.cloudEvents != cloudevents.ModeOff {
+		if payload, err = cloudevents.Structured(payload); err != nil {
+			return fmt.Errorf("marshal mqtt payload: %w", err)
+		}
+	}
+
+	topic := e.topic.Render(request.Fields[contract.FieldHostName], request.Fields[contract.FieldPnpID])
+	e.logger.Info("publishing request", "method", payload.HTTPRequest, "urlSuffix", payload.URLSuffix, "topic", topic, "updated", payload.UpdateDateUtc)
+
+	ctx, cancel := context.WithTimeout(e.baseCtx, e.publishTimeout)
+	defer cancel()
+	if err := e.publisher.Publish(ctx, topic, payload.Body); err != nil {
+		return fmt.Errorf("publish request: %w", err)
+	}
+
+	return nil
+}
+
+func (e *Enqueuer) Close() error {
+	return e.publisher.Close()
+}
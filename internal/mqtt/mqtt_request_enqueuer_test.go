@@ -0,0 +1,78 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/cloudevents"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+type fakePublisher struct {
+	topic string
+	body  []byte
+	err   error
+}
+
+func (p *fakePublisher) Publish(_ context.Context, topic string, body []byte) error {
+	p.topic = topic
+	p.body = append([]byte(nil), body...)
+	return p.err
+}
+
+func (p *fakePublisher) Close() error {
+	return nil
+}
+
+func testRequest() enqueuer.Request {
+	return enqueuer.Request{
+		Timestamp: time.Date(2026, 5, 26, 10, 0, 0, 0, time.UTC),
+		Event:     contract.EventTypeRenderDeviceDiscovered,
+		Fields: map[string]string{
+			contract.FieldPnpID:    `SWD\MMDEVAPI\{0.0.0.00000000}`,
+			contract.FieldHostName: "host-1",
+		},
+	}
+}
+
+func TestEnqueueRequest_PublishesPayloadToDeviceTopic(t *testing.T) {
+	publisher := &fakePublisher{}
+	sut := NewEnqueuerWithContext(context.Background(), publisher, slog.Default(), "audio/{host}/{pnpId}", time.Second, cloudevents.ModeOff)
+
+	if err := sut.EnqueueRequest(testRequest()); err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+
+	if publisher.topic != `audio/host-1/SWD\MMDEVAPI\{0.0.0.00000000}` {
+		t.Fatalf("unexpected topic: %q", publisher.topic)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(publisher.body, &payload); err != nil {
+		t.Fatalf("payload is not valid JSON: %v", err)
+	}
+	if payload[contract.FieldHTTPRequest] != "POST" {
+		t.Fatalf("expected POST payload, got %#v", payload[contract.FieldHTTPRequest])
+	}
+}
+
+func TestEnqueueRequest_PropagatesPublisherError(t *testing.T) {
+	publisher := &fakePublisher{err: errors.New("broker down")}
+	sut := NewEnqueuerWithContext(context.Background(), publisher, slog.Default(), defaultTopic, time.Second, cloudevents.ModeOff)
+
+	if err := sut.EnqueueRequest(testRequest()); err == nil {
+		t.Fatal("expected publish error")
+	}
+}
+
+func TestTopicTemplate_RenderKeepsValuesInOneLevel(t *testing.T) {
+	got := TopicTemplate("audio/{host}/{pnpId}/state").Render("", "a/b+c#d")
+	if got != "audio/unknown/a_b_c_d/state" {
+		t.Fatalf("unexpected topic: %q", got)
+	}
+}
@@ -0,0 +1,100 @@
+package mqtt
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+const (
+	statusOnline  = "online"
+	statusOffline = "offline"
+)
+
+// brokerClient hides the differences between the MQTT 3.1.1 and MQTT 5 clients.
+type brokerClient interface {
+	publish(ctx context.Context, topic string, qos byte, retained bool, payload []byte) error
+	disconnect(ctx context.Context) error
+}
+
+// session carries what both clients need to connect and announce presence.
+type session struct {
+	cfg         Config
+	logger      *slog.Logger
+	statusTopic string
+}
+
+// announceOnline publishes the retained "online" status after every
+// (re)connect, replacing the "offline" last-will left by a dropped connection.
+func (s session) announceOnline(client brokerClient) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.PublishTimeout)
+	defer cancel()
+	if err := client.publish(ctx, s.statusTopic, s.cfg.QoS, true, []byte(statusOnline)); err != nil {
+		s.logger.Warn("MQTT online status publish failed", "topic", s.statusTopic, "err", err)
+	}
+}
+
+// RequestPublisher publishes request payloads to an MQTT broker.
+type RequestPublisher struct {
+	session
+	client brokerClient
+}
+
+// NewRequestPublisher connects to the broker with the "offline" status as
+// last-will message and fails if the connection is not up within ConnectTimeout.
+func NewRequestPublisher(ctx context.Context, cfg Config, logger *slog.Logger) (*RequestPublisher, error) {
+	if ctx == nil {
+		panic("nil context")
+	}
+	if logger == nil {
+		panic("nil logger")
+	}
+
+	cfg = cfg.withDefaults()
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	hostName, err := os.Hostname()
+	if err != nil || strings.TrimSpace(hostName) == "" {
+		hostName = "unknown-host"
+	}
+	s := session{cfg: cfg, logger: logger, statusTopic: cfg.StatusTopic.Render(hostName, "")}
+
+	var client brokerClient
+	switch cfg.ProtocolVersion {
+	case ProtocolVersion5:
+		client, err = connectV5(ctx, s)
+	default:
+		client, err = connectV311(ctx, s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mqtt connect to %s failed: %w", cfg.Broker, err)
+	}
+
+	logger.Info("MQTT publisher connected", "broker", cfg.Broker, "clientId", cfg.ClientID, "protocol", cfg.ProtocolVersion.String(), "statusTopic", s.statusTopic)
+	return &RequestPublisher{session: s, client: client}, nil
+}
+
+func (p *RequestPublisher) Publish(ctx context.Context, topic string, body []byte) error {
+	if ctx == nil {
+		panic("nil context")
+	}
+	if err := p.client.publish(ctx, topic, p.cfg.QoS, p.cfg.Retained, body); err != nil {
+		return fmt.Errorf("mqtt publish to %q failed: %w", topic, err)
+	}
+	return nil
+}
+
+// Close publishes the retained "offline" status and disconnects. A clean
+// disconnect discards the last-will, so the status is sent explicitly.
+func (p *RequestPublisher) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.cfg.PublishTimeout)
+	defer cancel()
+	if err := p.client.publish(ctx, p.statusTopic, p.cfg.QoS, true, []byte(statusOffline)); err != nil {
+		p.logger.Warn("MQTT offline status publish failed", "topic", p.statusTopic, "err", err)
+	}
+	return p.client.disconnect(ctx)
+}
@@ -0,0 +1,43 @@
+package mqtt
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	topicPlaceholderHost  = "{host}"
+	topicPlaceholderPnpID = "{pnpId}"
+	unknownTopicSegment   = "unknown"
+)
+
+// TopicTemplate is a topic name with optional {host} and {pnpId}
+// placeholders, for example "win-sound-scanner/{host}/{pnpId}".
+type TopicTemplate string
+
+// Render substitutes the placeholders. Values are reduced to a single topic
+// level: '/', '+' and '#' are replaced by '_' and empty values by "unknown".
+func (t TopicTemplate) Render(hostName, pnpID string) string {
+	return strings.NewReplacer(
+		topicPlaceholderHost, topicSegment(hostName),
+		topicPlaceholderPnpID, topicSegment(pnpID),
+	).Replace(string(t))
+}
+
+func (t TopicTemplate) validate() error {
+	if strings.TrimSpace(string(t)) == "" {
+		return errors.New("topic is empty")
+	}
+	if strings.ContainsAny(string(t), "+#") {
+		return errors.New("topic must not contain wildcards '+' or '#'")
+	}
+	return nil
+}
+
+func topicSegment(v string) string {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return unknownTopicSegment
+	}
+	return strings.NewReplacer("/", "_", "+", "_", "#", "_").Replace(v)
+}
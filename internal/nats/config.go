@@ -0,0 +1,125 @@
+package nats
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultURL            = "nats://localhost:4222"
+	defaultSubjectPrefix  = "win-sound-scanner"
+	defaultClientName     = "win-sound-scanner"
+	defaultPublishTimeout = 10 * time.Second
+	envNatsURL            = "WIN_SOUND_NATS_URL"
+	envNatsUser           = "WIN_SOUND_NATS_USER"
+	envNatsPassword       = "WIN_SOUND_NATS_PASSWORD"
+	envNatsClientName     = "WIN_SOUND_NATS_CLIENT_NAME"
+	envNatsSubjectPrefix  = "WIN_SOUND_NATS_SUBJECT_PREFIX"
+	envNatsJetStream      = "WIN_SOUND_NATS_JETSTREAM"
+	envNatsStream         = "WIN_SOUND_NATS_STREAM"
+	envNatsPublishTimeout = "WIN_SOUND_NATS_PUBLISH_TIMEOUT_MS"
+)
+
+// Config defines NATS connection, subject and JetStream settings.
+type Config struct {
+	// URL is a single server URL or a comma-separated list of them.
+	URL           string
+	User          string
+	Password      string
+	ClientName    string
+	SubjectPrefix string
+	// JetStream switches from fire-and-forget core NATS publishing to
+	// JetStream publishing that waits for the stream's ack.
+	JetStream bool
+	// Stream, when set in JetStream mode, is created on startup if it does
+	// not exist yet, capturing "<SubjectPrefix>.>".
+	Stream         string
+	PublishTimeout time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		URL:            defaultURL,
+		ClientName:     defaultClientName,
+		SubjectPrefix:  defaultSubjectPrefix,
+		PublishTimeout: defaultPublishTimeout,
+	}
+}
+
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	if strings.TrimSpace(c.URL) == "" {
+		c.URL = d.URL
+	}
+	if strings.TrimSpace(c.ClientName) == "" {
+		c.ClientName = d.ClientName
+	}
+	c.SubjectPrefix = strings.Trim(strings.TrimSpace(c.SubjectPrefix), ".")
+	if c.SubjectPrefix == "" {
+		c.SubjectPrefix = d.SubjectPrefix
+	}
+	c.Stream = strings.TrimSpace(c.Stream)
+	if c.PublishTimeout <= 0 {
+		c.PublishTimeout = d.PublishTimeout
+	}
+	return c
+}
+
+func (c Config) validate() error {
+	if strings.ContainsAny(c.SubjectPrefix, " \t*>") {
+		return fmt.Errorf("invalid %s %q: must not contain whitespace or wildcards", envNatsSubjectPrefix, c.SubjectPrefix)
+	}
+	if strings.ContainsAny(c.Stream, " \t.*>/\\") {
+		return fmt.Errorf("invalid %s %q: must not contain whitespace, '.', '*', '>', '/' or '\\'", envNatsStream, c.Stream)
+	}
+	return nil
+}
+
+func LoadConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+
+	if v := strings.TrimSpace(os.Getenv(envNatsURL)); v != "" {
+		cfg.URL = v
+	}
+	if v := os.Getenv(envNatsUser); v != "" {
+		cfg.User = v
+	}
+	if v := os.Getenv(envNatsPassword); v != "" {
+		cfg.Password = v
+	}
+	if v := strings.TrimSpace(os.Getenv(envNatsClientName)); v != "" {
+		cfg.ClientName = v
+	}
+	if v := strings.TrimSpace(os.Getenv(envNatsSubjectPrefix)); v != "" {
+		cfg.SubjectPrefix = v
+	}
+	if v := strings.TrimSpace(os.Getenv(envNatsJetStream)); v != "" {
+		jetStream, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s %q: %w", envNatsJetStream, v, err)
+		}
+		cfg.JetStream = jetStream
+	}
+	if v := strings.TrimSpace(os.Getenv(envNatsStream)); v != "" {
+		cfg.Stream = v
+	}
+	if v := strings.TrimSpace(os.Getenv(envNatsPublishTimeout)); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s %q: %w", envNatsPublishTimeout, v, err)
+		}
+		if n < 0 {
+			return Config{}, fmt.Errorf("%s can not be negative %q", envNatsPublishTimeout, v)
+		}
+		cfg.PublishTimeout = time.Duration(n) * time.Millisecond
+	}
+
+	cfg = cfg.withDefaults()
+	if err := cfg.validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
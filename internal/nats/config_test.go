@@ -0,0 +1,74 @@
+package nats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadConfigFromEnv_Defaults(t *testing.T) {
+	for _, key := range []string{envNatsURL, envNatsClientName, envNatsSubjectPrefix, envNatsJetStream, envNatsStream, envNatsPublishTimeout} {
+		t.Setenv(key, "")
+	}
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed: %v", err)
+	}
+
+	if cfg.URL != defaultURL {
+		t.Fatalf("expected url %q, got %q", defaultURL, cfg.URL)
+	}
+	if cfg.SubjectPrefix != defaultSubjectPrefix {
+		t.Fatalf("expected subject prefix %q, got %q", defaultSubjectPrefix, cfg.SubjectPrefix)
+	}
+	if cfg.JetStream || cfg.Stream != "" {
+		t.Fatalf("expected core NATS mode, got jetStream=%t stream=%q", cfg.JetStream, cfg.Stream)
+	}
+	if cfg.PublishTimeout != defaultPublishTimeout {
+		t.Fatalf("expected timeout %s, got %s", defaultPublishTimeout, cfg.PublishTimeout)
+	}
+}
+
+func TestLoadConfigFromEnv_Overrides(t *testing.T) {
+	t.Setenv(envNatsURL, "nats://nats-1:4222,nats://nats-2:4222")
+	t.Setenv(envNatsClientName, "scanner-1")
+	t.Setenv(envNatsSubjectPrefix, "site-a.audio.")
+	t.Setenv(envNatsJetStream, "true")
+	t.Setenv(envNatsStream, "AUDIO_DEVICES")
+	t.Setenv(envNatsPublishTimeout, "2500")
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed: %v", err)
+	}
+
+	if cfg.URL != "nats://nats-1:4222,nats://nats-2:4222" || cfg.ClientName != "scanner-1" {
+		t.Fatalf("unexpected url/client name: %q/%q", cfg.URL, cfg.ClientName)
+	}
+	if cfg.SubjectPrefix != "site-a.audio" {
+		t.Fatalf("unexpected subject prefix: %q", cfg.SubjectPrefix)
+	}
+	if !cfg.JetStream || cfg.Stream != "AUDIO_DEVICES" {
+		t.Fatalf("unexpected jetStream/stream: %t/%q", cfg.JetStream, cfg.Stream)
+	}
+	if cfg.PublishTimeout != 2500*time.Millisecond {
+		t.Fatalf("unexpected timeout: %s", cfg.PublishTimeout)
+	}
+}
+
+func TestLoadConfigFromEnv_InvalidValues(t *testing.T) {
+	cases := map[string]string{
+		envNatsSubjectPrefix:  "audio.*",
+		envNatsJetStream:      "maybe",
+		envNatsStream:         "audio.devices",
+		envNatsPublishTimeout: "-1",
+	}
+	for key, value := range cases {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+			if _, err := LoadConfigFromEnv(); err == nil {
+				t.Fatalf("expected error for %s=%q", key, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/cloudevents"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+type MessagePublisher interface {
+	Publish(ctx context.Context, subject, msgID string, body []byte) error
+	Close() error
+}
+
+// Enqueuer publishes requests on per-event, per-device NATS subjects using the shared message-shaping.
+type Enqueuer struct {
+	baseCtx        context.Context
+	publisher      MessagePublisher
+	logger         *slog.Logger
+	subjectPrefix  string
+	publishTimeout time.Duration
+	cloudEvents    cloudevents.Mode
+}
+
+func NewEnqueuerWithContext(baseCtx context.Context, publisher MessagePublisher, logger *slog.Logger, subjectPrefix string, publishTimeout time.Duration, cloudEvents cloudevents.Mode) *Enqueuer {
+	if baseCtx == nil {
+		panic("nil context")
+	}
+	if publisher == nil {
+		panic("nil publisher")
+	}
+	if logger == nil {
+		panic("nil logger")
+	}
+	if strings.TrimSpace(subjectPrefix) == "" {
+		subjectPrefix = defaultSubjectPrefix
+	}
+	if publishTimeout <= 0 {
+		publishTimeout = defaultPublishTimeout
+	}
+
+	return &Enqueuer{
+		baseCtx:        baseCtx,
+		publisher:      publisher,
+		logger:         logger,
+		subjectPrefix:  subjectPrefix,
+		publishTimeout: publishTimeout,
+		cloudEvents:    cloudEvents,
+	}
+}
+
+func (e *Enqueuer) EnqueueRequest(request enqueuer.Request) error {
+	e.logger.Info("Preparing request in NATS enqueuer", "event", request.Event, "fields", request.Fields)
+	payload, err := enqueuer.BuildRequestPayload(request)
+	if err != nil {
+		return fmt.Errorf("marshal nats payload: %w", err)
+	}
+	// There is no binary-mode binding here, so both modes publish structured events.
+	if e.cloudEvents != cloudevents.ModeOff {
+		if payload, err = cloudevents.Structured(payload); err != nil {
+			return fmt.Errorf("marshal nats payload: %w", err)
+		}
+	}
+
+	subject := Subject(e.subjectPrefix, request.Event, request.Fields)
+	msgID := payload.EventID
+	e.logger.Info("publishing event", "method", payload.HTTPRequest, "urlSuffix", payload.URLSuffix, "subject", subject, "msgId", msgID, "updated", payload.UpdateDateUtc)
+
+	ctx, cancel := context.WithTimeout(e.baseCtx, e.publishTimeout)
+	defer cancel()
+	if err := e.publisher.Publish(ctx, subject, msgID, payload.Body); err != nil {
+		return fmt.Errorf("publish event: %w", err)
+	}
+
+	return nil
+}
+
+func (e *Enqueuer) Close() error {
+	return e.publisher.Close()
+}
@@ -0,0 +1,101 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/cloudevents"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+type fakePublisher struct {
+	subject string
+	msgID   string
+	body    []byte
+	err     error
+}
+
+func (p *fakePublisher) Publish(_ context.Context, subject, msgID string, body []byte) error {
+	p.subject = subject
+	p.msgID = msgID
+	p.body = append([]byte(nil), body...)
+	return p.err
+}
+
+func (p *fakePublisher) Close() error {
+	return nil
+}
+
+func testRequest(event contract.EventType, second int) enqueuer.Request {
+	return enqueuer.Request{
+		Timestamp: time.Date(2026, 5, 26, 10, 0, second, 0, time.UTC),
+		Event:     event,
+		Fields: map[string]string{
+			contract.FieldPnpID:    `SWD\MMDEVAPI\{0.0.0.00000000}`,
+			contract.FieldHostName: "host-1",
+		},
+	}
+}
+
+func TestEnqueueRequest_PublishesOnEventAndDeviceSubject(t *testing.T) {
+	publisher := &fakePublisher{}
+	sut := NewEnqueuerWithContext(context.Background(), publisher, slog.Default(), "audio", time.Second, cloudevents.ModeOff)
+
+	if err := sut.EnqueueRequest(testRequest(contract.EventTypeRenderDeviceDiscovered, 0)); err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+
+	if publisher.subject != `audio.RenderDeviceDiscovered.host-1.SWD\MMDEVAPI\{0_0_0_00000000}` {
+		t.Fatalf("unexpected subject: %q", publisher.subject)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(publisher.body, &payload); err != nil {
+		t.Fatalf("payload is not valid JSON: %v", err)
+	}
+	if payload[contract.FieldHTTPRequest] != "POST" {
+		t.Fatalf("expected POST payload, got %#v", payload[contract.FieldHTTPRequest])
+	}
+}
+
+func TestEnqueueRequest_MessageIDDistinguishesChangesOfTheSameDevice(t *testing.T) {
+	publisher := &fakePublisher{}
+	sut := NewEnqueuerWithContext(context.Background(), publisher, slog.Default(), "audio", time.Second, cloudevents.ModeOff)
+
+	ids := make(map[string]bool)
+	for _, request := range []enqueuer.Request{
+		testRequest(contract.EventTypeRenderVolumeChanged, 0),
+		testRequest(contract.EventTypeRenderVolumeChanged, 1),
+		testRequest(contract.EventTypeRenderVolumeChanged, 1),
+	} {
+		if err := sut.EnqueueRequest(request); err != nil {
+			t.Fatalf("EnqueueRequest failed: %v", err)
+		}
+		payload, err := enqueuer.BuildRequestPayload(request)
+		if err != nil {
+			t.Fatalf("BuildRequestPayload failed: %v", err)
+		}
+		if publisher.msgID != payload.EventID {
+			t.Fatalf("expected the event id %q as message id, got %q", payload.EventID, publisher.msgID)
+		}
+		ids[publisher.msgID] = true
+	}
+
+	if len(ids) != 2 {
+		t.Fatalf("expected 2 distinct message ids for 2 distinct changes, got %v", ids)
+	}
+}
+
+func TestEnqueueRequest_PropagatesPublisherError(t *testing.T) {
+	publisher := &fakePublisher{err: errors.New("no responders")}
+	sut := NewEnqueuerWithContext(context.Background(), publisher, slog.Default(), "audio", time.Second, cloudevents.ModeOff)
+
+	if err := sut.EnqueueRequest(testRequest(contract.EventTypeRenderDeviceDiscovered, 0)); err == nil {
+		t.Fatal("expected publish error")
+	}
+}
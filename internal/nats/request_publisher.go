@@ -0,0 +1,125 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// RequestPublisher publishes request payloads with core NATS or, in
+// JetStream mode, waits for the stream to acknowledge each message.
+type RequestPublisher struct {
+	cfg    Config
+	logger *slog.Logger
+	conn   *natsgo.Conn
+	js     jetstream.JetStream
+}
+
+func NewRequestPublisher(ctx context.Context, cfg Config, logger *slog.Logger) (*RequestPublisher, error) {
+	if ctx == nil {
+		panic("nil context")
+	}
+	if logger == nil {
+		panic("nil logger")
+	}
+
+	cfg = cfg.withDefaults()
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	opts := []natsgo.Option{
+		natsgo.Name(cfg.ClientName),
+		natsgo.MaxReconnects(-1),
+		natsgo.DisconnectErrHandler(func(_ *natsgo.Conn, err error) {
+			if err != nil {
+				logger.Warn("NATS disconnected", "err", err)
+			}
+		}),
+		natsgo.ReconnectHandler(func(nc *natsgo.Conn) {
+			logger.Info("NATS reconnected", "url", nc.ConnectedUrlRedacted())
+		}),
+	}
+	if cfg.User != "" {
+		opts = append(opts, natsgo.UserInfo(cfg.User, cfg.Password))
+	}
+
+	conn, err := natsgo.Connect(cfg.URL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("nats connect to %s failed: %w", cfg.URL, err)
+	}
+
+	p := &RequestPublisher{cfg: cfg, logger: logger, conn: conn}
+	if cfg.JetStream {
+		if err := p.initJetStream(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	logger.Info("NATS publisher connected", "url", conn.ConnectedUrlRedacted(), "jetStream", cfg.JetStream, "stream", cfg.Stream)
+	return p, nil
+}
+
+func (p *RequestPublisher) initJetStream(ctx context.Context) error {
+	js, err := jetstream.New(p.conn)
+	if err != nil {
+		return fmt.Errorf("nats jetstream init failed: %w", err)
+	}
+	p.js = js
+
+	if p.cfg.Stream == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.PublishTimeout)
+	defer cancel()
+	if _, err := js.Stream(ctx, p.cfg.Stream); err == nil {
+		return nil
+	} else if !errors.Is(err, jetstream.ErrStreamNotFound) {
+		return fmt.Errorf("nats stream %q lookup failed: %w", p.cfg.Stream, err)
+	}
+
+	subjects := []string{p.cfg.SubjectPrefix + ".>"}
+	if _, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: p.cfg.Stream, Subjects: subjects}); err != nil {
+		return fmt.Errorf("nats stream %q create failed: %w", p.cfg.Stream, err)
+	}
+	p.logger.Info("NATS stream created", "stream", p.cfg.Stream, "subjects", subjects)
+	return nil
+}
+
+// Publish sends body on subject. In JetStream mode msgID is sent as the
+// Nats-Msg-Id header, so a retried message is dropped by the server's
+// duplicate window; in core NATS mode the connection is flushed instead.
+func (p *RequestPublisher) Publish(ctx context.Context, subject, msgID string, body []byte) error {
+	if ctx == nil {
+		panic("nil context")
+	}
+
+	if p.js == nil {
+		if err := p.conn.Publish(subject, body); err != nil {
+			return fmt.Errorf("nats publish failed: %w", err)
+		}
+		if err := p.conn.FlushWithContext(ctx); err != nil {
+			return fmt.Errorf("nats flush failed: %w", err)
+		}
+		return nil
+	}
+
+	ack, err := p.js.Publish(ctx, subject, body, jetstream.WithMsgID(msgID))
+	if err != nil {
+		return fmt.Errorf("nats jetstream publish failed: %w", err)
+	}
+	if ack.Duplicate {
+		p.logger.Info("NATS JetStream dropped duplicate message", "stream", ack.Stream, "msgId", msgID)
+	}
+	return nil
+}
+
+func (p *RequestPublisher) Close() error {
+	return p.conn.Drain()
+}
@@ -0,0 +1,38 @@
+package nats
+
+import (
+	"strings"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+)
+
+const unknownSubjectToken = "unknown"
+
+// Subject builds "<prefix>.<event>.<host>.<pnpId>", so consumers can
+// subscribe to e.g. "win-sound-scanner.RenderVolumeChanged.>" or
+// "win-sound-scanner.*.HOST-1.>".
+func Subject(prefix string, event contract.EventType, fields map[string]string) string {
+	return strings.Join([]string{
+		prefix,
+		event.String(),
+		subjectToken(fields[contract.FieldHostName]),
+		subjectToken(fields[contract.FieldPnpID]),
+	}, ".")
+}
+
+// subjectToken keeps a value within one subject token: PnP IDs contain '.'
+// (e.g. "{0.0.0.00000000}") and must not add levels or wildcards.
+func subjectToken(v string) string {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return unknownSubjectToken
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '\r', '\n':
+			return '_'
+		default:
+			return r
+		}
+	}, v)
+}
@@ -0,0 +1,114 @@
+package outbox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/envconfig"
+	"github.com/collect-sound-devices/win-sound-scanner-go/pkg/appinfo"
+)
+
+const (
+	defaultDirName           = "outbox"
+	defaultRetryInitialDelay = 1 * time.Second
+	defaultRetryMaxDelay     = 30 * time.Second
+	defaultCompactThreshold  = 1024
+	defaultBestEffortRetries = 10
+	envOutboxEnabled         = "WIN_SOUND_OUTBOX_ENABLED"
+	envOutboxDir             = "WIN_SOUND_OUTBOX_DIR"
+	envOutboxRetryInitial    = "WIN_SOUND_OUTBOX_RETRY_INITIAL_DELAY_MS"
+	envOutboxRetryMax        = "WIN_SOUND_OUTBOX_RETRY_MAX_DELAY_MS"
+	envOutboxBestEffortMax   = "WIN_SOUND_OUTBOX_BEST_EFFORT_MAX_ATTEMPTS"
+)
+
+// Config defines where the outbox keeps its write-ahead file and how it retries delivery.
+type Config struct {
+	Enabled           bool
+	Dir               string
+	RetryInitialDelay time.Duration
+	RetryMaxDelay     time.Duration
+	// CompactThreshold is the number of acknowledged records after which
+	// the write-ahead file is rewritten with the pending records only.
+	CompactThreshold int
+	// BestEffort outboxes discard a request after BestEffortMaxAttempts
+	// failed deliveries; the others retry until it is delivered.
+	BestEffort            bool
+	BestEffortMaxAttempts int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Enabled:           true,
+		RetryInitialDelay: defaultRetryInitialDelay,
+		RetryMaxDelay:     defaultRetryMaxDelay,
+		CompactThreshold:  defaultCompactThreshold,
+
+		BestEffortMaxAttempts: defaultBestEffortRetries,
+	}
+}
+
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	if c.RetryInitialDelay <= 0 {
+		c.RetryInitialDelay = d.RetryInitialDelay
+	}
+	if c.RetryMaxDelay <= 0 {
+		c.RetryMaxDelay = d.RetryMaxDelay
+	}
+	if c.RetryMaxDelay < c.RetryInitialDelay {
+		c.RetryMaxDelay = c.RetryInitialDelay
+	}
+	if c.CompactThreshold <= 0 {
+		c.CompactThreshold = d.CompactThreshold
+	}
+	if c.BestEffortMaxAttempts <= 0 {
+		c.BestEffortMaxAttempts = d.BestEffortMaxAttempts
+	}
+	return c
+}
+
+// LoadConfigFromEnv loads outbox configuration from environment variables.
+// The directory defaults to %ProgramData%\WinSoundScanner\outbox.
+func LoadConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+
+	if v := strings.TrimSpace(os.Getenv(envOutboxEnabled)); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s %q: %w", envOutboxEnabled, v, err)
+		}
+		cfg.Enabled = enabled
+	}
+
+	if v := strings.TrimSpace(os.Getenv(envOutboxDir)); v != "" {
+		cfg.Dir = v
+	} else if cfg.Enabled {
+		dataDir, err := appinfo.DataDir()
+		if err != nil {
+			return Config{}, fmt.Errorf("resolve outbox directory (set %s): %w", envOutboxDir, err)
+		}
+		cfg.Dir = filepath.Join(dataDir, defaultDirName)
+	}
+
+	initialDelay, err := envconfig.Millis(envOutboxRetryInitial, cfg.RetryInitialDelay)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.RetryInitialDelay = initialDelay
+
+	maxDelay, err := envconfig.Millis(envOutboxRetryMax, cfg.RetryMaxDelay)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.RetryMaxDelay = maxDelay
+
+	if cfg.BestEffortMaxAttempts, err = envconfig.NonNegativeInt(envOutboxBestEffortMax, cfg.BestEffortMaxAttempts); err != nil {
+		return Config{}, err
+	}
+
+	return cfg.withDefaults(), nil
+}
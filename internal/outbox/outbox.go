@@ -0,0 +1,241 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+// drainPollInterval is how often Drain checks for pending requests.
+const drainPollInterval = 20 * time.Millisecond
+
+// ErrClosed is returned by EnqueueRequest after Close.
+var ErrClosed = errors.New("outbox is closed")
+
+// Outbox persists every request to a write-ahead file before returning and
+// delivers it through the wrapped transport from a background worker.
+// A request is removed from the file only after the transport accepted it,
+// so pending requests survive broker outages and process restarts.
+type Outbox struct {
+	cfg    Config
+	next   enqueuer.EnqueueRequest
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	wal     *wal
+	pending []entry
+	lastSeq uint64
+	acked   int
+	closed  bool
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New opens the write-ahead file, schedules replay of any pending requests
+// in their original order and starts the delivery worker.
+func New(cfg Config, next enqueuer.EnqueueRequest, logger *slog.Logger) (*Outbox, error) {
+	if next == nil {
+		panic("nil enqueuer")
+	}
+	if logger == nil {
+		panic("nil logger")
+	}
+
+	cfg = cfg.withDefaults()
+	if cfg.Dir == "" {
+		return nil, errors.New("outbox directory is empty")
+	}
+
+	w, pending, lastSeq, err := openWAL(cfg.Dir, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &Outbox{
+		cfg:     cfg,
+		next:    next,
+		logger:  logger,
+		wal:     w,
+		pending: pending,
+		lastSeq: lastSeq,
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	logger.Info("Outbox opened", "path", w.path, "pending", len(pending))
+	go o.run()
+	o.signal()
+	return o, nil
+}
+
+func (o *Outbox) EnqueueRequest(request enqueuer.Request) error {
+	o.mu.Lock()
+	if o.closed {
+		o.mu.Unlock()
+		return ErrClosed
+	}
+
+	e := entry{seq: o.lastSeq + 1, request: request}
+	if err := o.wal.appendPut(e); err != nil {
+		o.mu.Unlock()
+		return fmt.Errorf("persist request: %w", err)
+	}
+	o.lastSeq = e.seq
+	o.pending = append(o.pending, e)
+	o.mu.Unlock()
+
+	o.signal()
+	return nil
+}
+
+// Pending returns the number of requests not yet accepted by the transport.
+func (o *Outbox) Pending() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.pending)
+}
+
+// Drain waits until the transport accepted every pending request or ctx is
+// done and returns the number of requests still pending. Requests enqueued
+// meanwhile are waited for as well.
+func (o *Outbox) Drain(ctx context.Context) int {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for {
+		pending := o.Pending()
+		if pending == 0 {
+			return 0
+		}
+		select {
+		case <-ctx.Done():
+			return pending
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close stops the delivery worker and closes the write-ahead file.
+// Undelivered requests stay on disk and are replayed by the next New.
+func (o *Outbox) Close() error {
+	o.mu.Lock()
+	if o.closed {
+		o.mu.Unlock()
+		return nil
+	}
+	o.closed = true
+	o.mu.Unlock()
+
+	close(o.stop)
+	<-o.done
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.pending) > 0 {
+		o.logger.Info("Outbox closed with pending requests", "pending", len(o.pending))
+	}
+	return o.wal.close()
+}
+
+func (o *Outbox) signal() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (o *Outbox) run() {
+	defer close(o.done)
+
+	delay := o.cfg.RetryInitialDelay
+	attempts := 0
+	for {
+		e, ok := o.head()
+		if !ok {
+			select {
+			case <-o.stop:
+				return
+			case <-o.wake:
+				continue
+			}
+		}
+
+		err := o.next.EnqueueRequest(e.request)
+		attempts++
+		if errors.Is(err, enqueuer.ErrRejected) {
+			o.logger.Error("Outbox request rejected; discarding it", "seq", e.seq, "event", e.request.Event, "err", err)
+			err = nil
+		}
+		if err != nil && o.cfg.BestEffort && attempts >= o.cfg.BestEffortMaxAttempts {
+			o.logger.Error("Best-effort outbox delivery failed; discarding the request", "seq", e.seq, "event", e.request.Event, "attempts", attempts, "err", err)
+			err = nil
+		}
+		if err != nil {
+			o.logger.Warn("Outbox delivery failed; retrying", "seq", e.seq, "event", e.request.Event, "retryDelay", delay, "err", err)
+			if !o.sleep(delay) {
+				return
+			}
+			delay = min(delay*2, o.cfg.RetryMaxDelay)
+			continue
+		}
+
+		delay = o.cfg.RetryInitialDelay
+		attempts = 0
+		if err := o.ack(e.seq); err != nil {
+			o.logger.Error("Outbox acknowledgement failed", "seq", e.seq, "err", err)
+		}
+	}
+}
+
+func (o *Outbox) head() (entry, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.pending) == 0 {
+		return entry{}, false
+	}
+	return o.pending[0], true
+}
+
+func (o *Outbox) ack(seq uint64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if len(o.pending) > 0 && o.pending[0].seq == seq {
+		o.pending = o.pending[1:]
+	}
+
+	if len(o.pending) == 0 {
+		o.acked = 0
+		return o.wal.reset()
+	}
+
+	if err := o.wal.appendAck(seq); err != nil {
+		return err
+	}
+	o.acked++
+	if o.acked >= o.cfg.CompactThreshold {
+		if err := o.wal.compact(o.pending); err != nil {
+			return err
+		}
+		o.acked = 0
+	}
+	return nil
+}
+
+func (o *Outbox) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-o.stop:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
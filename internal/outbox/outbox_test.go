@@ -0,0 +1,317 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+type recordingEnqueuer struct {
+	mu       sync.Mutex
+	err      error
+	received []enqueuer.Request
+}
+
+func (r *recordingEnqueuer) EnqueueRequest(request enqueuer.Request) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.received = append(r.received, request)
+	return nil
+}
+
+func (r *recordingEnqueuer) setErr(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+}
+
+func (r *recordingEnqueuer) pnpIDs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, 0, len(r.received))
+	for _, request := range r.received {
+		ids = append(ids, request.Fields[contract.FieldPnpID])
+	}
+	return ids
+}
+
+func testConfig(dir string) Config {
+	return Config{
+		Enabled:           true,
+		Dir:               dir,
+		RetryInitialDelay: 5 * time.Millisecond,
+		RetryMaxDelay:     10 * time.Millisecond,
+	}
+}
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func testRequest(pnpID string) enqueuer.Request {
+	return enqueuer.Request{
+		Timestamp:   time.Date(2026, 5, 26, 10, 0, 0, 0, time.UTC),
+		Event:       contract.EventTypeRenderVolumeChanged,
+		Fields:      map[string]string{contract.FieldPnpID: pnpID, contract.FieldHostName: "host-1"},
+		TraceParent: testTraceParent,
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestOutbox_DeliversInOrderAndEmptiesFile(t *testing.T) {
+	dir := t.TempDir()
+	next := &recordingEnqueuer{}
+	sut, err := New(testConfig(dir), next, slog.Default())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	for _, id := range []string{"pnp-1", "pnp-2", "pnp-3"} {
+		if err := sut.EnqueueRequest(testRequest(id)); err != nil {
+			t.Fatalf("EnqueueRequest failed: %v", err)
+		}
+	}
+	waitFor(t, func() bool { return sut.Pending() == 0 })
+	if err := sut.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	ids := next.pnpIDs()
+	if len(ids) != 3 || ids[0] != "pnp-1" || ids[1] != "pnp-2" || ids[2] != "pnp-3" {
+		t.Fatalf("unexpected delivery order: %#v", ids)
+	}
+	info, err := os.Stat(filepath.Join(dir, walFileName))
+	if err != nil {
+		t.Fatalf("stat outbox file: %v", err)
+	}
+	if info.Size() != 0 {
+		t.Fatalf("expected empty outbox file, got %d bytes", info.Size())
+	}
+}
+
+func TestOutbox_ReplaysPendingRequestsAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	failing := &recordingEnqueuer{err: errors.New("broker down")}
+	first, err := New(testConfig(dir), failing, slog.Default())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	for _, id := range []string{"pnp-1", "pnp-2"} {
+		if err := first.EnqueueRequest(testRequest(id)); err != nil {
+			t.Fatalf("EnqueueRequest failed: %v", err)
+		}
+	}
+	if err := first.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	next := &recordingEnqueuer{}
+	second, err := New(testConfig(dir), next, slog.Default())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer func() { _ = second.Close() }()
+	waitFor(t, func() bool { return second.Pending() == 0 })
+
+	ids := next.pnpIDs()
+	if len(ids) != 2 || ids[0] != "pnp-1" || ids[1] != "pnp-2" {
+		t.Fatalf("unexpected replay order: %#v", ids)
+	}
+	if got := next.received[0].TraceParent; got != testTraceParent {
+		t.Fatalf("expected the replay to keep the traceparent, got %q", got)
+	}
+}
+
+func TestOutbox_RetriesUntilTransportRecovers(t *testing.T) {
+	next := &recordingEnqueuer{err: errors.New("broker down")}
+	sut, err := New(testConfig(t.TempDir()), next, slog.Default())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer func() { _ = sut.Close() }()
+
+	if err := sut.EnqueueRequest(testRequest("pnp-1")); err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if sut.Pending() != 1 {
+		t.Fatalf("expected request to stay pending, got %d", sut.Pending())
+	}
+
+	next.setErr(nil)
+	waitFor(t, func() bool { return sut.Pending() == 0 })
+}
+
+func TestOutbox_DrainReportsRequestsLeftPending(t *testing.T) {
+	next := &recordingEnqueuer{err: errors.New("broker down")}
+	sut, err := New(testConfig(t.TempDir()), next, slog.Default())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer func() { _ = sut.Close() }()
+
+	for _, pnpID := range []string{"pnp-1", "pnp-2"} {
+		if err := sut.EnqueueRequest(testRequest(pnpID)); err != nil {
+			t.Fatalf("EnqueueRequest failed: %v", err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if pending := sut.Drain(ctx); pending != 2 {
+		t.Fatalf("expected 2 requests left pending, got %d", pending)
+	}
+
+	next.setErr(nil)
+	if pending := sut.Drain(context.Background()); pending != 0 {
+		t.Fatalf("expected outbox to drain, got %d pending", pending)
+	}
+	if got := next.pnpIDs(); len(got) != 2 {
+		t.Fatalf("expected both requests delivered, got %v", got)
+	}
+}
+
+func TestOpenWAL_IgnoresTornTrailingLineAndAckedRecords(t *testing.T) {
+	dir := t.TempDir()
+	content := `{"op":"put","seq":1,"request":{"timestamp":"2026-05-26T10:00:00Z","event":5,"fields":{"pnpId":"pnp-1"}}}
+{"op":"put","seq":2,"request":{"timestamp":"2026-05-26T10:00:01Z","event":5,"fields":{"pnpId":"pnp-2"}}}
+{"op":"ack","seq":1}
+{"op":"put","seq":3,"requ`
+	if err := os.WriteFile(filepath.Join(dir, walFileName), []byte(content), 0o644); err != nil {
+		t.Fatalf("write outbox file: %v", err)
+	}
+
+	w, pending, lastSeq, err := openWAL(dir, slog.Default())
+	if err != nil {
+		t.Fatalf("openWAL failed: %v", err)
+	}
+	defer func() { _ = w.close() }()
+
+	if len(pending) != 1 || pending[0].seq != 2 || pending[0].request.Fields[contract.FieldPnpID] != "pnp-2" {
+		t.Fatalf("unexpected pending entries: %#v", pending)
+	}
+	if lastSeq != 2 {
+		t.Fatalf("expected last sequence 2, got %d", lastSeq)
+	}
+}
+
+func TestOutbox_DiscardsRejectedRequests(t *testing.T) {
+	next := &recordingEnqueuer{err: fmt.Errorf("status 400: %w", enqueuer.ErrRejected)}
+	sut, err := New(testConfig(t.TempDir()), next, slog.Default())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer func() { _ = sut.Close() }()
+
+	if err := sut.EnqueueRequest(testRequest("pnp-1")); err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+	waitFor(t, func() bool { return sut.Pending() == 0 })
+}
+
+func TestReadRecords_ReportsCorruptLines(t *testing.T) {
+	first := `{"op":"put","seq":1,"request":{"timestamp":"2026-05-26T10:00:00Z","event":5,"fields":{"pnpId":"pnp-1"}}}` + "\n"
+	corrupt := "{\"op\":\"put\",\"seq\":2,garbage}\n"
+	empty := `{"op":"put","seq":3}` + "\n"
+	last := `{"op":"put","seq":4,"request":{"timestamp":"2026-05-26T10:00:01Z","event":5,"fields":{"pnpId":"pnp-4"}}}` + "\n"
+
+	pending, _, validSize, skipped, err := readRecords(strings.NewReader(first + corrupt + empty + last))
+	if err != nil {
+		t.Fatalf("readRecords failed: %v", err)
+	}
+	if len(pending) != 2 {
+		t.Fatalf("expected two readable entries, got %#v", pending)
+	}
+	want := []int64{int64(len(first)), int64(len(first + corrupt))}
+	if len(skipped) != 2 || skipped[0] != want[0] || skipped[1] != want[1] {
+		t.Fatalf("expected skipped offsets %v, got %v", want, skipped)
+	}
+	if validSize != int64(len(first+corrupt+empty+last)) {
+		t.Fatalf("unexpected valid size %d", validSize)
+	}
+}
+
+func TestOutbox_BestEffortDiscardsAfterMaxAttempts(t *testing.T) {
+	next := &countingEnqueuer{err: errors.New("broker down")}
+	cfg := testConfig(t.TempDir())
+	cfg.BestEffort = true
+	cfg.BestEffortMaxAttempts = 3
+	sut, err := New(cfg, next, slog.Default())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer func() { _ = sut.Close() }()
+
+	if err := sut.EnqueueRequest(testRequest("pnp-1")); err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+	waitFor(t, func() bool { return sut.Pending() == 0 })
+	if got := next.count(); got != 3 {
+		t.Fatalf("expected 3 delivery attempts, got %d", got)
+	}
+}
+
+func TestMoveWAL_MovesRootFileIntoSinkDirectory(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, walFileName), []byte("{}\n"), 0o644); err != nil {
+		t.Fatalf("write outbox file: %v", err)
+	}
+	sinkDir := filepath.Join(root, "rabbitmq")
+
+	moved, err := MoveWAL(root, sinkDir)
+	if err != nil || !moved {
+		t.Fatalf("expected the file to be moved, got %v, %v", moved, err)
+	}
+	if _, err := os.Stat(filepath.Join(sinkDir, walFileName)); err != nil {
+		t.Fatalf("expected the file in the sink directory: %v", err)
+	}
+	if moved, err := MoveWAL(root, sinkDir); err != nil || moved {
+		t.Fatalf("expected nothing left to move, got %v, %v", moved, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, walFileName), []byte("{}\n"), 0o644); err != nil {
+		t.Fatalf("write outbox file: %v", err)
+	}
+	if _, err := MoveWAL(root, sinkDir); err == nil {
+		t.Fatal("expected an error when the sink directory already has a file")
+	}
+}
+
+type countingEnqueuer struct {
+	mu       sync.Mutex
+	err      error
+	attempts int
+}
+
+func (c *countingEnqueuer) EnqueueRequest(enqueuer.Request) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.attempts++
+	return c.err
+}
+
+func (c *countingEnqueuer) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.attempts
+}
@@ -0,0 +1,262 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+const (
+	walFileName = "outbox.wal"
+	opPut       = "put"
+	opAck       = "ack"
+)
+
+// record is one line of the write-ahead file.
+type record struct {
+	Op      string         `json:"op"`
+	Seq     uint64         `json:"seq"`
+	Request *storedRequest `json:"request,omitempty"`
+}
+
+type storedRequest struct {
+	Timestamp time.Time          `json:"timestamp"`
+	Event     contract.EventType `json:"event"`
+	Fields    map[string]string  `json:"fields,omitempty"`
+
+	// TraceParent lets a replay after a restart continue the trace.
+	TraceParent string `json:"traceParent,omitempty"`
+}
+
+type entry struct {
+	seq     uint64
+	request enqueuer.Request
+}
+
+// wal is an append-only JSON Lines file of put and ack records.
+// It is not safe for concurrent use; the Outbox serialises access.
+type wal struct {
+	path string
+	file *os.File
+}
+
+// MoveWAL moves the write-ahead file from the directory from into the
+// directory to, unless to already has one, and reports whether it moved it.
+// Before every sink got its own subdirectory, a single sink kept its file
+// directly in the outbox directory.
+func MoveWAL(from, to string) (bool, error) {
+	source := filepath.Join(from, walFileName)
+	if _, err := os.Stat(source); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("stat outbox file: %w", err)
+	}
+	target := filepath.Join(to, walFileName)
+	if _, err := os.Stat(target); err == nil {
+		return false, fmt.Errorf("outbox file %s is left in place, %s already exists", source, target)
+	}
+	if err := os.MkdirAll(to, 0o755); err != nil {
+		return false, fmt.Errorf("create outbox directory: %w", err)
+	}
+	if err := os.Rename(source, target); err != nil {
+		return false, fmt.Errorf("move outbox file: %w", err)
+	}
+	return true, nil
+}
+
+// openWAL opens (or creates) the write-ahead file in dir and returns the
+// entries that were put but never acknowledged, in sequence order.
+// A torn trailing line left by a crash is truncated away. Complete lines
+// that cannot be read are skipped with a warning, since the next compaction
+// removes them for good.
+func openWAL(dir string, logger *slog.Logger) (*wal, []entry, uint64, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, nil, 0, fmt.Errorf("create outbox directory: %w", err)
+	}
+
+	path := filepath.Join(dir, walFileName)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("open outbox file: %w", err)
+	}
+
+	pending, lastSeq, validSize, skipped, err := readRecords(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, 0, err
+	}
+	for _, offset := range skipped {
+		logger.Warn("Outbox record is corrupt; skipping it", "path", path, "offset", offset)
+	}
+	if len(skipped) > 0 {
+		logger.Warn("Outbox file has corrupt records", "path", path, "skipped", len(skipped))
+	}
+	if err := file.Truncate(validSize); err != nil {
+		_ = file.Close()
+		return nil, nil, 0, fmt.Errorf("truncate outbox file: %w", err)
+	}
+	if _, err := file.Seek(validSize, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, nil, 0, fmt.Errorf("seek outbox file: %w", err)
+	}
+
+	return &wal{path: path, file: file}, pending, lastSeq, nil
+}
+
+// readRecords also returns the offsets of the complete lines it skipped.
+func readRecords(r io.Reader) ([]entry, uint64, int64, []int64, error) {
+	var (
+		order     []uint64
+		puts      = make(map[uint64]enqueuer.Request)
+		lastSeq   uint64
+		validSize int64
+		skipped   []int64
+	)
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// Anything after the last newline is an incomplete write.
+			break
+		}
+		if err != nil {
+			return nil, 0, 0, nil, fmt.Errorf("read outbox file: %w", err)
+		}
+		offset := validSize
+		validSize += int64(len(line))
+
+		var rec record
+		if err := json.Unmarshal(bytes.TrimSpace(line), &rec); err != nil {
+			skipped = append(skipped, offset)
+			continue
+		}
+		if rec.Seq > lastSeq {
+			lastSeq = rec.Seq
+		}
+		switch rec.Op {
+		case opPut:
+			if rec.Request == nil {
+				skipped = append(skipped, offset)
+				continue
+			}
+			order = append(order, rec.Seq)
+			puts[rec.Seq] = enqueuer.Request{
+				Timestamp:   rec.Request.Timestamp,
+				Event:       rec.Request.Event,
+				Fields:      rec.Request.Fields,
+				TraceParent: rec.Request.TraceParent,
+			}
+		case opAck:
+			delete(puts, rec.Seq)
+		}
+	}
+
+	pending := make([]entry, 0, len(puts))
+	for _, seq := range order {
+		if request, ok := puts[seq]; ok {
+			pending = append(pending, entry{seq: seq, request: request})
+		}
+	}
+	return pending, lastSeq, validSize, skipped, nil
+}
+
+func (w *wal) appendPut(e entry) error {
+	return w.append(record{
+		Op:  opPut,
+		Seq: e.seq,
+		Request: &storedRequest{
+			Timestamp:   e.request.Timestamp,
+			Event:       e.request.Event,
+			Fields:      e.request.Fields,
+			TraceParent: e.request.TraceParent,
+		},
+	})
+}
+
+func (w *wal) appendAck(seq uint64) error {
+	return w.append(record{Op: opAck, Seq: seq})
+}
+
+func (w *wal) append(rec record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal outbox record: %w", err)
+	}
+	line = append(line, '\n')
+	if _, err := w.file.Write(line); err != nil {
+		return fmt.Errorf("write outbox record: %w", err)
+	}
+	if err := w.file.Sync(); err != nil {
+		return fmt.Errorf("sync outbox file: %w", err)
+	}
+	return nil
+}
+
+// reset empties the file once every record has been acknowledged.
+func (w *wal) reset() error {
+	if err := w.file.Truncate(0); err != nil {
+		return fmt.Errorf("truncate outbox file: %w", err)
+	}
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("seek outbox file: %w", err)
+	}
+	return w.file.Sync()
+}
+
+// compact rewrites the file with the pending entries only and swaps it in
+// with a rename, so a crash leaves either the old or the new file intact.
+func (w *wal) compact(pending []entry) error {
+	tmpPath := w.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("create compacted outbox file: %w", err)
+	}
+	next := &wal{path: tmpPath, file: tmp}
+	for _, e := range pending {
+		if err := next.appendPut(e); err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmpPath)
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("close compacted outbox file: %w", err)
+	}
+
+	// Windows cannot rename over an open file.
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("close outbox file: %w", err)
+	}
+	renameErr := os.Rename(tmpPath, w.path)
+
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("reopen outbox file: %w", err)
+	}
+	w.file = file
+	if renameErr != nil {
+		return fmt.Errorf("replace outbox file: %w", renameErr)
+	}
+	return nil
+}
+
+func (w *wal) close() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
@@ -0,0 +1,145 @@
+package pipeline
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+type coalesceKey struct {
+	deviceKey string
+	event     contract.EventType
+}
+
+type pendingVolume struct {
+	request    enqueuer.Request
+	firstSeen  time.Time
+	merged     int
+	generation uint64
+	timer      *time.Timer
+}
+
+// Coalescer merges bursts of volume-change events per device and event type.
+// Only the last volume of a burst is forwarded, once no update arrived for
+// SettleWindow or MaxDelay after the first update of the burst has passed.
+// Any other event flushes pending volume changes of the same device first,
+// so the per-device order seen downstream is preserved.
+type Coalescer struct {
+	cfg    CoalesceConfig
+	next   enqueuer.EnqueueRequest
+	logger *slog.Logger
+
+	mu         sync.Mutex
+	pending    map[coalesceKey]*pendingVolume
+	generation uint64
+	closed     bool
+}
+
+func NewCoalescer(cfg CoalesceConfig, next enqueuer.EnqueueRequest, logger *slog.Logger) *Coalescer {
+	if next == nil {
+		panic("nil enqueuer")
+	}
+	if logger == nil {
+		panic("nil logger")
+	}
+
+	return &Coalescer{
+		cfg:     cfg,
+		next:    next,
+		logger:  logger,
+		pending: make(map[coalesceKey]*pendingVolume),
+	}
+}
+
+func (c *Coalescer) EnqueueRequest(request enqueuer.Request) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	deviceKey := enqueuer.DeviceKey(request.Fields)
+	if c.closed || c.cfg.SettleWindow <= 0 || !isVolumeEvent(request.Event) {
+		c.flushDeviceLocked(deviceKey)
+		return c.next.EnqueueRequest(request)
+	}
+
+	key := coalesceKey{deviceKey: deviceKey, event: request.Event}
+	now := time.Now()
+	p, ok := c.pending[key]
+	if !ok {
+		p = &pendingVolume{firstSeen: now}
+		c.pending[key] = p
+	} else {
+		p.timer.Stop()
+		p.merged++
+	}
+	p.request = request
+
+	delay := c.cfg.SettleWindow
+	if c.cfg.MaxDelay > 0 {
+		if remaining := p.firstSeen.Add(c.cfg.MaxDelay).Sub(now); remaining < delay {
+			delay = max(remaining, 0)
+		}
+	}
+
+	c.generation++
+	generation := c.generation
+	p.generation = generation
+	p.timer = time.AfterFunc(delay, func() {
+		c.fire(key, generation)
+	})
+	return nil
+}
+
+// Close forwards every pending volume change immediately; later requests
+// pass through without coalescing.
+func (c *Coalescer) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	for key := range c.pending {
+		c.flushLocked(key)
+	}
+	return nil
+}
+
+func (c *Coalescer) fire(key coalesceKey, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if p, ok := c.pending[key]; ok && p.generation == generation {
+		c.flushLocked(key)
+	}
+}
+
+func (c *Coalescer) flushDeviceLocked(deviceKey string) {
+	for key := range c.pending {
+		if key.deviceKey == deviceKey {
+			c.flushLocked(key)
+		}
+	}
+}
+
+func (c *Coalescer) flushLocked(key coalesceKey) {
+	p := c.pending[key]
+	delete(c.pending, key)
+	p.timer.Stop()
+
+	if p.merged > 0 {
+		c.logger.Info("Coalesced volume changes", "event", key.event, "key", key.deviceKey, "merged", p.merged)
+	}
+	if err := c.next.EnqueueRequest(p.request); err != nil {
+		c.logger.Error("Enqueue failed", "event", p.request.Event, "err", err)
+	}
+}
+
+func isVolumeEvent(event contract.EventType) bool {
+	switch event {
+	case contract.EventTypeRenderVolumeChanged, contract.EventTypeCaptureVolumeChanged:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,108 @@
+package pipeline
+
+import (
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+type collectingEnqueuer struct {
+	mu       sync.Mutex
+	received []enqueuer.Request
+}
+
+func (e *collectingEnqueuer) EnqueueRequest(request enqueuer.Request) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.received = append(e.received, request)
+	return nil
+}
+
+func (e *collectingEnqueuer) snapshot() []enqueuer.Request {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]enqueuer.Request(nil), e.received...)
+}
+
+func deviceRequest(event contract.EventType, pnpID, volume string) enqueuer.Request {
+	return enqueuer.Request{
+		Event: event,
+		Fields: map[string]string{
+			contract.FieldHostName: "host-1",
+			contract.FieldPnpID:    pnpID,
+			contract.FieldVolume:   volume,
+		},
+	}
+}
+
+func TestCoalescer_EmitsOnlyFinalVolumeAfterSettleWindow(t *testing.T) {
+	next := &collectingEnqueuer{}
+	sut := NewCoalescer(CoalesceConfig{SettleWindow: 30 * time.Millisecond}, next, slog.Default())
+
+	for _, volume := range []string{"10", "20", "30"} {
+		_ = sut.EnqueueRequest(deviceRequest(contract.EventTypeRenderVolumeChanged, "pnp-1", volume))
+	}
+	_ = sut.EnqueueRequest(deviceRequest(contract.EventTypeRenderVolumeChanged, "pnp-2", "50"))
+	if got := len(next.snapshot()); got != 0 {
+		t.Fatalf("expected nothing forwarded inside the settle window, got %d", got)
+	}
+
+	time.Sleep(80 * time.Millisecond)
+	received := next.snapshot()
+	if len(received) != 2 {
+		t.Fatalf("expected one request per device, got %d", len(received))
+	}
+	volumes := map[string]string{}
+	for _, request := range received {
+		volumes[request.Fields[contract.FieldPnpID]] = request.Fields[contract.FieldVolume]
+	}
+	if volumes["pnp-1"] != "30" || volumes["pnp-2"] != "50" {
+		t.Fatalf("unexpected forwarded volumes: %#v", volumes)
+	}
+}
+
+func TestCoalescer_MaxDelayForcesPeriodicUpdates(t *testing.T) {
+	next := &collectingEnqueuer{}
+	sut := NewCoalescer(CoalesceConfig{SettleWindow: 40 * time.Millisecond, MaxDelay: 60 * time.Millisecond}, next, slog.Default())
+
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		_ = sut.EnqueueRequest(deviceRequest(contract.EventTypeCaptureVolumeChanged, "pnp-1", "1"))
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := len(next.snapshot()); got < 2 {
+		t.Fatalf("expected periodic updates during a long drag, got %d", got)
+	}
+}
+
+func TestCoalescer_OtherEventFlushesPendingVolumeFirst(t *testing.T) {
+	next := &collectingEnqueuer{}
+	sut := NewCoalescer(CoalesceConfig{SettleWindow: time.Hour}, next, slog.Default())
+
+	_ = sut.EnqueueRequest(deviceRequest(contract.EventTypeRenderVolumeChanged, "pnp-1", "42"))
+	_ = sut.EnqueueRequest(deviceRequest(contract.EventTypeRenderDeviceDiscovered, "pnp-1", ""))
+
+	received := next.snapshot()
+	if len(received) != 2 {
+		t.Fatalf("expected two forwarded requests, got %d", len(received))
+	}
+	if received[0].Event != contract.EventTypeRenderVolumeChanged || received[1].Event != contract.EventTypeRenderDeviceDiscovered {
+		t.Fatalf("unexpected order: %v, %v", received[0].Event, received[1].Event)
+	}
+}
+
+func TestCoalescer_CloseFlushesPending(t *testing.T) {
+	next := &collectingEnqueuer{}
+	sut := NewCoalescer(CoalesceConfig{SettleWindow: time.Hour}, next, slog.Default())
+
+	_ = sut.EnqueueRequest(deviceRequest(contract.EventTypeRenderVolumeChanged, "pnp-1", "42"))
+	_ = sut.Close()
+
+	if received := next.snapshot(); len(received) != 1 || received[0].Fields[contract.FieldVolume] != "42" {
+		t.Fatalf("unexpected forwarded requests: %#v", received)
+	}
+}
@@ -0,0 +1,128 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/envconfig"
+)
+
+// OverflowPolicy decides what happens when a request arrives at a full queue.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock makes the caller wait for free capacity.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropOldest evicts the oldest queued request to make room.
+	OverflowDropOldest OverflowPolicy = "drop-oldest"
+	// OverflowDropNewest rejects the incoming request.
+	OverflowDropNewest OverflowPolicy = "drop-newest"
+)
+
+const (
+	defaultQueueCapacity      = 256
+	defaultQueueOverflow      = OverflowDropOldest
+	defaultVolumeSettleWindow = 250 * time.Millisecond
+	defaultVolumeMaxDelay     = 1 * time.Second
+	envQueueCapacity          = "WIN_SOUND_QUEUE_CAPACITY"
+	envQueueOverflow          = "WIN_SOUND_QUEUE_OVERFLOW"
+	envVolumeSettleWindow     = "WIN_SOUND_VOLUME_SETTLE_MS"
+	envVolumeMaxDelay         = "WIN_SOUND_VOLUME_MAX_DELAY_MS"
+)
+
+// QueueConfig defines the bounded queue between device callbacks and the enqueuer.
+type QueueConfig struct {
+	Capacity int
+	Overflow OverflowPolicy
+}
+
+func DefaultQueueConfig() QueueConfig {
+	return QueueConfig{
+		Capacity: defaultQueueCapacity,
+		Overflow: defaultQueueOverflow,
+	}
+}
+
+func (c QueueConfig) withDefaults() QueueConfig {
+	d := DefaultQueueConfig()
+	if c.Capacity <= 0 {
+		c.Capacity = d.Capacity
+	}
+	if strings.TrimSpace(string(c.Overflow)) == "" {
+		c.Overflow = d.Overflow
+	}
+	return c
+}
+
+// LoadQueueConfigFromEnv loads queue configuration from environment variables.
+func LoadQueueConfigFromEnv() (QueueConfig, error) {
+	cfg := DefaultQueueConfig()
+
+	if v := strings.TrimSpace(os.Getenv(envQueueCapacity)); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return QueueConfig{}, fmt.Errorf("invalid %s %q: %w", envQueueCapacity, v, err)
+		}
+		if n <= 0 {
+			return QueueConfig{}, fmt.Errorf("%s must be positive %q", envQueueCapacity, v)
+		}
+		cfg.Capacity = n
+	}
+
+	if v := strings.ToLower(strings.TrimSpace(os.Getenv(envQueueOverflow))); v != "" {
+		policy, err := ParseOverflowPolicy(v)
+		if err != nil {
+			return QueueConfig{}, fmt.Errorf("invalid %s: %w", envQueueOverflow, err)
+		}
+		cfg.Overflow = policy
+	}
+
+	return cfg.withDefaults(), nil
+}
+
+// ParseOverflowPolicy validates a textual overflow policy.
+func ParseOverflowPolicy(raw string) (OverflowPolicy, error) {
+	switch policy := OverflowPolicy(strings.ToLower(strings.TrimSpace(raw))); policy {
+	case OverflowBlock, OverflowDropOldest, OverflowDropNewest:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unsupported overflow policy %q (supported: block, drop-oldest, drop-newest)", raw)
+	}
+}
+
+// CoalesceConfig defines how bursts of volume-change events are merged.
+// A zero SettleWindow disables coalescing; a zero MaxDelay lets a burst
+// be held back for as long as updates keep arriving.
+type CoalesceConfig struct {
+	SettleWindow time.Duration
+	MaxDelay     time.Duration
+}
+
+func DefaultCoalesceConfig() CoalesceConfig {
+	return CoalesceConfig{
+		SettleWindow: defaultVolumeSettleWindow,
+		MaxDelay:     defaultVolumeMaxDelay,
+	}
+}
+
+// LoadCoalesceConfigFromEnv loads volume coalescing configuration from environment variables.
+func LoadCoalesceConfigFromEnv() (CoalesceConfig, error) {
+	cfg := DefaultCoalesceConfig()
+
+	settleWindow, err := envconfig.Millis(envVolumeSettleWindow, cfg.SettleWindow)
+	if err != nil {
+		return CoalesceConfig{}, err
+	}
+	cfg.SettleWindow = settleWindow
+
+	maxDelay, err := envconfig.Millis(envVolumeMaxDelay, cfg.MaxDelay)
+	if err != nil {
+		return CoalesceConfig{}, err
+	}
+	cfg.MaxDelay = maxDelay
+
+	return cfg, nil
+}
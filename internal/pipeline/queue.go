@@ -0,0 +1,167 @@
+package pipeline
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+var (
+	// ErrQueueFull is returned when a request is rejected by the drop-newest policy.
+	ErrQueueFull = errors.New("request queue is full")
+	// ErrQueueClosed is returned by EnqueueRequest after Close.
+	ErrQueueClosed = errors.New("request queue is closed")
+)
+
+// QueueStats is a snapshot of the queue counters.
+type QueueStats struct {
+	Depth         int
+	Enqueued      uint64
+	Dispatched    uint64
+	Failed        uint64
+	DroppedOldest uint64
+	DroppedNewest uint64
+}
+
+// Dropped returns the total number of requests lost to the overflow policy.
+func (s QueueStats) Dropped() uint64 {
+	return s.DroppedOldest + s.DroppedNewest
+}
+
+// Queue is a bounded in-memory queue with a dedicated dispatcher goroutine.
+// EnqueueRequest only touches memory, so native device callbacks return
+// immediately while the dispatcher waits on the slow transport.
+type Queue struct {
+	cfg    QueueConfig
+	next   enqueuer.EnqueueRequest
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	items  []enqueuer.Request
+	closed bool
+	stats  QueueStats
+
+	done chan struct{}
+}
+
+func NewQueue(cfg QueueConfig, next enqueuer.EnqueueRequest, logger *slog.Logger) *Queue {
+	if next == nil {
+		panic("nil enqueuer")
+	}
+	if logger == nil {
+		panic("nil logger")
+	}
+
+	cfg = cfg.withDefaults()
+	q := &Queue{
+		cfg:    cfg,
+		next:   next,
+		logger: logger,
+		items:  make([]enqueuer.Request, 0, cfg.Capacity),
+		done:   make(chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.mu)
+
+	logger.Info("Request queue started", "capacity", cfg.Capacity, "overflow", cfg.Overflow)
+	go q.dispatch()
+	return q
+}
+
+func (q *Queue) EnqueueRequest(request enqueuer.Request) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for !q.closed && len(q.items) >= q.cfg.Capacity {
+		switch q.cfg.Overflow {
+		case OverflowBlock:
+			q.cond.Wait()
+			continue
+		case OverflowDropNewest:
+			q.stats.DroppedNewest++
+			return ErrQueueFull
+		default:
+			dropped := q.items[0]
+			q.items = q.items[1:]
+			q.stats.DroppedOldest++
+			q.logger.Warn("Request queue full, dropped oldest request", "event", dropped.Event, "dropped", q.stats.Dropped())
+		}
+	}
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	q.items = append(q.items, request)
+	q.stats.Enqueued++
+	q.cond.Broadcast()
+	return nil
+}
+
+// Stats returns a snapshot of the queue counters.
+func (q *Queue) Stats() QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stats := q.stats
+	stats.Depth = len(q.items)
+	return stats
+}
+
+// Close stops accepting requests, lets the dispatcher drain what is already
+// queued and waits for it to finish.
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		q.cond.Broadcast()
+	}
+	q.mu.Unlock()
+
+	<-q.done
+	return nil
+}
+
+func (q *Queue) dispatch() {
+	defer close(q.done)
+
+	for {
+		request, ok := q.take()
+		if !ok {
+			stats := q.Stats()
+			q.logger.Info("Request queue stopped", "dispatched", stats.Dispatched, "failed", stats.Failed, "dropped", stats.Dropped())
+			return
+		}
+
+		err := q.next.EnqueueRequest(request)
+
+		q.mu.Lock()
+		if err != nil {
+			q.stats.Failed++
+		} else {
+			q.stats.Dispatched++
+		}
+		q.mu.Unlock()
+
+		if err != nil {
+			q.logger.Error("Enqueue failed", "event", request.Event, "err", err)
+		}
+	}
+}
+
+func (q *Queue) take() (enqueuer.Request, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.items) == 0 {
+		if q.closed {
+			return enqueuer.Request{}, false
+		}
+		q.cond.Wait()
+	}
+
+	request := q.items[0]
+	q.items = q.items[1:]
+	q.cond.Broadcast()
+	return request, true
+}
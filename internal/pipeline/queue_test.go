@@ -0,0 +1,140 @@
+package pipeline
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+// gatedEnqueuer blocks every delivery until the gate is opened.
+type gatedEnqueuer struct {
+	gate     chan struct{}
+	mu       sync.Mutex
+	received []enqueuer.Request
+}
+
+func newGatedEnqueuer() *gatedEnqueuer {
+	return &gatedEnqueuer{gate: make(chan struct{})}
+}
+
+func (g *gatedEnqueuer) EnqueueRequest(request enqueuer.Request) error {
+	<-g.gate
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.received = append(g.received, request)
+	return nil
+}
+
+func (g *gatedEnqueuer) volumes() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	result := make([]string, 0, len(g.received))
+	for _, request := range g.received {
+		result = append(result, request.Fields[contract.FieldVolume])
+	}
+	return result
+}
+
+func volumeRequest(volume string) enqueuer.Request {
+	return enqueuer.Request{
+		Event:  contract.EventTypeRenderVolumeChanged,
+		Fields: map[string]string{contract.FieldPnpID: "pnp-1", contract.FieldVolume: volume},
+	}
+}
+
+// fillQueue enqueues the first request and waits until the dispatcher holds it,
+// so the remaining requests occupy the queue deterministically.
+func fillQueue(t *testing.T, q *Queue, volumes ...string) {
+	t.Helper()
+	if err := q.EnqueueRequest(volumeRequest(volumes[0])); err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for q.Stats().Depth != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("dispatcher did not take the first request")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for _, volume := range volumes[1:] {
+		_ = q.EnqueueRequest(volumeRequest(volume))
+	}
+}
+
+func TestQueue_DropOldestKeepsNewestRequests(t *testing.T) {
+	next := newGatedEnqueuer()
+	q := NewQueue(QueueConfig{Capacity: 2, Overflow: OverflowDropOldest}, next, slog.Default())
+
+	fillQueue(t, q, "1", "2", "3", "4")
+	close(next.gate)
+	if err := q.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	volumes := next.volumes()
+	if len(volumes) != 3 || volumes[0] != "1" || volumes[1] != "3" || volumes[2] != "4" {
+		t.Fatalf("unexpected delivered volumes: %#v", volumes)
+	}
+	if stats := q.Stats(); stats.DroppedOldest != 1 || stats.Dispatched != 3 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestQueue_DropNewestRejectsIncomingRequest(t *testing.T) {
+	next := newGatedEnqueuer()
+	q := NewQueue(QueueConfig{Capacity: 1, Overflow: OverflowDropNewest}, next, slog.Default())
+
+	fillQueue(t, q, "1", "2")
+	if err := q.EnqueueRequest(volumeRequest("3")); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+	close(next.gate)
+	_ = q.Close()
+
+	volumes := next.volumes()
+	if len(volumes) != 2 || volumes[0] != "1" || volumes[1] != "2" {
+		t.Fatalf("unexpected delivered volumes: %#v", volumes)
+	}
+	if stats := q.Stats(); stats.DroppedNewest != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestQueue_EnqueueDoesNotWaitForSlowTransport(t *testing.T) {
+	next := newGatedEnqueuer()
+	q := NewQueue(QueueConfig{Capacity: 8}, next, slog.Default())
+
+	returned := make(chan struct{})
+	go func() {
+		for i := 0; i < 4; i++ {
+			_ = q.EnqueueRequest(volumeRequest("1"))
+		}
+		close(returned)
+	}()
+
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("EnqueueRequest blocked on the transport")
+	}
+	close(next.gate)
+	_ = q.Close()
+
+	if err := q.EnqueueRequest(volumeRequest("1")); !errors.Is(err, ErrQueueClosed) {
+		t.Fatalf("expected ErrQueueClosed, got %v", err)
+	}
+}
+
+func TestParseOverflowPolicy(t *testing.T) {
+	if policy, err := ParseOverflowPolicy(" Drop-Newest "); err != nil || policy != OverflowDropNewest {
+		t.Fatalf("unexpected result policy=%q err=%v", policy, err)
+	}
+	if _, err := ParseOverflowPolicy("drop-all"); err == nil {
+		t.Fatal("expected unsupported policy error")
+	}
+}
@@ -7,11 +7,15 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/envconfig"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/tlsconfig"
 )
 
 const (
 	defaultHost                    = "localhost"
 	defaultPort                    = 5672
+	defaultTLSPort                 = 5671
 	defaultVHost                   = "/"
 	defaultUser                    = "guest"
 	defaultPassword                = "guest"
@@ -23,8 +27,36 @@ const (
 	defaultInitialReconnectDelay   = 2 * time.Second
 	defaultMaxReconnectDelay       = 30 * time.Second
 	defaultPublishConfirmTimeout   = 10 * time.Second
+
+	envRabbitMQTLSEnabled    = "WIN_SOUND_RABBITMQ_TLS_ENABLED"
+	envRabbitMQTLSCAFile     = "WIN_SOUND_RABBITMQ_TLS_CA_FILE"
+	envRabbitMQTLSCertFile   = "WIN_SOUND_RABBITMQ_TLS_CERT_FILE"
+	envRabbitMQTLSKeyFile    = "WIN_SOUND_RABBITMQ_TLS_KEY_FILE"
+	envRabbitMQTLSServerName = "WIN_SOUND_RABBITMQ_TLS_SERVER_NAME"
+	envRabbitMQAuthMechanism = "WIN_SOUND_RABBITMQ_AUTH_MECHANISM"
 )
 
+// AuthMechanism is the SASL mechanism used to log in to the broker.
+type AuthMechanism string
+
+const (
+	// AuthPlain logs in with User and Password.
+	AuthPlain AuthMechanism = "PLAIN"
+	// AuthExternal logs in with the TLS client certificate; the broker takes
+	// the user name from the certificate subject.
+	AuthExternal AuthMechanism = "EXTERNAL"
+)
+
+// ParseAuthMechanism accepts the mechanism names case-insensitively.
+func ParseAuthMechanism(raw string) (AuthMechanism, error) {
+	switch m := AuthMechanism(strings.ToUpper(strings.TrimSpace(raw))); m {
+	case AuthPlain, AuthExternal:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unsupported auth mechanism %q (supported: %s, %s)", raw, AuthPlain, AuthExternal)
+	}
+}
+
 // Config defines RabbitMQ connection, topology, and retry settings.
 type Config struct {
 	Host                    string
@@ -40,6 +72,12 @@ type Config struct {
 	InitialReconnectDelay   time.Duration
 	MaxReconnectDelay       time.Duration
 	PublishConfirmTimeout   time.Duration
+
+	// TLSEnabled dials amqps instead of amqp; TLS holds the optional CA
+	// bundle, client certificate and server name override.
+	TLSEnabled    bool
+	TLS           tlsconfig.Config
+	AuthMechanism AuthMechanism
 }
 
 func DefaultConfig() Config {
@@ -57,7 +95,23 @@ func DefaultConfig() Config {
 		InitialReconnectDelay:   defaultInitialReconnectDelay,
 		MaxReconnectDelay:       defaultMaxReconnectDelay,
 		PublishConfirmTimeout:   defaultPublishConfirmTimeout,
+		AuthMechanism:           AuthPlain,
+	}
+}
+
+func (c Config) defaultPort() int {
+	if c.TLSEnabled {
+		return defaultTLSPort
 	}
+	return defaultPort
+}
+
+// Scheme is the AMQP URI scheme matching TLSEnabled.
+func (c Config) Scheme() string {
+	if c.TLSEnabled {
+		return "amqps"
+	}
+	return "amqp"
 }
 
 func (c Config) withDefaults() Config {
@@ -65,14 +119,14 @@ func (c Config) withDefaults() Config {
 
 	if host, port, ok := splitHostPort(c.Host); ok {
 		c.Host = host
-		if c.Port <= 0 || c.Port == d.Port {
+		if c.Port <= 0 || c.Port == c.defaultPort() {
 			c.Port = port
 		}
 	}
 
 	c.Host = defaultTrimmedString(c.Host, d.Host)
 	if c.Port <= 0 {
-		c.Port = d.Port
+		c.Port = c.defaultPort()
 	}
 	c.VHost = defaultTrimmedString(c.VHost, d.VHost)
 	c.User = defaultTrimmedString(c.User, d.User)
@@ -98,6 +152,9 @@ func (c Config) withDefaults() Config {
 	if c.MaxReconnectDelay < c.InitialReconnectDelay {
 		c.MaxReconnectDelay = c.InitialReconnectDelay
 	}
+	if c.AuthMechanism == "" {
+		c.AuthMechanism = d.AuthMechanism
+	}
 
 	return c
 }
@@ -114,90 +171,108 @@ func defaultTrimmedString(v, fallback string) string {
 func LoadConfigFromEnv() (Config, error) {
 	cfg := DefaultConfig()
 
-	cfg.Host = trimmedEnvOrDefault("WIN_SOUND_RABBITMQ_HOST", cfg.Host)
-	cfg.VHost = envOrDefault("WIN_SOUND_RABBITMQ_VHOST", cfg.VHost)
-	cfg.User = envOrDefault("WIN_SOUND_RABBITMQ_USER", cfg.User)
-	cfg.Password = envOrDefault("WIN_SOUND_RABBITMQ_PASSWORD", cfg.Password)
-	cfg.ExchangeName = envOrDefault("WIN_SOUND_RABBITMQ_EXCHANGE", cfg.ExchangeName)
-	cfg.QueueName = envOrDefault("WIN_SOUND_RABBITMQ_QUEUE", cfg.QueueName)
-	cfg.RoutingKey = envOrDefault("WIN_SOUND_RABBITMQ_ROUTING_KEY", cfg.RoutingKey)
+	cfg.Host = envconfig.TrimmedString("WIN_SOUND_RABBITMQ_HOST", cfg.Host)
+	cfg.VHost = envconfig.String("WIN_SOUND_RABBITMQ_VHOST", cfg.VHost)
+	cfg.User = envconfig.String("WIN_SOUND_RABBITMQ_USER", cfg.User)
+	cfg.Password = envconfig.String("WIN_SOUND_RABBITMQ_PASSWORD", cfg.Password)
+	cfg.ExchangeName = envconfig.String("WIN_SOUND_RABBITMQ_EXCHANGE", cfg.ExchangeName)
+	cfg.QueueName = envconfig.String("WIN_SOUND_RABBITMQ_QUEUE", cfg.QueueName)
+	cfg.RoutingKey = envconfig.String("WIN_SOUND_RABBITMQ_ROUTING_KEY", cfg.RoutingKey)
 
-	port, err := intEnvOrDefault("WIN_SOUND_RABBITMQ_PORT", cfg.Port)
+	if err := loadTLSFromEnv(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	// amqps listens on its own port; an explicit port or host:port still wins.
+	cfg.Port = cfg.defaultPort()
+	port, err := envconfig.Int("WIN_SOUND_RABBITMQ_PORT", cfg.Port)
 	if err != nil {
 		return Config{}, err
 	}
 	cfg.Port = port
 
-	connectionThresholdSeconds, err := intEnvOrDefault("WIN_SOUND_RABBITMQ_CONNECTION_THRESHOLD_SEC", int(cfg.ConnectionThreshold/time.Second))
+	connectionThresholdSeconds, err := envconfig.Int("WIN_SOUND_RABBITMQ_CONNECTION_THRESHOLD_SEC", int(cfg.ConnectionThreshold/time.Second))
 	if err != nil {
 		return Config{}, err
 	}
 	cfg.ConnectionThreshold = time.Duration(connectionThresholdSeconds) * time.Second
 
-	maxReconnectAttempts, err := nonNegativeIntEnvOrDefault("WIN_SOUND_RABBITMQ_MAX_RECONNECT_ATTEMPTS", cfg.MaxReconnectionAttempts)
+	maxReconnectAttempts, err := envconfig.NonNegativeInt("WIN_SOUND_RABBITMQ_MAX_RECONNECT_ATTEMPTS", cfg.MaxReconnectionAttempts)
 	if err != nil {
 		return Config{}, err
 	}
 	cfg.MaxReconnectionAttempts = maxReconnectAttempts
 
-	initialReconnectDelayMillis, err := intEnvOrDefault("WIN_SOUND_RABBITMQ_INITIAL_RECONNECT_DELAY_MS", int(cfg.InitialReconnectDelay/time.Millisecond))
+	initialReconnectDelayMillis, err := envconfig.Int("WIN_SOUND_RABBITMQ_INITIAL_RECONNECT_DELAY_MS", int(cfg.InitialReconnectDelay/time.Millisecond))
 	if err != nil {
 		return Config{}, err
 	}
 	cfg.InitialReconnectDelay = time.Duration(initialReconnectDelayMillis) * time.Millisecond
 
-	maxReconnectDelayMillis, err := nonNegativeIntEnvOrDefault("WIN_SOUND_RABBITMQ_MAX_RECONNECT_DELAY_MS", int(cfg.MaxReconnectDelay/time.Millisecond))
+	maxReconnectDelayMillis, err := envconfig.NonNegativeInt("WIN_SOUND_RABBITMQ_MAX_RECONNECT_DELAY_MS", int(cfg.MaxReconnectDelay/time.Millisecond))
 	if err != nil {
 		return Config{}, err
 	}
 	cfg.MaxReconnectDelay = time.Duration(maxReconnectDelayMillis) * time.Millisecond
 
-	publishConfirmTimeoutMillis, err := nonNegativeIntEnvOrDefault("WIN_SOUND_RABBITMQ_PUBLISH_CONFIRM_TIMEOUT_MS", int(cfg.PublishConfirmTimeout/time.Millisecond))
+	publishConfirmTimeoutMillis, err := envconfig.NonNegativeInt("WIN_SOUND_RABBITMQ_PUBLISH_CONFIRM_TIMEOUT_MS", int(cfg.PublishConfirmTimeout/time.Millisecond))
 	if err != nil {
 		return Config{}, err
 	}
 	cfg.PublishConfirmTimeout = time.Duration(publishConfirmTimeoutMillis) * time.Millisecond
 
-	return cfg.withDefaults(), nil
+	cfg = cfg.withDefaults()
+	if err := cfg.validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
 }
 
-func envOrDefault(key, fallback string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
+func loadTLSFromEnv(cfg *Config) error {
+	if v := strings.TrimSpace(os.Getenv(envRabbitMQTLSEnabled)); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %w", envRabbitMQTLSEnabled, v, err)
+		}
+		cfg.TLSEnabled = enabled
 	}
-	return fallback
-}
+	cfg.TLS.CAFile = strings.TrimSpace(os.Getenv(envRabbitMQTLSCAFile))
+	cfg.TLS.CertFile = strings.TrimSpace(os.Getenv(envRabbitMQTLSCertFile))
+	cfg.TLS.KeyFile = strings.TrimSpace(os.Getenv(envRabbitMQTLSKeyFile))
+	cfg.TLS.ServerName = strings.TrimSpace(os.Getenv(envRabbitMQTLSServerName))
 
-func trimmedEnvOrDefault(key, fallback string) string {
-	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
-		return v
+	if v := strings.TrimSpace(os.Getenv(envRabbitMQAuthMechanism)); v != "" {
+		mechanism, err := ParseAuthMechanism(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", envRabbitMQAuthMechanism, err)
+		}
+		cfg.AuthMechanism = mechanism
 	}
-	return fallback
+	return nil
 }
 
-func intEnvOrDefault(key string, fallback int) (int, error) {
-	v := strings.TrimSpace(os.Getenv(key))
-	if v == "" {
-		return fallback, nil
+// validate rejects TLS and auth settings that would be silently ignored.
+func (c Config) validate() error {
+	if !c.TLSEnabled && !c.TLS.IsZero() {
+		return fmt.Errorf("rabbitmq TLS options are set but %s is not true", envRabbitMQTLSEnabled)
 	}
-
-	n, err := strconv.Atoi(v)
-	if err != nil {
-		return 0, fmt.Errorf("invalid %s %q: %w", key, v, err)
+	if err := c.TLS.Validate(); err != nil {
+		return fmt.Errorf("invalid rabbitmq TLS settings: %w", err)
 	}
-
-	return n, nil
-}
-
-func nonNegativeIntEnvOrDefault(key string, fallback int) (int, error) {
-	n, err := intEnvOrDefault(key, fallback)
-	if err != nil {
-		return 0, err
+	if _, err := ParseAuthMechanism(string(c.AuthMechanism)); err != nil {
+		return err
 	}
-	if n < 0 {
-		return 0, fmt.Errorf("%s can not be negative %q", key, strings.TrimSpace(os.Getenv(key)))
+	if c.AuthMechanism == AuthExternal && (!c.TLSEnabled || !c.TLS.HasClientCertificate()) {
+		return fmt.Errorf("rabbitmq %s auth requires %s and a client certificate (%s, %s)",
+			AuthExternal, envRabbitMQTLSEnabled, envRabbitMQTLSCertFile, envRabbitMQTLSKeyFile)
 	}
-	return n, nil
+	return nil
+}
+
+// usesGuestAccount reports whether PLAIN login falls back to guest/guest,
+// which RabbitMQ only accepts from localhost.
+func (c Config) usesGuestAccount() bool {
+	return c.AuthMechanism == AuthPlain && c.User == defaultUser && c.Password == defaultPassword
 }
 
 func splitHostPort(raw string) (string, int, bool) {
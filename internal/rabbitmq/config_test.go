@@ -33,3 +33,59 @@ func TestSplitHostPort(t *testing.T) {
 		t.Fatalf("unexpected split result host=%q port=%d", host, port)
 	}
 }
+
+func TestLoadConfigFromEnv_TLSDefaultsToAmqpsPort(t *testing.T) {
+	t.Setenv("WIN_SOUND_RABBITMQ_HOST", "rabbit.example")
+	t.Setenv("WIN_SOUND_RABBITMQ_PORT", "")
+	t.Setenv(envRabbitMQTLSEnabled, "true")
+	t.Setenv(envRabbitMQTLSCAFile, "/etc/rabbitmq/ca.pem")
+	t.Setenv(envRabbitMQTLSCertFile, "/etc/rabbitmq/client.pem")
+	t.Setenv(envRabbitMQTLSKeyFile, "/etc/rabbitmq/client.key")
+	t.Setenv(envRabbitMQTLSServerName, "rabbit.internal")
+	t.Setenv(envRabbitMQAuthMechanism, "external")
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed: %v", err)
+	}
+
+	if cfg.Scheme() != "amqps" || cfg.Port != defaultTLSPort {
+		t.Fatalf("unexpected scheme/port: %s %d", cfg.Scheme(), cfg.Port)
+	}
+	if cfg.TLS.ServerName != "rabbit.internal" || cfg.AuthMechanism != AuthExternal {
+		t.Fatalf("unexpected TLS/auth settings: %+v %s", cfg.TLS, cfg.AuthMechanism)
+	}
+}
+
+func TestWithDefaults_TLSHostPortEmbedded(t *testing.T) {
+	cfg := Config{Host: "rabbit.example:5681", TLSEnabled: true, Port: defaultTLSPort}.withDefaults()
+
+	if cfg.Host != "rabbit.example" || cfg.Port != 5681 {
+		t.Fatalf("unexpected host/port: %q %d", cfg.Host, cfg.Port)
+	}
+}
+
+func TestLoadConfigFromEnv_RejectsBadTLSCombinations(t *testing.T) {
+	cases := map[string]map[string]string{
+		"TLS file without TLS":         {envRabbitMQTLSCAFile: "/etc/rabbitmq/ca.pem"},
+		"invalid TLS flag":             {envRabbitMQTLSEnabled: "yes please"},
+		"key without cert":             {envRabbitMQTLSEnabled: "true", envRabbitMQTLSKeyFile: "/etc/rabbitmq/client.key"},
+		"unknown mechanism":            {envRabbitMQAuthMechanism: "AMQPLAIN"},
+		"EXTERNAL without TLS":         {envRabbitMQAuthMechanism: "EXTERNAL"},
+		"EXTERNAL without certificate": {envRabbitMQTLSEnabled: "true", envRabbitMQAuthMechanism: "EXTERNAL"},
+	}
+	for name, env := range cases {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range []string{
+				envRabbitMQTLSEnabled, envRabbitMQTLSCAFile, envRabbitMQTLSCertFile, envRabbitMQTLSKeyFile,
+				envRabbitMQTLSServerName, envRabbitMQAuthMechanism,
+			} {
+				t.Setenv(key, env[key])
+			}
+
+			if _, err := LoadConfigFromEnv(); err == nil {
+				t.Fatal("expected configuration error")
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package rabbitmq
+
+import (
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/cloudevents"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+// Header names set on every message.
+const (
+	HeaderHostName  = "hostName"
+	HeaderPnpID     = "pnpId"
+	HeaderMethod    = "httpMethod"
+	HeaderURLSuffix = "urlSuffix"
+
+	// headerCloudEventsPrefix marks CloudEvents attributes in binary mode,
+	// as the CloudEvents AMQP binding names application properties.
+	headerCloudEventsPrefix = "cloudEvents:"
+)
+
+// Message is one AMQP message: the JSON body and the properties consumers
+// deduplicate and route on.
+type Message struct {
+	Body        []byte
+	ContentType string
+	// MessageID is stable across retries of the same event.
+	MessageID string
+	// Type is the event type name, e.g. RenderVolumeChanged.
+	Type string
+	// CorrelationID groups the messages of one device.
+	CorrelationID string
+	Headers       map[string]string
+	// Timestamp is the event time; zero means the publish time.
+	Timestamp time.Time
+}
+
+func newMessage(payload enqueuer.RequestPayload, mode cloudevents.Mode) (Message, error) {
+	message := Message{
+		Body:          payload.Body,
+		ContentType:   enqueuer.PayloadContentType,
+		MessageID:     payload.EventID,
+		Type:          payload.Event.String(),
+		CorrelationID: payload.DeviceKey,
+		Headers: map[string]string{
+			HeaderHostName:  payload.HostName,
+			HeaderPnpID:     payload.PnpID,
+			HeaderMethod:    payload.HTTPRequest,
+			HeaderURLSuffix: payload.URLSuffix,
+		},
+		Timestamp: payload.EventTime,
+	}
+
+	switch mode {
+	case cloudevents.ModeStructured:
+		body, err := cloudevents.FromPayload(payload).MarshalStructured()
+		if err != nil {
+			return Message{}, err
+		}
+		message.Body = body
+		message.ContentType = cloudevents.ContentTypeStructured
+	case cloudevents.ModeBinary:
+		for _, attribute := range cloudevents.FromPayload(payload).Attributes() {
+			message.Headers[headerCloudEventsPrefix+attribute.Name] = attribute.Value
+		}
+	}
+	return message, nil
+}
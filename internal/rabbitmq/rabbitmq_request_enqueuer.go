@@ -6,12 +6,17 @@ import (
 	"log/slog"
 	"time"
 
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/cloudevents"
 	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/tracing"
 )
 
 // RabbitMessagePublisher is the publishing contract expected from a RabbitMQ publisher.
 type RabbitMessagePublisher interface {
-	Publish(ctx context.Context, body []byte) error
+	Publish(ctx context.Context, message Message) error
 	Close() error
 }
 
@@ -21,9 +26,10 @@ type Enqueuer struct {
 	publisher      RabbitMessagePublisher
 	logger         *slog.Logger
 	publishTimeout time.Duration
+	cloudEvents    cloudevents.Mode
 }
 
-func NewEnqueuerWithContext(baseCtx context.Context, publisher RabbitMessagePublisher, logger *slog.Logger) *Enqueuer {
+func NewEnqueuerWithContext(baseCtx context.Context, publisher RabbitMessagePublisher, logger *slog.Logger, cloudEvents cloudevents.Mode) *Enqueuer {
 	if baseCtx == nil {
 		panic("nil context")
 	}
@@ -41,21 +47,41 @@ func NewEnqueuerWithContext(baseCtx context.Context, publisher RabbitMessagePubl
 		publisher:      publisher,
 		logger:         logger,
 		publishTimeout: publishTimeout,
+		cloudEvents:    cloudEvents,
 	}
 }
 
-func (e *Enqueuer) EnqueueRequest(request enqueuer.Request) error {
+func (e *Enqueuer) EnqueueRequest(request enqueuer.Request) (err error) {
+	// The producer span continues the trace of the device callback; its
+	// traceparent goes into the message headers.
+	ctx, span := tracing.Start(tracing.ContextWithTraceParent(e.baseCtx, request.TraceParent), "rabbitmq publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			tracing.AttrEventType.String(request.Event.String()),
+			tracing.AttrMessagingSystem.String("rabbitmq"),
+			tracing.AttrMessagingOperationType.String("send"),
+		))
+	defer func() { tracing.End(span, err) }()
+
 	e.logger.Info("Preparing request in RabbitMQ enqueuer", "event", request.Event, "fields", request.Fields)
+	_, buildSpan := tracing.Start(ctx, "BuildRequestPayload")
 	payload, err := enqueuer.BuildRequestPayload(request)
+	tracing.End(buildSpan, err)
 	if err != nil {
 		return fmt.Errorf("marshal rabbitmq payload: %w", err)
 	}
 
 	e.logger.Info("publishing request", "method", payload.HTTPRequest, "urlSuffix", payload.URLSuffix, "updated", payload.UpdateDateUtc)
 
-	ctx, cancel := context.WithTimeout(e.baseCtx, e.publishTimeout)
+	message, err := newMessage(payload, e.cloudEvents)
+	if err != nil {
+		return fmt.Errorf("marshal rabbitmq payload: %w", err)
+	}
+	tracing.Inject(ctx, propagation.MapCarrier(message.Headers))
+
+	ctx, cancel := context.WithTimeout(ctx, e.publishTimeout)
 	defer cancel()
-	if err := e.publisher.Publish(ctx, payload.Body); err != nil {
+	if err := e.publisher.Publish(ctx, message); err != nil {
 		return fmt.Errorf("publish request: %w", err)
 	}
 
@@ -0,0 +1,156 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/cloudevents"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+	"github.com/collect-sound-devices/win-sound-scanner-go/pkg/appinfo"
+)
+
+type fakePublisher struct {
+	messages []Message
+	err      error
+}
+
+func (p *fakePublisher) Publish(_ context.Context, message Message) error {
+	p.messages = append(p.messages, message)
+	return p.err
+}
+
+func (p *fakePublisher) Close() error {
+	return nil
+}
+
+func volumeRequest(at time.Time) enqueuer.Request {
+	return enqueuer.Request{
+		Timestamp: at,
+		Event:     contract.EventTypeRenderVolumeChanged,
+		Fields: map[string]string{
+			contract.FieldPnpID:        "pnp-1",
+			contract.FieldHostName:     "host-1",
+			contract.FieldRenderVolume: "42",
+		},
+	}
+}
+
+func TestEnqueueRequest_PublishesMessageProperties(t *testing.T) {
+	publisher := &fakePublisher{}
+	sut := NewEnqueuerWithContext(context.Background(), publisher, slog.Default(), cloudevents.ModeOff)
+	eventTime := time.Date(2026, 5, 26, 10, 0, 0, 0, time.UTC)
+
+	if err := sut.EnqueueRequest(volumeRequest(eventTime)); err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+
+	message := publisher.messages[0]
+	if message.MessageID == "" || message.Type != "RenderVolumeChanged" || message.CorrelationID != "host-1|pnp-1" {
+		t.Fatalf("unexpected message properties: %+v", message)
+	}
+	if !message.Timestamp.Equal(eventTime) {
+		t.Fatalf("expected event time %s, got %s", eventTime, message.Timestamp)
+	}
+	want := map[string]string{HeaderHostName: "host-1", HeaderPnpID: "pnp-1", HeaderMethod: "PUT", HeaderURLSuffix: "/pnp-1/host-1"}
+	for key, value := range want {
+		if message.Headers[key] != value {
+			t.Fatalf("header %s: expected %q, got %q", key, value, message.Headers[key])
+		}
+	}
+}
+
+func TestEnqueueRequest_RetryKeepsMessageID(t *testing.T) {
+	publisher := &fakePublisher{err: errors.New("boom")}
+	sut := NewEnqueuerWithContext(context.Background(), publisher, slog.Default(), cloudevents.ModeOff)
+	request := volumeRequest(time.Date(2026, 5, 26, 10, 0, 0, 0, time.UTC))
+
+	if err := sut.EnqueueRequest(request); err == nil {
+		t.Fatal("expected publisher error")
+	}
+	publisher.err = nil
+	if err := sut.EnqueueRequest(request); err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+
+	if publisher.messages[0].MessageID != publisher.messages[1].MessageID {
+		t.Fatalf("expected a stable message ID, got %q and %q", publisher.messages[0].MessageID, publisher.messages[1].MessageID)
+	}
+}
+
+func TestEnqueueRequest_CloudEventsModes(t *testing.T) {
+	request := volumeRequest(time.Date(2026, 5, 26, 10, 0, 0, 0, time.UTC))
+
+	binary := &fakePublisher{}
+	if err := NewEnqueuerWithContext(context.Background(), binary, slog.Default(), cloudevents.ModeBinary).EnqueueRequest(request); err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+	message := binary.messages[0]
+	if message.ContentType != "application/json" || message.Headers["cloudEvents:type"] != "com.collect-sound-devices.render.volume-changed" ||
+		message.Headers["cloudEvents:id"] != message.MessageID || message.Headers["cloudEvents:source"] != "scanner://host-1" {
+		t.Fatalf("unexpected binary-mode message: %+v", message)
+	}
+
+	structured := &fakePublisher{}
+	if err := NewEnqueuerWithContext(context.Background(), structured, slog.Default(), cloudevents.ModeStructured).EnqueueRequest(request); err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+	message = structured.messages[0]
+	if message.ContentType != cloudevents.ContentTypeStructured || !strings.Contains(string(message.Body), `"specversion":"1.0"`) {
+		t.Fatalf("unexpected structured-mode message: %+v", message)
+	}
+	if _, ok := message.Headers["cloudEvents:id"]; ok {
+		t.Fatal("structured mode must not set binary-mode headers")
+	}
+}
+
+func TestNewPublishing_MapsMessageProperties(t *testing.T) {
+	eventTime := time.Date(2026, 5, 26, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	publishing := newPublishing(Message{
+		Body:          []byte(`{}`),
+		MessageID:     "id-1",
+		Type:          "RenderVolumeChanged",
+		CorrelationID: "host-1|pnp-1",
+		Headers:       map[string]string{HeaderPnpID: "pnp-1"},
+		Timestamp:     eventTime,
+	})
+
+	if publishing.MessageId != "id-1" || publishing.Type != "RenderVolumeChanged" || publishing.CorrelationId != "host-1|pnp-1" {
+		t.Fatalf("unexpected properties: %+v", publishing)
+	}
+	if publishing.AppId != appinfo.AppName+"/"+appinfo.Version {
+		t.Fatalf("unexpected app id: %q", publishing.AppId)
+	}
+	if publishing.DeliveryMode != amqp.Persistent || publishing.ContentType != "application/json" {
+		t.Fatalf("unexpected delivery mode/content type: %d %q", publishing.DeliveryMode, publishing.ContentType)
+	}
+	if !publishing.Timestamp.Equal(eventTime) || publishing.Timestamp.Location() != time.UTC {
+		t.Fatalf("expected UTC event time, got %s", publishing.Timestamp)
+	}
+	if publishing.Headers[HeaderPnpID] != "pnp-1" {
+		t.Fatalf("unexpected headers: %#v", publishing.Headers)
+	}
+}
+
+func TestEnqueueRequest_PropagatesTraceContext(t *testing.T) {
+	publisher := &fakePublisher{}
+	sut := NewEnqueuerWithContext(context.Background(), publisher, slog.Default(), cloudevents.ModeOff)
+	request := volumeRequest(time.Date(2026, 5, 26, 10, 0, 0, 0, time.UTC))
+	request.TraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	if err := sut.EnqueueRequest(request); err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+
+	// The header names the producer span, so only the trace ID is fixed.
+	got := publisher.messages[0].Headers["traceparent"]
+	if !strings.HasPrefix(got, "00-4bf92f3577b34da6a3ce929d0e0e4736-") {
+		t.Fatalf("expected the trace to continue in the traceparent header, got %q", got)
+	}
+}
@@ -2,24 +2,51 @@ package rabbitmq
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/health"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/metrics"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/tracing"
+	"github.com/collect-sound-devices/win-sound-scanner-go/pkg/appinfo"
 )
 
+// metricsTransport labels the RabbitMQ metrics, matching the enqueuer mode.
+const metricsTransport = "rabbitmq"
+
 // RequestPublisher manages RabbitMQ connection, topology, and message publishing.
 type RequestPublisher struct {
 	cfg    Config
 	logger *slog.Logger
+	tls    *tls.Config
 
 	mu       sync.Mutex
 	conn     *amqp.Connection
 	ch       *amqp.Channel
 	confirms <-chan amqp.Confirmation
+
+	// link is replaced on every connect and close and by the NotifyClose
+	// watcher, so Health never waits for a publish holding mu.
+	link atomic.Pointer[linkState]
+
+	// done ends the background reconnect on Close.
+	done   context.Context
+	cancel context.CancelFunc
+}
+
+// linkState is the state of one connection and its channel.
+type linkState struct {
+	open   bool
+	detail string
 }
 
 // NewRequestPublisher creates a RabbitMQ publisher and establishes the AMQP
@@ -33,19 +60,34 @@ func NewRequestPublisher(ctx context.Context, cfg Config, logger *slog.Logger) (
 	}
 
 	cfg = cfg.withDefaults()
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
 
 	p := &RequestPublisher{
 		cfg:    cfg,
 		logger: logger,
 	}
+	p.done, p.cancel = context.WithCancel(context.Background())
+	if cfg.TLSEnabled {
+		tlsCfg, err := cfg.TLS.Build()
+		if err != nil {
+			return nil, fmt.Errorf("rabbitmq TLS: %w", err)
+		}
+		p.tls = tlsCfg
+	}
+	if cfg.usesGuestAccount() {
+		logger.Warn("RabbitMQ uses the default guest/guest credentials; set WIN_SOUND_RABBITMQ_USER and WIN_SOUND_RABBITMQ_PASSWORD", "host", cfg.Host)
+	}
 
 	if err := p.connectWithRetryLocked(ctx); err != nil {
+		p.cancel()
 		return nil, err
 	}
 	return p, nil
 }
 
-func (p *RequestPublisher) Publish(ctx context.Context, body []byte) error {
+func (p *RequestPublisher) Publish(ctx context.Context, message Message) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
@@ -59,14 +101,14 @@ func (p *RequestPublisher) Publish(ctx context.Context, body []byte) error {
 		}
 	}
 
-	if err := p.publishLocked(ctx, body); err == nil {
+	if err := p.publishLocked(ctx, message); err == nil {
 		return nil
 	} else {
 		p.logger.Warn("RabbitMQ publish failed, reconnecting once", "err", err)
 		if recErr := p.connectWithRetryLocked(ctx); recErr != nil {
 			return fmt.Errorf("rabbitmq publish failed: %w (reconnect failed: %v)", err, recErr)
 		}
-		if retryErr := p.publishLocked(ctx, body); retryErr != nil {
+		if retryErr := p.publishLocked(ctx, message); retryErr != nil {
 			return fmt.Errorf("rabbitmq publish failed after reconnect: %w", retryErr)
 		}
 	}
@@ -74,16 +116,32 @@ func (p *RequestPublisher) Publish(ctx context.Context, body []byte) error {
 	return nil
 }
 
+// Health reports the publisher as up while the connection and the channel
+// are open. A link the broker closed is reopened in the background, see
+// reconnect, or by the next publish, whichever comes first.
+func (p *RequestPublisher) Health() health.Component {
+	link := p.link.Load()
+	if link == nil {
+		return health.Down("not connected")
+	}
+	if !link.open {
+		return health.Down(link.detail)
+	}
+	return health.Up(link.detail)
+}
+
 func (p *RequestPublisher) Close() error {
+	p.cancel()
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	return p.closeLocked()
 }
 
-func (p *RequestPublisher) publishLocked(ctx context.Context, body []byte) error {
+func (p *RequestPublisher) publishLocked(ctx context.Context, message Message) error {
 	if p.ch == nil {
 		return errors.New("rabbitmq channel is not initialized")
 	}
+	trace.SpanFromContext(ctx).SetAttributes(tracing.AttrMessagingDestination.String(p.cfg.ExchangeName))
 
 	err := p.ch.PublishWithContext(
 		ctx,
@@ -91,17 +149,21 @@ func (p *RequestPublisher) publishLocked(ctx context.Context, body []byte) error
 		p.cfg.RoutingKey,
 		false,
 		false,
-		amqp.Publishing{
-			ContentType:  "application/json",
-			DeliveryMode: amqp.Persistent,
-			Timestamp:    time.Now().UTC(),
-			Body:         body,
-		},
+		newPublishing(message),
 	)
 	if err != nil {
 		return fmt.Errorf("publish call failed: %w", err)
 	}
+	published := time.Now()
+	_, confirmSpan := tracing.Start(ctx, "rabbitmq confirm")
+	err = p.waitConfirm(ctx, published)
+	tracing.End(confirmSpan, err)
+	return err
+}
 
+// waitConfirm waits for the publisher confirm of the message published at
+// published.
+func (p *RequestPublisher) waitConfirm(ctx context.Context, published time.Time) error {
 	confirmTimeout := p.cfg.PublishConfirmTimeout
 	if deadline, ok := ctx.Deadline(); ok {
 		remaining := time.Until(deadline)
@@ -121,6 +183,7 @@ func (p *RequestPublisher) publishLocked(ctx context.Context, body []byte) error
 		if !ok {
 			return errors.New("rabbitmq confirms channel is closed")
 		}
+		metrics.ObserveConfirmWait(time.Since(published))
 		if !c.Ack {
 			return fmt.Errorf("message NOT ACKed (deliveryTag=%d)", c.DeliveryTag)
 		}
@@ -138,8 +201,13 @@ func (p *RequestPublisher) connectWithRetryLocked(ctx context.Context) error {
 	delay := p.cfg.InitialReconnectDelay
 
 	for attempt := 1; attempt <= p.cfg.MaxReconnectionAttempts; attempt++ {
-		if err := p.connectOnceLocked(); err == nil {
-			p.logger.Info("RabbitMQ producer initialized", "attempt", attempt)
+		err := p.connectOnceLocked()
+		metrics.ReconnectAttempt(metricsTransport, err)
+		if err != nil {
+			p.link.Store(&linkState{detail: err.Error()})
+		}
+		if err == nil {
+			p.logger.Info("RabbitMQ producer initialized", "attempt", attempt, "scheme", p.cfg.Scheme(), "auth", string(p.cfg.AuthMechanism))
 			return nil
 		} else {
 			lastErr = err
@@ -168,7 +236,7 @@ func (p *RequestPublisher) connectWithRetryLocked(ctx context.Context) error {
 			case <-timer.C:
 			}
 
-			delay = minDuration(delay*2, p.cfg.MaxReconnectDelay)
+			delay = min(delay*2, p.cfg.MaxReconnectDelay)
 		}
 	}
 
@@ -180,14 +248,14 @@ func (p *RequestPublisher) connectOnceLocked() error {
 
 	conn, err := amqp.DialConfig(
 		amqp.URI{
-			Scheme:   "amqp",
+			Scheme:   p.cfg.Scheme(),
 			Host:     p.cfg.Host,
 			Port:     p.cfg.Port,
 			Username: p.cfg.User,
 			Password: p.cfg.Password,
 			Vhost:    p.cfg.VHost,
 		}.String(),
-		amqp.Config{Heartbeat: p.cfg.ConnectionThreshold},
+		p.dialConfig(),
 	)
 	if err != nil {
 		return fmt.Errorf("dial failed: %w", err)
@@ -248,10 +316,123 @@ func (p *RequestPublisher) connectOnceLocked() error {
 	p.conn = conn
 	p.ch = ch
 	p.confirms = ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+	p.watchLink(conn, ch)
 
 	return nil
 }
 
+// watchLink marks the link down when the broker closes the connection or the
+// channel. Each notify channel receives at most one error before it is
+// closed, so the buffer of one never blocks the library.
+func (p *RequestPublisher) watchLink(conn *amqp.Connection, ch *amqp.Channel) {
+	link := &linkState{open: true, detail: fmt.Sprintf("connected to %s:%d", p.cfg.Host, p.cfg.Port)}
+	p.link.Store(link)
+
+	connClosed := conn.NotifyClose(make(chan *amqp.Error, 1))
+	chClosed := ch.NotifyClose(make(chan *amqp.Error, 1))
+	go func() {
+		var err *amqp.Error
+		var detail string
+		select {
+		case err = <-connClosed:
+			detail = closedDetail("connection", err)
+		case err = <-chClosed:
+			detail = closedDetail("channel", err)
+		}
+		// A reconnect has already stored a newer state. A close without an
+		// error is our own, from Close or a reconnect.
+		if p.link.CompareAndSwap(link, &linkState{detail: detail}) && err != nil {
+			p.logger.Warn("RabbitMQ link closed by the broker; reconnecting in the background", "detail", detail)
+			go p.reconnect()
+		}
+	}()
+}
+
+// reconnect reopens a link the broker closed, so readiness recovers with the
+// broker instead of waiting for the next device event. It retries with the
+// reconnect backoff until a connect succeeds, a publish reconnected first or
+// the publisher is closed.
+func (p *RequestPublisher) reconnect() {
+	delay := p.cfg.InitialReconnectDelay
+	for {
+		timer := time.NewTimer(delay)
+		select {
+		case <-p.done.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		p.mu.Lock()
+		if p.done.Err() != nil {
+			p.mu.Unlock()
+			return
+		}
+		if link := p.link.Load(); link != nil && link.open {
+			p.mu.Unlock()
+			return
+		}
+		err := p.connectOnceLocked()
+		metrics.ReconnectAttempt(metricsTransport, err)
+		if err != nil {
+			p.link.Store(&linkState{detail: err.Error()})
+		}
+		p.mu.Unlock()
+
+		if err == nil {
+			p.logger.Info("RabbitMQ producer reconnected", "scheme", p.cfg.Scheme())
+			return
+		}
+		p.logger.Warn("RabbitMQ background reconnect failed; retrying", "retryDelay", delay, "err", err)
+		delay = min(delay*2, p.cfg.MaxReconnectDelay)
+	}
+}
+
+func closedDetail(what string, err *amqp.Error) string {
+	if err == nil {
+		return what + " closed"
+	}
+	return fmt.Sprintf("%s closed: %s", what, err.Error())
+}
+
+func (p *RequestPublisher) dialConfig() amqp.Config {
+	cfg := amqp.Config{Heartbeat: p.cfg.ConnectionThreshold}
+	if p.tls != nil {
+		// DialConfig fills in ServerName, so every dial gets its own copy.
+		cfg.TLSClientConfig = p.tls.Clone()
+	}
+	if p.cfg.AuthMechanism == AuthExternal {
+		cfg.SASL = []amqp.Authentication{&amqp.ExternalAuth{}}
+	}
+	return cfg
+}
+
+func newPublishing(message Message) amqp.Publishing {
+	timestamp := message.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	contentType := message.ContentType
+	if contentType == "" {
+		contentType = enqueuer.PayloadContentType
+	}
+	headers := make(amqp.Table, len(message.Headers))
+	for key, value := range message.Headers {
+		headers[key] = value
+	}
+	return amqp.Publishing{
+		ContentType:   contentType,
+		DeliveryMode:  amqp.Persistent,
+		MessageId:     message.MessageID,
+		Type:          message.Type,
+		AppId:         appinfo.AppName + "/" + appinfo.Version,
+		CorrelationId: message.CorrelationID,
+		Headers:       headers,
+		Timestamp:     timestamp.UTC(),
+		Body:          message.Body,
+	}
+}
+
 func (p *RequestPublisher) closeLocked() error {
 	var err error
 
@@ -264,13 +445,9 @@ func (p *RequestPublisher) closeLocked() error {
 		p.conn = nil
 	}
 	p.confirms = nil
+	if p.link.Load() != nil {
+		p.link.Store(&linkState{detail: "closed"})
+	}
 
 	return err
 }
-
-func minDuration(a, b time.Duration) time.Duration {
-	if a < b {
-		return a
-	}
-	return b
-}
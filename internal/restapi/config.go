@@ -0,0 +1,167 @@
+package restapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/envconfig"
+)
+
+const (
+	defaultTimeout           = 10 * time.Second
+	defaultMaxRetries        = 3
+	defaultInitialRetryDelay = 500 * time.Millisecond
+	defaultMaxRetryDelay     = 10 * time.Second
+	envHTTPBaseURL           = "WIN_SOUND_HTTP_BASE_URL"
+	envHTTPTimeout           = "WIN_SOUND_HTTP_TIMEOUT_MS"
+	envHTTPMaxRetries        = "WIN_SOUND_HTTP_MAX_RETRIES"
+	envHTTPInitialRetryDelay = "WIN_SOUND_HTTP_INITIAL_RETRY_DELAY_MS"
+	envHTTPMaxRetryDelay     = "WIN_SOUND_HTTP_MAX_RETRY_DELAY_MS"
+	envHTTPHeaders           = "WIN_SOUND_HTTP_HEADERS"
+)
+
+// Config defines the Device Repository REST API endpoint and retry settings.
+type Config struct {
+	// BaseURL is the resource URL the computed urlSuffix is appended to.
+	BaseURL string
+	// Timeout limits a single HTTP attempt.
+	Timeout           time.Duration
+	MaxRetries        int
+	InitialRetryDelay time.Duration
+	MaxRetryDelay     time.Duration
+	Headers           http.Header
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Timeout:           defaultTimeout,
+		MaxRetries:        defaultMaxRetries,
+		InitialRetryDelay: defaultInitialRetryDelay,
+		MaxRetryDelay:     defaultMaxRetryDelay,
+		Headers:           http.Header{},
+	}
+}
+
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	c.BaseURL = strings.TrimRight(strings.TrimSpace(c.BaseURL), "/")
+	if c.Timeout <= 0 {
+		c.Timeout = d.Timeout
+	}
+	if c.MaxRetries < 0 {
+		c.MaxRetries = d.MaxRetries
+	}
+	if c.InitialRetryDelay <= 0 {
+		c.InitialRetryDelay = d.InitialRetryDelay
+	}
+	if c.MaxRetryDelay <= 0 {
+		c.MaxRetryDelay = d.MaxRetryDelay
+	}
+	if c.MaxRetryDelay < c.InitialRetryDelay {
+		c.MaxRetryDelay = c.InitialRetryDelay
+	}
+	if c.Headers == nil {
+		c.Headers = d.Headers
+	}
+	return c
+}
+
+func (c Config) validate() error {
+	if c.BaseURL == "" {
+		return fmt.Errorf("%s is required", envHTTPBaseURL)
+	}
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", envHTTPBaseURL, c.BaseURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid %s %q: scheme must be http or https", envHTTPBaseURL, c.BaseURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid %s %q: host is empty", envHTTPBaseURL, c.BaseURL)
+	}
+	return nil
+}
+
+// RequestBudget returns the longest time a request can take including all
+// retries, which is used to bound a single EnqueueRequest call.
+func (c Config) RequestBudget() time.Duration {
+	c = c.withDefaults()
+	retries := time.Duration(c.MaxRetries)
+	return c.Timeout*(retries+1) + c.MaxRetryDelay*retries
+}
+
+// LoadConfigFromEnv loads REST API configuration from environment variables.
+// WIN_SOUND_HTTP_BASE_URL is required.
+func LoadConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+	cfg.BaseURL = os.Getenv(envHTTPBaseURL)
+
+	timeout, err := envconfig.Millis(envHTTPTimeout, cfg.Timeout)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Timeout = timeout
+
+	if v := strings.TrimSpace(os.Getenv(envHTTPMaxRetries)); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s %q: %w", envHTTPMaxRetries, v, err)
+		}
+		if n < 0 {
+			return Config{}, fmt.Errorf("%s can not be negative %q", envHTTPMaxRetries, v)
+		}
+		cfg.MaxRetries = n
+	}
+
+	initialDelay, err := envconfig.Millis(envHTTPInitialRetryDelay, cfg.InitialRetryDelay)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.InitialRetryDelay = initialDelay
+
+	maxDelay, err := envconfig.Millis(envHTTPMaxRetryDelay, cfg.MaxRetryDelay)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.MaxRetryDelay = maxDelay
+
+	headers, err := parseHeaders(os.Getenv(envHTTPHeaders))
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid %s: %w", envHTTPHeaders, err)
+	}
+	cfg.Headers = headers
+
+	cfg = cfg.withDefaults()
+	if err := cfg.validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// parseHeaders reads "Name: value" pairs separated by semicolons,
+// for example "Authorization: Bearer abc; X-Site: lab-1".
+func parseHeaders(raw string) (http.Header, error) {
+	headers := http.Header{}
+	var errs []error
+	for _, part := range strings.Split(raw, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			errs = append(errs, fmt.Errorf("header %q is not in \"Name: value\" form", part))
+			continue
+		}
+		headers.Add(name, strings.TrimSpace(value))
+	}
+	return headers, errors.Join(errs...)
+}
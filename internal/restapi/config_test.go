@@ -0,0 +1,59 @@
+package restapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadConfigFromEnv_RequiresBaseURL(t *testing.T) {
+	t.Setenv(envHTTPBaseURL, "")
+
+	if _, err := LoadConfigFromEnv(); err == nil {
+		t.Fatal("expected error for missing base URL")
+	}
+}
+
+func TestLoadConfigFromEnv_Overrides(t *testing.T) {
+	t.Setenv(envHTTPBaseURL, " https://repo.example.com/api/AudioDevices/ ")
+	t.Setenv(envHTTPTimeout, "2500")
+	t.Setenv(envHTTPMaxRetries, "5")
+	t.Setenv(envHTTPInitialRetryDelay, "100")
+	t.Setenv(envHTTPMaxRetryDelay, "2000")
+	t.Setenv(envHTTPHeaders, "Authorization: Bearer abc; X-Site: lab-1")
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed: %v", err)
+	}
+
+	if cfg.BaseURL != "https://repo.example.com/api/AudioDevices" {
+		t.Fatalf("unexpected base URL: %q", cfg.BaseURL)
+	}
+	if cfg.Timeout != 2500*time.Millisecond || cfg.MaxRetries != 5 {
+		t.Fatalf("unexpected timeout/retries: %s/%d", cfg.Timeout, cfg.MaxRetries)
+	}
+	if cfg.InitialRetryDelay != 100*time.Millisecond || cfg.MaxRetryDelay != 2*time.Second {
+		t.Fatalf("unexpected retry delays: %s/%s", cfg.InitialRetryDelay, cfg.MaxRetryDelay)
+	}
+	if cfg.Headers.Get("Authorization") != "Bearer abc" || cfg.Headers.Get("X-Site") != "lab-1" {
+		t.Fatalf("unexpected headers: %#v", cfg.Headers)
+	}
+}
+
+func TestLoadConfigFromEnv_InvalidValues(t *testing.T) {
+	cases := map[string]string{
+		envHTTPBaseURL:    "ftp://repo.example.com",
+		envHTTPTimeout:    "abc",
+		envHTTPMaxRetries: "-1",
+		envHTTPHeaders:    "no-colon-here",
+	}
+	for key, value := range cases {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(envHTTPBaseURL, "http://localhost:5027/api/AudioDevices")
+			t.Setenv(key, value)
+			if _, err := LoadConfigFromEnv(); err == nil {
+				t.Fatalf("expected error for %s=%q", key, value)
+			}
+		})
+	}
+}
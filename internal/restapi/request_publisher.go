@@ -0,0 +1,170 @@
+package restapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+	"github.com/collect-sound-devices/win-sound-scanner-go/pkg/appinfo"
+)
+
+const maxErrorBodyBytes = 512
+
+// statusClass tells how a response status is handled.
+type statusClass int
+
+const (
+	statusSuccess statusClass = iota
+	statusRetryable
+	statusRejected
+)
+
+// classifyStatus treats 2xx as success, 408, 425, 429 and 5xx as transient
+// and every other status as a permanent rejection of the request.
+func classifyStatus(code int) statusClass {
+	switch {
+	case code >= 200 && code < 300:
+		return statusSuccess
+	case code == http.StatusRequestTimeout, code == http.StatusTooEarly, code == http.StatusTooManyRequests, code >= 500:
+		return statusRetryable
+	default:
+		return statusRejected
+	}
+}
+
+// RequestPublisher sends request payloads straight to the Device Repository REST API.
+type RequestPublisher struct {
+	cfg    Config
+	logger *slog.Logger
+	client *http.Client
+}
+
+func NewRequestPublisher(cfg Config, logger *slog.Logger) (*RequestPublisher, error) {
+	if logger == nil {
+		panic("nil logger")
+	}
+
+	cfg = cfg.withDefaults()
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	logger.Info("REST API publisher initialized", "baseUrl", cfg.BaseURL, "timeout", cfg.Timeout, "maxRetries", cfg.MaxRetries)
+	return &RequestPublisher{
+		cfg:    cfg,
+		logger: logger,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+// Publish sends body with the given method to BaseURL+urlSuffix. Network
+// errors and transient statuses are retried with exponential backoff;
+// other statuses fail immediately with an error wrapping enqueuer.ErrRejected.
+func (p *RequestPublisher) Publish(ctx context.Context, method, urlSuffix string, body []byte) error {
+	if ctx == nil {
+		panic("nil context")
+	}
+
+	target := p.cfg.BaseURL + escapeSuffix(urlSuffix)
+	delay := p.cfg.InitialRetryDelay
+	var lastErr error
+
+	for attempt := 1; attempt <= p.cfg.MaxRetries+1; attempt++ {
+		retryAfter, err := p.send(ctx, method, target, body)
+		if err == nil {
+			p.logger.Info("REST API request accepted", "method", method, "url", target, "attempt", attempt)
+			return nil
+		}
+		if errors.Is(err, enqueuer.ErrRejected) || ctx.Err() != nil {
+			return err
+		}
+
+		lastErr = err
+		if attempt > p.cfg.MaxRetries {
+			break
+		}
+		wait := delay
+		if retryAfter > 0 {
+			wait = min(retryAfter, p.cfg.MaxRetryDelay)
+		}
+		p.logger.Warn("REST API request failed; retrying", "method", method, "url", target, "attempt", attempt, "retryDelay", wait, "err", err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay = min(delay*2, p.cfg.MaxRetryDelay)
+	}
+
+	return fmt.Errorf("rest api request failed after %d attempts: %w", p.cfg.MaxRetries+1, lastErr)
+}
+
+func (p *RequestPublisher) send(ctx context.Context, method, target string, body []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("build http request: %w: %w", err, enqueuer.ErrRejected)
+	}
+	for name, values := range p.cfg.Headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", appinfo.AppName+"/"+appinfo.Version)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("http request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+
+	switch classifyStatus(resp.StatusCode) {
+	case statusSuccess:
+		return 0, nil
+	case statusRetryable:
+		return parseRetryAfter(resp.Header.Get("Retry-After")), fmt.Errorf("http status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	default:
+		return 0, fmt.Errorf("http status %d: %s: %w", resp.StatusCode, strings.TrimSpace(string(detail)), enqueuer.ErrRejected)
+	}
+}
+
+func (p *RequestPublisher) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
+
+// escapeSuffix escapes every path segment, since PnP IDs contain
+// characters such as '\', '{' and '#'.
+func escapeSuffix(urlSuffix string) string {
+	if urlSuffix == "" {
+		return ""
+	}
+	segments := strings.Split(urlSuffix, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func parseRetryAfter(raw string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,129 @@
+package restapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+func testPublisher(t *testing.T, baseURL string) *RequestPublisher {
+	t.Helper()
+	publisher, err := NewRequestPublisher(Config{
+		BaseURL:           baseURL,
+		Timeout:           time.Second,
+		MaxRetries:        2,
+		InitialRetryDelay: time.Millisecond,
+		MaxRetryDelay:     5 * time.Millisecond,
+		Headers:           http.Header{"X-Api-Key": []string{"secret"}},
+	}, slog.Default())
+	if err != nil {
+		t.Fatalf("NewRequestPublisher failed: %v", err)
+	}
+	return publisher
+}
+
+func TestPublish_SendsMethodSuffixBodyAndHeaders(t *testing.T) {
+	var gotMethod, gotPath, gotKey, gotType, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotMethod, gotPath, gotBody = r.Method, r.URL.EscapedPath(), string(body)
+		gotKey, gotType = r.Header.Get("X-Api-Key"), r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sut := testPublisher(t, server.URL+"/api/AudioDevices")
+	err := sut.Publish(context.Background(), "PUT", `/SWD\MMDEVAPI\{0.0.0}/host-1`, []byte(`{"a":1}`))
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if gotMethod != "PUT" || gotBody != `{"a":1}` {
+		t.Fatalf("unexpected request: %s %q", gotMethod, gotBody)
+	}
+	if gotPath != "/api/AudioDevices/SWD%5CMMDEVAPI%5C%7B0.0.0%7D/host-1" {
+		t.Fatalf("unexpected path: %q", gotPath)
+	}
+	if gotKey != "secret" || gotType != "application/json" {
+		t.Fatalf("unexpected headers: key=%q content-type=%q", gotKey, gotType)
+	}
+}
+
+func TestPublish_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	if err := testPublisher(t, server.URL).Publish(context.Background(), "POST", "", nil); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls.Load())
+	}
+}
+
+func TestPublish_GivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := testPublisher(t, server.URL).Publish(context.Background(), "POST", "", nil)
+	if err == nil || errors.Is(err, enqueuer.ErrRejected) {
+		t.Fatalf("expected transient error, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls.Load())
+	}
+}
+
+func TestPublish_ClientErrorIsRejectedWithoutRetry(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		http.Error(w, "bad payload", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	err := testPublisher(t, server.URL).Publish(context.Background(), "POST", "", nil)
+	if !errors.Is(err, enqueuer.ErrRejected) {
+		t.Fatalf("expected ErrRejected, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected a single attempt, got %d", calls.Load())
+	}
+}
+
+func TestClassifyStatus(t *testing.T) {
+	cases := map[int]statusClass{
+		200: statusSuccess,
+		204: statusSuccess,
+		400: statusRejected,
+		404: statusRejected,
+		408: statusRetryable,
+		429: statusRetryable,
+		500: statusRetryable,
+		503: statusRetryable,
+	}
+	for code, want := range cases {
+		if got := classifyStatus(code); got != want {
+			t.Fatalf("classifyStatus(%d) = %d, want %d", code, got, want)
+		}
+	}
+}
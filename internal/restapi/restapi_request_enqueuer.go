@@ -0,0 +1,71 @@
+package restapi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+// MessagePublisher is the publishing contract expected from a REST API publisher.
+type MessagePublisher interface {
+	Publish(ctx context.Context, method, urlSuffix string, body []byte) error
+	Close() error
+}
+
+// Enqueuer sends requests directly to the REST API using the shared message-shaping.
+type Enqueuer struct {
+	baseCtx        context.Context
+	publisher      MessagePublisher
+	logger         *slog.Logger
+	publishTimeout time.Duration
+}
+
+// NewEnqueuerWithContext creates an enqueuer whose publishTimeout bounds a
+// request including all of its retries.
+func NewEnqueuerWithContext(baseCtx context.Context, publisher MessagePublisher, logger *slog.Logger, publishTimeout time.Duration) *Enqueuer {
+	if baseCtx == nil {
+		panic("nil context")
+	}
+	if publisher == nil {
+		panic("nil publisher")
+	}
+	if logger == nil {
+		panic("nil logger")
+	}
+	if publishTimeout <= 0 {
+		publishTimeout = defaultTimeout
+	}
+
+	return &Enqueuer{
+		baseCtx:        baseCtx,
+		publisher:      publisher,
+		logger:         logger,
+		publishTimeout: publishTimeout,
+	}
+}
+
+func (e *Enqueuer) EnqueueRequest(request enqueuer.Request) error {
+	e.logger.Debug("Preparing request in REST API enqueuer", "event", request.Event, "fields", request.Fields)
+	payload, err := enqueuer.BuildRequestPayload(request)
+	if err != nil {
+		// The same request fails the same way every time, so it is not retried.
+		return fmt.Errorf("marshal rest api payload: %w: %w", err, enqueuer.ErrRejected)
+	}
+
+	e.logger.Info("sending request", "method", payload.HTTPRequest, "urlSuffix", payload.URLSuffix, "updated", payload.UpdateDateUtc)
+
+	ctx, cancel := context.WithTimeout(e.baseCtx, e.publishTimeout)
+	defer cancel()
+	if err := e.publisher.Publish(ctx, payload.HTTPRequest, payload.URLSuffix, payload.Body); err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+
+	return nil
+}
+
+func (e *Enqueuer) Close() error {
+	return e.publisher.Close()
+}
@@ -2,16 +2,35 @@ package scannerapp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/cloudevents"
 	c "github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/encoding"
 	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/filesink"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/health"
 	kafkatarget "github.com/collect-sound-devices/win-sound-scanner-go/internal/kafka"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/metrics"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/mqtt"
+	natstarget "github.com/collect-sound-devices/win-sound-scanner-go/internal/nats"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/outbox"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/pipeline"
 	"github.com/collect-sound-devices/win-sound-scanner-go/internal/rabbitmq"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/restapi"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/schema"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/schemaregistry"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/tracing"
 )
 
 // WithComponent adds a component attribute when one is provided.
@@ -26,7 +45,10 @@ func WithComponent(logger *slog.Logger, component string) *slog.Logger {
 	return logger.With("component", component)
 }
 
-func Run(ctx context.Context, logger *slog.Logger) error {
+// Run starts the device source and the request pipeline and runs until ctx
+// is cancelled. Every request received from reload rebuilds the request
+// enqueuer chain, see ReloadRequest; reload may be nil.
+func Run(ctx context.Context, logger *slog.Logger, reload <-chan ReloadRequest) error {
 	if ctx == nil {
 		panic("nil context")
 	}
@@ -36,27 +58,86 @@ func Run(ctx context.Context, logger *slog.Logger) error {
 
 	appLogger := WithComponent(logger, "application-root")
 
+	// The listeners start first, so a broker that is slow to connect already
+	// shows in the reconnect metrics and as not ready.
+	stopListeners, err := startListeners(logger)
+	if err != nil {
+		return err
+	}
+	defer stopListeners()
+	stopTracing, err := startTracing(ctx, logger)
+	if err != nil {
+		return err
+	}
+	defer stopTracing()
+
+	// The device source is registered before the transports connect, so
+	// readiness stays down until the pipeline is running.
+	source, err := newDeviceSource()
+	if err != nil {
+		return err
+	}
+	defer health.Register(healthDeviceSource, deviceSourceCheck(source))()
+
 	appLogger.Info("Initializing. Creating request enqueuer.")
-	reqEnqueuer, cleanupEnqueuer, err := newRequestEnqueuer(ctx, logger)
+	chain, err := newEnqueuerChain(ctx, logger)
 	if err != nil {
 		return err
 	}
-	defer cleanupEnqueuer()
+	defer chain.close()
+
+	stopReloads := chain.serveReloads(ctx, reload)
+	defer stopReloads()
+	return runPipeline(ctx, logger, source, chain.current)
+}
+
+// runPipeline connects the device source to the request enqueuer through the
+// volume coalescer and the request queue and runs until ctx is cancelled.
+func runPipeline(ctx context.Context, logger *slog.Logger, source DeviceSource, reqEnqueuer enqueuer.EnqueueRequest) error {
+	appLogger := WithComponent(logger, "application-root")
 
-	enqueue := func(event c.EventType, fields map[string]string) {
+	appLogger.Info("Creating request queue.")
+	queueCfg, err := pipeline.LoadQueueConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	queue := pipeline.NewQueue(queueCfg, reqEnqueuer, WithComponent(logger, "request_queue"))
+	metrics.SetQueue(queue.Stats)
+	defer func() {
+		metrics.SetQueue(nil)
+		_ = queue.Close()
+	}()
+
+	coalesceCfg, err := pipeline.LoadCoalesceConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	coalescer := pipeline.NewCoalescer(coalesceCfg, queue, WithComponent(logger, "volume_coalescer"))
+	defer func() {
+		_ = coalescer.Close()
+	}()
+
+	enqueue := func(ctx context.Context, event c.EventType, fields map[string]string) {
 		appLogger.Info("Enqueue request..")
-		if err := reqEnqueuer.EnqueueRequest(enqueuer.Request{
-			Timestamp: time.Now(),
-			Event:     event,
-			Fields:    fields,
-		}); err != nil {
+		metrics.EventReceived(event)
+		// The span ends when the request is queued; the transports continue
+		// the trace from its traceparent.
+		ctx, span := tracing.Start(ctx, "enqueue", trace.WithAttributes(tracing.AttrEventType.String(event.String())))
+		err := coalescer.EnqueueRequest(enqueuer.Request{
+			Timestamp:   time.Now(),
+			Event:       event,
+			Fields:      fields,
+			TraceParent: tracing.TraceParent(ctx),
+		})
+		tracing.End(span, err)
+		if err != nil {
 			appLogger.Error("Enqueue failed", "event", event, "err", err)
 		}
 	}
 
 	appLogger.Info("Initializing")
 
-	app, err := NewImpl(enqueue, WithComponent(logger, " cpp-lib-engine"))
+	app, err := NewImpl(source, enqueue, WithComponent(logger, " cpp-lib-engine"))
 	if err != nil {
 		return err
 	}
@@ -68,7 +149,61 @@ func Run(ctx context.Context, logger *slog.Logger) error {
 	return nil
 }
 
-func newRequestEnqueuer(ctx context.Context, logger *slog.Logger) (enqueuer.EnqueueRequest, func(), error) {
+// chainConfig holds the enqueuer settings that are read before any
+// transport connects. A chain keeps the configuration it was opened with,
+// so a failed reload can reopen it while the environment already holds the
+// new settings.
+type chainConfig struct {
+	modes      []string
+	bestEffort map[string]bool
+	// outbox is only read when a sink needs it, see durableModes.
+	outbox     outbox.Config
+	validation schema.Config
+}
+
+func loadChainConfig(logger *slog.Logger) (chainConfig, error) {
+	requestLogger := WithComponent(logger, "dispatch_enqueuer")
+	modes, bestEffort, err := parseEnqueuerModes(os.Getenv(EnvWinSoundEnqueuer), os.Getenv(EnvWinSoundEnqueuerBestEffort))
+	if err != nil {
+		return chainConfig{}, err
+	}
+	cfg := chainConfig{modes: modes, bestEffort: bestEffort}
+
+	for _, mode := range modes {
+		if durableModes[mode] {
+			continue
+		}
+		requestLogger.Info("Reading outbox configuration...")
+		if cfg.outbox, err = outbox.LoadConfigFromEnv(); err != nil {
+			return chainConfig{}, err
+		}
+		break
+	}
+
+	requestLogger.Info("Reading schema validation configuration...")
+	if cfg.validation, err = schema.LoadConfigFromEnv(); err != nil {
+		return chainConfig{}, err
+	}
+	return cfg, nil
+}
+
+// connectedSink is the transport of one enqueuer mode, connected but not yet
+// put behind its outbox. Connecting and assembling are separate steps, so a
+// reload can connect the new transports while the old outboxes still run.
+type connectedSink struct {
+	mode      string
+	transport enqueuer.EnqueueRequest
+	cleanup   func()
+	// durable is set for the empty and file enqueuers, which need no outbox.
+	durable  bool
+	required bool
+	// reconnect opens the transport again with the settings it was created
+	// with. It is only set for MQTT, which a reload closes ahead of time.
+	reconnect func(ctx context.Context) (connectedSink, error)
+}
+
+// connectSinks creates the transports of all modes in cfg.
+func connectSinks(ctx context.Context, cfg chainConfig, logger *slog.Logger) ([]connectedSink, error) {
 	if ctx == nil {
 		panic("nil context")
 	}
@@ -76,19 +211,361 @@ func newRequestEnqueuer(ctx context.Context, logger *slog.Logger) (enqueuer.Enqu
 		panic("nil logger")
 	}
 
-	mode := strings.ToLower(strings.TrimSpace(os.Getenv(EnvWinSoundEnqueuer)))
 	requestLogger := WithComponent(logger, "dispatch_enqueuer")
+	sinks := make([]connectedSink, 0, len(cfg.modes))
+	for _, mode := range cfg.modes {
+		sink, err := connectSink(ctx, mode, logger, requestLogger)
+		if err != nil {
+			closeSinks(sinks)
+			if len(cfg.modes) > 1 {
+				err = fmt.Errorf("create %s sink: %w", mode, err)
+			}
+			return nil, err
+		}
+		sink.required = !cfg.bestEffort[mode]
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// closeSinks closes the transports.
+func closeSinks(sinks []connectedSink) {
+	cleanups := make([]func(), 0, len(sinks))
+	for _, sink := range sinks {
+		cleanups = append(cleanups, sink.cleanup)
+	}
+	closeParallel(cleanups)
+}
+
+// closeParallel runs the cleanups in parallel, so one slow sink does not
+// hold up the others' shutdown.
+func closeParallel(cleanups []func()) {
+	var wg sync.WaitGroup
+	for _, c := range cleanups {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c()
+		}()
+	}
+	wg.Wait()
+}
+
+// chainLayers are what a chain puts in front of its transports: schema
+// validation and the outboxes. The outboxes are kept by sink mode, so a
+// reload can drain the ones the new configuration no longer opens.
+type chainLayers struct {
+	close    func()
+	outboxes map[string]*outbox.Outbox
+}
+
+func noLayers() chainLayers {
+	return chainLayers{close: func() {}}
+}
+
+// outboxDir returns the outbox directory of a sink, or "" if it has none.
+func outboxDir(cfg outbox.Config, sink connectedSink) string {
+	if !cfg.Enabled || sink.durable {
+		return ""
+	}
+	return filepath.Join(cfg.Dir, sink.mode)
+}
+
+// assembleSinks puts the connected transports behind their outboxes and,
+// for several sinks, the fan-out enqueuer. The returned layers close the
+// outboxes only; the transports stay open for the caller to close, also
+// on error.
+func assembleSinks(sinks []connectedSink, outboxCfg outbox.Config, logger *slog.Logger) (enqueuer.EnqueueRequest, chainLayers, error) {
+	requestLogger := WithComponent(logger, "dispatch_enqueuer")
+	if outboxCfg.Enabled {
+		adoptRootOutbox(sinks, outboxCfg.Dir, requestLogger)
+	}
+	layers := chainLayers{outboxes: make(map[string]*outbox.Outbox)}
+	keep := func(sink connectedSink, sinkEnqueuer enqueuer.EnqueueRequest) {
+		if box, ok := sinkEnqueuer.(*outbox.Outbox); ok {
+			layers.outboxes[sink.mode] = box
+		}
+	}
+	if len(sinks) == 1 {
+		next, cleanup, err := assembleSink(sinks[0], outboxCfg, logger, requestLogger)
+		if err != nil {
+			return nil, chainLayers{}, err
+		}
+		keep(sinks[0], next)
+		layers.close = cleanup
+		return next, layers, nil
+	}
 
+	var modes, bestEffort []string
+	for _, sink := range sinks {
+		modes = append(modes, sink.mode)
+		if !sink.required {
+			bestEffort = append(bestEffort, sink.mode)
+		}
+	}
+	requestLogger.Info("Creating fan-out request enqueuer...", "sinks", modes, "bestEffort", bestEffort)
+	fanOutSinks := make([]enqueuer.Sink, 0, len(sinks))
+	cleanups := make([]func(), 0, len(sinks))
+	for _, sink := range sinks {
+		sinkEnqueuer, sinkCleanup, err := assembleSink(sink, outboxCfg, logger, requestLogger)
+		if err != nil {
+			closeParallel(cleanups)
+			return nil, chainLayers{}, fmt.Errorf("create %s sink: %w", sink.mode, err)
+		}
+		keep(sink, sinkEnqueuer)
+		cleanups = append(cleanups, sinkCleanup)
+		fanOutSinks = append(fanOutSinks, enqueuer.Sink{Name: sink.mode, Enqueuer: sinkEnqueuer, Required: sink.required})
+	}
+
+	layers.close = func() { closeParallel(cleanups) }
+	return enqueuer.NewFanOutRequestEnqueuer(fanOutSinks, WithComponent(logger, "fanout_enqueuer")), layers, nil
+}
+
+func assembleSink(sink connectedSink, outboxCfg outbox.Config, logger, requestLogger *slog.Logger) (enqueuer.EnqueueRequest, func(), error) {
+	if sink.durable {
+		return sink.transport, func() {}, nil
+	}
+	return newOutboxEnqueuer(sink, outboxCfg, logger, requestLogger)
+}
+
+// adoptRootOutbox moves the write-ahead file a single sink used to keep
+// directly in the outbox directory to the first sink with an outbox, so
+// adding a sink to the list does not orphan its pending requests.
+func adoptRootOutbox(sinks []connectedSink, dir string, requestLogger *slog.Logger) {
+	for _, sink := range sinks {
+		if sink.durable {
+			continue
+		}
+		sinkDir := filepath.Join(dir, sink.mode)
+		moved, err := outbox.MoveWAL(dir, sinkDir)
+		if err != nil {
+			requestLogger.Warn("Outbox file of the previous layout was not moved; its requests are not delivered", "sink", sink.mode, "err", err)
+		} else if moved {
+			requestLogger.Info("Moved the outbox file of the previous layout", "sink", sink.mode, "dir", sinkDir)
+		}
+		return
+	}
+}
+
+// newValidatingEnqueuer puts JSON Schema validation in front of all sinks,
+// so an invalid payload reaches neither an outbox nor a broker.
+func newValidatingEnqueuer(next enqueuer.EnqueueRequest, cfg schema.Config, logger *slog.Logger) (enqueuer.EnqueueRequest, func(), error) {
+	requestLogger := WithComponent(logger, "dispatch_enqueuer")
+	if !cfg.Enabled {
+		requestLogger.Info("Schema validation is disabled")
+		return next, func() {}, nil
+	}
+
+	fileCfg := filesink.DefaultConfig()
+	fileCfg.Dir = cfg.RejectedDir
+	fileCfg.FilePrefix = filesink.RejectedFilePrefix
+	writer, err := filesink.NewRotatingWriter(fileCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open rejected events log: %w", err)
+	}
+	rejected := filesink.NewRejectedLog(writer)
+	requestLogger.Info("Schema validation enabled", "schemaVersion", schema.Version, "rejectedLog", writer.Path())
+
+	cleanup := func() {
+		if err := rejected.Close(); err != nil {
+			requestLogger.Error("Rejected events log close failed", "err", err)
+		}
+	}
+	return enqueuer.NewValidatingRequestEnqueuer(next, rejected, WithComponent(logger, "schema_validator")), cleanup, nil
+}
+
+// parseEnqueuerModes reads the comma-separated enqueuer list (rabbitmq when
+// empty) and the subset of it whose failures are only logged.
+func parseEnqueuerModes(rawModes, rawBestEffort string) ([]string, map[string]bool, error) {
+	modes := splitModes(rawModes)
+	if len(modes) == 0 {
+		modes = []string{EnvWinSoundEnqueuerVal01RabbitMq}
+	}
+
+	seen := make(map[string]bool, len(modes))
+	for _, mode := range modes {
+		if !supportedEnqueuerModes[mode] {
+			return nil, nil, unsupportedEnqueuerError(mode)
+		}
+		if seen[mode] {
+			return nil, nil, fmt.Errorf("invalid %s %q: %s is listed twice", EnvWinSoundEnqueuer, rawModes, mode)
+		}
+		seen[mode] = true
+	}
+
+	bestEffort := make(map[string]bool)
+	for _, mode := range splitModes(rawBestEffort) {
+		if !seen[mode] {
+			return nil, nil, fmt.Errorf("invalid %s %q: %s is not listed in %s", EnvWinSoundEnqueuerBestEffort, rawBestEffort, mode, EnvWinSoundEnqueuer)
+		}
+		bestEffort[mode] = true
+	}
+	if len(bestEffort) == len(modes) && len(modes) > 1 {
+		return nil, nil, fmt.Errorf("invalid %s %q: at least one sink must be required", EnvWinSoundEnqueuerBestEffort, rawBestEffort)
+	}
+	return modes, bestEffort, nil
+}
+
+func splitModes(raw string) []string {
+	var modes []string
+	for _, part := range strings.Split(raw, ",") {
+		if mode := strings.ToLower(strings.TrimSpace(part)); mode != "" {
+			modes = append(modes, mode)
+		}
+	}
+	return modes
+}
+
+func unsupportedEnqueuerError(mode string) error {
+	return fmt.Errorf("unsupported %s=%q (supported: empty, rabbitmq, kafka, http, mqtt, nats, file)", EnvWinSoundEnqueuer, mode)
+}
+
+// durableModes write to local storage themselves and need no outbox.
+var durableModes = map[string]bool{
+	EnvWinSoundEnqueuerVal00Empty: true,
+	EnvWinSoundEnqueuerVal06File:  true,
+}
+
+var supportedEnqueuerModes = map[string]bool{
+	EnvWinSoundEnqueuerVal00Empty:    true,
+	EnvWinSoundEnqueuerVal01RabbitMq: true,
+	EnvWinSoundEnqueuerVal02Kafka:    true,
+	EnvWinSoundEnqueuerVal03Http:     true,
+	EnvWinSoundEnqueuerVal04Mqtt:     true,
+	EnvWinSoundEnqueuerVal05Nats:     true,
+	EnvWinSoundEnqueuerVal06File:     true,
+}
+
+// connectSink creates the transport for one mode. Broker and HTTP
+// transports are put behind the outbox later, see assembleSink.
+func connectSink(ctx context.Context, mode string, logger, requestLogger *slog.Logger) (connectedSink, error) {
+	var (
+		transport enqueuer.EnqueueRequest
+		cleanup   func()
+		reconnect func(ctx context.Context) (connectedSink, error)
+		err       error
+	)
 	switch mode {
 	case EnvWinSoundEnqueuerVal00Empty:
-		return newEmptyRequestEnqueuer(requestLogger, logger)
-	case "", EnvWinSoundEnqueuerVal01RabbitMq:
-		return newRabbitMQRequestEnqueuer(ctx, logger, requestLogger)
+		transport, cleanup, err = newEmptyRequestEnqueuer(requestLogger, logger)
+	case EnvWinSoundEnqueuerVal06File:
+		transport, cleanup, err = newFileRequestEnqueuer(logger, requestLogger)
+	case EnvWinSoundEnqueuerVal01RabbitMq:
+		transport, cleanup, err = newRabbitMQRequestEnqueuer(ctx, logger, requestLogger)
 	case EnvWinSoundEnqueuerVal02Kafka:
-		return newKafkaRequestEnqueuer(ctx, logger, requestLogger)
+		transport, cleanup, err = newKafkaRequestEnqueuer(ctx, logger, requestLogger)
+	case EnvWinSoundEnqueuerVal03Http:
+		transport, cleanup, err = newHTTPRequestEnqueuer(ctx, logger, requestLogger)
+	case EnvWinSoundEnqueuerVal04Mqtt:
+		var settings mqttSettings
+		if settings, err = loadMQTTSettings(requestLogger); err == nil {
+			transport, cleanup, err = newMQTTRequestEnqueuer(ctx, settings, logger, requestLogger)
+			reconnect = func(ctx context.Context) (connectedSink, error) {
+				transport, cleanup, err := newMQTTRequestEnqueuer(ctx, settings, logger, requestLogger)
+				if err != nil {
+					return connectedSink{}, err
+				}
+				return newConnectedSink(mode, transport, cleanup), nil
+			}
+		}
+	case EnvWinSoundEnqueuerVal05Nats:
+		transport, cleanup, err = newNATSRequestEnqueuer(ctx, logger, requestLogger)
 	default:
-		return nil, nil, fmt.Errorf("unsupported %s=%q (supported: empty, rabbitmq, kafka)", EnvWinSoundEnqueuer, mode)
+		return connectedSink{}, unsupportedEnqueuerError(mode)
+	}
+	if err != nil {
+		return connectedSink{}, err
 	}
+	sink := newConnectedSink(mode, transport, cleanup)
+	sink.reconnect = reconnect
+	return sink, nil
+}
+
+func newConnectedSink(mode string, transport enqueuer.EnqueueRequest, cleanup func()) connectedSink {
+	// Behind the outbox, every delivery attempt is measured, retries included.
+	transport = metrics.NewInstrumentedRequestEnqueuer(transport, mode)
+	return connectedSink{mode: mode, transport: transport, cleanup: cleanup, durable: durableModes[mode]}
+}
+
+// startListeners starts the Prometheus listener when WIN_SOUND_METRICS_LISTEN
+// is set and the health endpoints when either WIN_SOUND_HEALTH_LISTEN or
+// WIN_SOUND_METRICS_LISTEN is set. Without a listener of their own, or
+// with the same address, the health endpoints share the metrics listener.
+func startListeners(logger *slog.Logger) (func(), error) {
+	metricsCfg, err := metrics.LoadConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	healthCfg, err := health.LoadConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	sharedHealth := !healthCfg.Enabled() || healthCfg.ListenAddress == metricsCfg.ListenAddress
+
+	stopMetrics := func() {}
+	if metricsCfg.Enabled() {
+		var routes map[string]http.Handler
+		if sharedHealth {
+			routes = health.Routes()
+		}
+		if stopMetrics, err = metrics.Serve(metricsCfg, routes, WithComponent(logger, "metrics")); err != nil {
+			return nil, err
+		}
+	}
+	if sharedHealth {
+		return stopMetrics, nil
+	}
+	stopHealth, err := health.Serve(healthCfg, WithComponent(logger, "health"))
+	if err != nil {
+		stopMetrics()
+		return nil, err
+	}
+	return func() {
+		stopHealth()
+		stopMetrics()
+	}, nil
+}
+
+// startTracing exports spans when WIN_SOUND_OTEL_ENDPOINT is set.
+func startTracing(ctx context.Context, logger *slog.Logger) (func(), error) {
+	cfg, err := tracing.LoadConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.Enabled() {
+		return func() {}, nil
+	}
+	return tracing.Setup(ctx, cfg, WithComponent(logger, "tracing"))
+}
+
+// newOutboxEnqueuer puts the durable outbox in front of a transport enqueuer.
+// The returned function closes the outbox only; the caller closes the
+// transport afterwards, so in-flight delivery can finish.
+func newOutboxEnqueuer(sink connectedSink, cfg outbox.Config, logger, requestLogger *slog.Logger) (enqueuer.EnqueueRequest, func(), error) {
+	if !cfg.Enabled {
+		requestLogger.Info("Outbox is disabled")
+		return sink.transport, func() {}, nil
+	}
+
+	// Each sink has its own outbox, so a broken sink never blocks or
+	// duplicates delivery to the others, and the file stays where it is
+	// when sinks are added or removed.
+	cfg.Dir = outboxDir(cfg, sink)
+	cfg.BestEffort = !sink.required
+	outboxLogger := WithComponent(logger, "outbox").With("sink", sink.mode)
+
+	requestLogger.Info("Creating outbox enqueuer...")
+	box, err := outbox.New(cfg, sink.transport, outboxLogger)
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		if err := box.Close(); err != nil {
+			requestLogger.Error("Outbox close failed", "err", err)
+		}
+	}
+
+	return box, cleanup, nil
 }
 
 func newEmptyRequestEnqueuer(requestLogger *slog.Logger, logger *slog.Logger) (enqueuer.EnqueueRequest, func(), error) {
@@ -97,12 +574,46 @@ func newEmptyRequestEnqueuer(requestLogger *slog.Logger, logger *slog.Logger) (e
 	return emptyEnqueuer, func() {}, nil
 }
 
+// newFileRequestEnqueuer writes requests to local JSON Lines files. Like the
+// empty enqueuer it does not need the outbox, since the file is the durable store.
+func newFileRequestEnqueuer(logger, requestLogger *slog.Logger) (enqueuer.EnqueueRequest, func(), error) {
+	requestLogger.Info("Reading request file configuration...")
+	cfg, err := filesink.LoadConfigFromEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+	cloudEvents, err := loadCloudEventsMode(requestLogger, EnvWinSoundEnqueuerVal06File, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	requestLogger.Info("Creating request file writer...")
+	writer, err := filesink.NewRotatingWriter(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	requestLogger.Info("Request file opened", "path", writer.Path(), "maxSizeBytes", cfg.MaxSizeBytes, "rotateDaily", cfg.RotateDaily, "fsyncInterval", cfg.FsyncInterval)
+
+	reqEnqueuer := filesink.NewEnqueuer(writer, WithComponent(logger, "file_enqueuer"), cloudEvents)
+	cleanup := func() {
+		if err := reqEnqueuer.Close(); err != nil {
+			requestLogger.Error("File enqueuer close failed", "err", err)
+		}
+	}
+
+	return reqEnqueuer, cleanup, nil
+}
+
 func newRabbitMQRequestEnqueuer(ctx context.Context, logger, requestLogger *slog.Logger) (enqueuer.EnqueueRequest, func(), error) {
 	requestLogger.Info("Reading RabbitMQ configuration...")
 	cfg, err := rabbitmq.LoadConfigFromEnv()
 	if err != nil {
 		return nil, nil, err
 	}
+	cloudEvents, err := loadCloudEventsMode(requestLogger, EnvWinSoundEnqueuerVal01RabbitMq, true)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	requestLogger.Info("Creating RabbitMQ request publisher...")
 	publisher, err := rabbitmq.NewRequestPublisher(ctx, cfg, WithComponent(logger, "rabbitmq_publisher"))
@@ -111,8 +622,10 @@ func newRabbitMQRequestEnqueuer(ctx context.Context, logger, requestLogger *slog
 	}
 
 	requestLogger.Info("Creating RabbitMQ request enqueuer...")
-	reqEnqueuer := rabbitmq.NewEnqueuerWithContext(ctx, publisher, WithComponent(logger, "rabbitmq_enqueuer"))
+	reqEnqueuer := rabbitmq.NewEnqueuerWithContext(ctx, publisher, WithComponent(logger, "rabbitmq_enqueuer"), cloudEvents)
+	unregister := health.Register(EnvWinSoundEnqueuerVal01RabbitMq, publisher.Health)
 	cleanup := func() {
+		unregister()
 		if err := reqEnqueuer.Close(); err != nil {
 			requestLogger.Error("Rabbitmq enqueuer close failed", "err", err)
 		}
@@ -127,6 +640,27 @@ func newKafkaRequestEnqueuer(ctx context.Context, logger, requestLogger *slog.Lo
 	if err != nil {
 		return nil, nil, err
 	}
+	cloudEvents, err := loadCloudEventsMode(requestLogger, EnvWinSoundEnqueuerVal02Kafka, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkKafkaCloudEvents(cloudEvents, cfg); err != nil {
+		return nil, nil, err
+	}
+	serializer, err := kafkatarget.NewSerializer(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.Encoding != encoding.FormatJSON {
+		requestLogger.Info("Kafka payload encoding", "encoding", cfg.Encoding, "schemaRegistry", cfg.SchemaRegistry.URL,
+			"autoRegister", cfg.AutoRegisterSchemas)
+	}
+	if err := serializer.CheckSchemas(ctx); errors.Is(err, schemaregistry.ErrNotFound) {
+		return nil, nil, fmt.Errorf("kafka %s encoding: %w", cfg.Encoding, err)
+	} else if err != nil {
+		// An unreachable registry is retried by the outbox like the broker.
+		requestLogger.Warn("Kafka schemas could not be checked", "err", err)
+	}
 
 	requestLogger.Info("Creating Kafka request publisher...")
 	publisher, err := kafkatarget.NewRequestPublisher(cfg, WithComponent(logger, "kafka_publisher"))
@@ -135,8 +669,10 @@ func newKafkaRequestEnqueuer(ctx context.Context, logger, requestLogger *slog.Lo
 	}
 
 	requestLogger.Info("Creating Kafka request enqueuer...")
-	reqEnqueuer := kafkatarget.NewEnqueuerWithContext(ctx, publisher, WithComponent(logger, "kafka_enqueuer"), cfg.WriteTimeout)
+	reqEnqueuer := kafkatarget.NewEnqueuerWithContext(ctx, publisher, WithComponent(logger, "kafka_enqueuer"), cfg.WriteTimeout, cloudEvents, serializer)
+	unregister := health.Register(EnvWinSoundEnqueuerVal02Kafka, publisher.Health)
 	cleanup := func() {
+		unregister()
 		if err := reqEnqueuer.Close(); err != nil {
 			requestLogger.Error("Kafka enqueuer close failed", "err", err)
 		}
@@ -144,3 +680,129 @@ func newKafkaRequestEnqueuer(ctx context.Context, logger, requestLogger *slog.Lo
 
 	return reqEnqueuer, cleanup, nil
 }
+
+// checkKafkaCloudEvents rejects structured CloudEvents, which wrap the
+// payload in a JSON envelope, for a binary record encoding.
+func checkKafkaCloudEvents(mode cloudevents.Mode, cfg kafkatarget.Config) error {
+	if mode == cloudevents.ModeStructured && cfg.Encoding != encoding.FormatJSON {
+		return fmt.Errorf("CloudEvents structured mode needs %s=%s, got %s; use binary mode instead",
+			EnvWinSoundKafkaEncoding, encoding.FormatJSON, cfg.Encoding)
+	}
+	return nil
+}
+
+func newHTTPRequestEnqueuer(ctx context.Context, logger, requestLogger *slog.Logger) (enqueuer.EnqueueRequest, func(), error) {
+	requestLogger.Info("Reading REST API configuration...")
+	cfg, err := restapi.LoadConfigFromEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+	// The Device Repository REST API takes the plain payload only.
+	cloudEvents, err := cloudevents.LoadModeFromEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+	if cloudEvents != cloudevents.ModeOff {
+		requestLogger.Info("CloudEvents mode does not apply to the REST API, sending plain payloads", "mode", cloudEvents)
+	}
+
+	requestLogger.Info("Creating REST API request publisher...")
+	publisher, err := restapi.NewRequestPublisher(cfg, WithComponent(logger, "restapi_publisher"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	requestLogger.Info("Creating REST API request enqueuer...")
+	reqEnqueuer := restapi.NewEnqueuerWithContext(ctx, publisher, WithComponent(logger, "restapi_enqueuer"), cfg.RequestBudget())
+	cleanup := func() {
+		if err := reqEnqueuer.Close(); err != nil {
+			requestLogger.Error("REST API enqueuer close failed", "err", err)
+		}
+	}
+
+	return reqEnqueuer, cleanup, nil
+}
+
+// mqttSettings are kept with the MQTT sink, so a failed reload can connect
+// the previous client again after the environment changed.
+type mqttSettings struct {
+	cfg         mqtt.Config
+	cloudEvents cloudevents.Mode
+}
+
+func loadMQTTSettings(requestLogger *slog.Logger) (mqttSettings, error) {
+	requestLogger.Info("Reading MQTT configuration...")
+	cfg, err := mqtt.LoadConfigFromEnv()
+	if err != nil {
+		return mqttSettings{}, err
+	}
+	cloudEvents, err := loadCloudEventsMode(requestLogger, EnvWinSoundEnqueuerVal04Mqtt, false)
+	if err != nil {
+		return mqttSettings{}, err
+	}
+	return mqttSettings{cfg: cfg, cloudEvents: cloudEvents}, nil
+}
+
+func newMQTTRequestEnqueuer(ctx context.Context, settings mqttSettings, logger, requestLogger *slog.Logger) (enqueuer.EnqueueRequest, func(), error) {
+	cfg := settings.cfg
+	requestLogger.Info("Creating MQTT request publisher...")
+	publisher, err := mqtt.NewRequestPublisher(ctx, cfg, WithComponent(logger, "mqtt_publisher"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	requestLogger.Info("Creating MQTT request enqueuer...")
+	reqEnqueuer := mqtt.NewEnqueuerWithContext(ctx, publisher, WithComponent(logger, "mqtt_enqueuer"), cfg.Topic, cfg.PublishTimeout, settings.cloudEvents)
+	cleanup := func() {
+		if err := reqEnqueuer.Close(); err != nil {
+			requestLogger.Error("MQTT enqueuer close failed", "err", err)
+		}
+	}
+
+	return reqEnqueuer, cleanup, nil
+}
+
+func newNATSRequestEnqueuer(ctx context.Context, logger, requestLogger *slog.Logger) (enqueuer.EnqueueRequest, func(), error) {
+	requestLogger.Info("Reading NATS configuration...")
+	cfg, err := natstarget.LoadConfigFromEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+	cloudEvents, err := loadCloudEventsMode(requestLogger, EnvWinSoundEnqueuerVal05Nats, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	requestLogger.Info("Creating NATS request publisher...")
+	publisher, err := natstarget.NewRequestPublisher(ctx, cfg, WithComponent(logger, "nats_publisher"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	requestLogger.Info("Creating NATS request enqueuer...")
+	reqEnqueuer := natstarget.NewEnqueuerWithContext(ctx, publisher, WithComponent(logger, "nats_enqueuer"), cfg.SubjectPrefix, cfg.PublishTimeout, cloudEvents)
+	cleanup := func() {
+		if err := reqEnqueuer.Close(); err != nil {
+			requestLogger.Error("NATS enqueuer close failed", "err", err)
+		}
+	}
+
+	return reqEnqueuer, cleanup, nil
+}
+
+// loadCloudEventsMode reads the CloudEvents mode for one transport. Binary
+// mode needs a protocol binding; transports without one publish structured events.
+func loadCloudEventsMode(requestLogger *slog.Logger, transport string, hasBinaryBinding bool) (cloudevents.Mode, error) {
+	mode, err := cloudevents.LoadModeFromEnv()
+	if err != nil {
+		return cloudevents.ModeOff, err
+	}
+	if mode == cloudevents.ModeBinary && !hasBinaryBinding {
+		requestLogger.Warn("CloudEvents binary mode is not supported by this transport, using structured mode", "transport", transport)
+		return cloudevents.ModeStructured, nil
+	}
+	if mode != cloudevents.ModeOff {
+		requestLogger.Info("CloudEvents enabled", "transport", transport, "mode", mode)
+	}
+	return mode, nil
+}
@@ -0,0 +1,117 @@
+package scannerapp
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+func TestParseEnqueuerModes(t *testing.T) {
+	modes, bestEffort, err := parseEnqueuerModes(" RabbitMQ, kafka ,file", "file")
+	if err != nil {
+		t.Fatalf("parseEnqueuerModes failed: %v", err)
+	}
+	if len(modes) != 3 || modes[0] != "rabbitmq" || modes[1] != "kafka" || modes[2] != "file" {
+		t.Fatalf("unexpected modes: %#v", modes)
+	}
+	if !bestEffort["file"] || bestEffort["rabbitmq"] || bestEffort["kafka"] {
+		t.Fatalf("unexpected best-effort set: %#v", bestEffort)
+	}
+}
+
+func TestParseEnqueuerModes_DefaultsToRabbitMQ(t *testing.T) {
+	modes, _, err := parseEnqueuerModes("", "")
+	if err != nil {
+		t.Fatalf("parseEnqueuerModes failed: %v", err)
+	}
+	if len(modes) != 1 || modes[0] != EnvWinSoundEnqueuerVal01RabbitMq {
+		t.Fatalf("unexpected modes: %#v", modes)
+	}
+}
+
+func TestParseEnqueuerModes_Invalid(t *testing.T) {
+	cases := []struct{ modes, bestEffort string }{
+		{"rabbitmq,amqp", ""},
+		{"kafka,kafka", ""},
+		{"rabbitmq,kafka", "file"},
+		{"rabbitmq,kafka", "rabbitmq,kafka"},
+	}
+	for _, c := range cases {
+		if _, _, err := parseEnqueuerModes(c.modes, c.bestEffort); err == nil {
+			t.Fatalf("expected error for modes=%q bestEffort=%q", c.modes, c.bestEffort)
+		}
+	}
+}
+
+func TestAssembleChain_FansOutToAllSinks(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(EnvWinSoundEnqueuer, "empty,file")
+	t.Setenv(EnvWinSoundEnqueuerBestEffort, "empty")
+	t.Setenv(EnvWinSoundFileDir, dir)
+	t.Setenv(EnvWinSoundFileFsyncInterval, "0")
+	t.Setenv(EnvWinSoundSchemaValidation, "false")
+
+	cfg, err := loadChainConfig(slog.Default())
+	if err != nil {
+		t.Fatalf("loadChainConfig failed: %v", err)
+	}
+	sinks, err := connectSinks(context.Background(), cfg, slog.Default())
+	if err != nil {
+		t.Fatalf("connectSinks failed: %v", err)
+	}
+	sut, layers, err := assembleChain(cfg, sinks, slog.Default())
+	if err != nil {
+		t.Fatalf("assembleChain failed: %v", err)
+	}
+	cleanup := func() {
+		layers.close()
+		closeSinks(sinks)
+	}
+	if _, ok := sut.(*enqueuer.FanOutRequestEnqueuer); !ok {
+		t.Fatalf("expected fan-out enqueuer, got %T", sut)
+	}
+
+	err = sut.EnqueueRequest(enqueuer.Request{
+		Timestamp: time.Now(),
+		Event:     contract.EventTypeRenderVolumeChanged,
+		Fields:    map[string]string{contract.FieldPnpID: "pnp-1", contract.FieldHostName: "host-1"},
+	})
+	if err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+	cleanup()
+
+	files, err := filepath.Glob(filepath.Join(dir, "requests-*.jsonl"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one request file, got %v (err %v)", files, err)
+	}
+	if info, err := os.Stat(files[0]); err != nil || info.Size() == 0 {
+		t.Fatalf("expected request line in %s", files[0])
+	}
+}
+
+func TestAdoptRootOutbox_MovesFileToFirstOutboxedSink(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "outbox.wal"), []byte("{}\n"), 0o600); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	adoptRootOutbox([]connectedSink{
+		{mode: EnvWinSoundEnqueuerVal06File, durable: true},
+		{mode: EnvWinSoundEnqueuerVal01RabbitMq},
+		{mode: EnvWinSoundEnqueuerVal02Kafka},
+	}, dir, slog.Default())
+
+	if _, err := os.Stat(filepath.Join(dir, "outbox.wal")); !os.IsNotExist(err) {
+		t.Fatalf("expected root outbox file to be moved, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, EnvWinSoundEnqueuerVal01RabbitMq, "outbox.wal")); err != nil {
+		t.Fatalf("expected outbox file in the rabbitmq directory: %v", err)
+	}
+}
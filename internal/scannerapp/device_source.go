@@ -0,0 +1,89 @@
+package scannerapp
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/health"
+)
+
+// DeviceDescription describes a default audio device as reported by a DeviceSource.
+type DeviceDescription struct {
+	Name          string
+	PnpID         string
+	RenderVolume  int
+	CaptureVolume int
+}
+
+// DeviceHandlers receives the notifications of a DeviceSource.
+// Handlers may be called on arbitrary threads; nil handlers are skipped.
+type DeviceHandlers struct {
+	Log                  func(timestamp, level, content string)
+	DefaultRender        func(present bool)
+	DefaultCapture       func(present bool)
+	RenderVolumeChanged  func()
+	CaptureVolumeChanged func()
+}
+
+// DeviceSource abstracts the sound library the scanner reads devices from.
+// SetHandlers is called before Initialize; notifications start after Initialize.
+// Initialized reports whether the source is between Initialize and Close; it
+// may be called from any goroutine.
+type DeviceSource interface {
+	SetHandlers(handlers DeviceHandlers)
+	Initialize(appName, version string) error
+	Initialized() bool
+	OperatingSystemName() (string, error)
+	DefaultRender() (DeviceDescription, error)
+	DefaultCapture() (DeviceDescription, error)
+	Close() error
+}
+
+// newDeviceSource selects the device source from WIN_SOUND_SOURCE.
+func newDeviceSource() (DeviceSource, error) {
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv(EnvWinSoundSource)))
+	if mode == "" {
+		mode = defaultDeviceSourceMode
+	}
+
+	switch mode {
+	case EnvWinSoundSourceVal00SoundLib:
+		return newSoundLibDeviceSource()
+	case EnvWinSoundSourceVal01Simulated:
+		return NewSimulatedDeviceSource(defaultSimulatedRender, defaultSimulatedCapture), nil
+	case EnvWinSoundSourceVal02Scenario:
+		return newScenarioDeviceSourceFromEnv()
+	default:
+		return nil, unsupportedDeviceSourceError(mode)
+	}
+}
+
+// healthDeviceSource names the device source in the readiness report.
+const healthDeviceSource = "deviceSource"
+
+// deviceSourceCheck reports the source as ready between Initialize and Close.
+func deviceSourceCheck(source DeviceSource) health.Check {
+	return func() health.Component {
+		if !source.Initialized() {
+			return health.Down("not initialized")
+		}
+		return health.Up("initialized")
+	}
+}
+
+func unsupportedDeviceSourceError(mode string) error {
+	return fmt.Errorf("unsupported %s=%q (supported: soundlib, simulated, scenario)", EnvWinSoundSource, mode)
+}
+
+func newScenarioDeviceSourceFromEnv() (DeviceSource, error) {
+	path := strings.TrimSpace(os.Getenv(EnvWinSoundScenarioFile))
+	if path == "" {
+		return nil, fmt.Errorf("%s=%s requires %s", EnvWinSoundSource, EnvWinSoundSourceVal02Scenario, EnvWinSoundScenarioFile)
+	}
+	scenario, err := LoadScenario(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewScenarioDeviceSource(scenario), nil
+}
@@ -0,0 +1,261 @@
+package scannerapp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+// ReloadRequest asks Run to rebuild the request enqueuer chain from the
+// current environment. The result is sent to Done, which must be buffered.
+//
+// Only the chain is rebuilt: the device source, the volume coalescer and the
+// request queue keep running, so no device state is lost. Settings read by
+// those (see config.Setting.StartupOnly) take effect after a restart.
+type ReloadRequest struct {
+	Done chan<- error
+}
+
+// enqueuerChain is the replaceable part of the pipeline: schema validation,
+// fan-out, the outboxes and the transports.
+type enqueuerChain struct {
+	logger  *slog.Logger
+	current *enqueuer.SwappableRequestEnqueuer
+
+	// The rest is only used by the reload loop and close: the configuration
+	// and transports of the current chain and the validation and outboxes
+	// in front of them.
+	cfg    chainConfig
+	sinks  []connectedSink
+	layers chainLayers
+}
+
+// reloadDrainTimeout bounds how long a reload waits for the outbox of a
+// dropped sink while new requests are held back.
+const reloadDrainTimeout = 10 * time.Second
+
+func newEnqueuerChain(ctx context.Context, logger *slog.Logger) (*enqueuerChain, error) {
+	cfg, err := loadChainConfig(logger)
+	if err != nil {
+		return nil, err
+	}
+	sinks, err := connectSinks(ctx, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	next, layers, err := assembleChain(cfg, sinks, logger)
+	if err != nil {
+		closeSinks(sinks)
+		return nil, err
+	}
+	return &enqueuerChain{
+		logger:  logger,
+		current: enqueuer.NewSwappableRequestEnqueuer(next),
+		cfg:     cfg,
+		sinks:   sinks,
+		layers:  layers,
+	}, nil
+}
+
+// assembleChain puts schema validation in front of the assembled sinks. The
+// returned layers close validation and the outboxes, not the transports.
+func assembleChain(cfg chainConfig, sinks []connectedSink, logger *slog.Logger) (enqueuer.EnqueueRequest, chainLayers, error) {
+	next, layers, err := assembleSinks(sinks, cfg.outbox, logger)
+	if err != nil {
+		return nil, chainLayers{}, err
+	}
+	next, closeValidation, err := newValidatingEnqueuer(next, cfg.validation, logger)
+	if err != nil {
+		layers.close()
+		return nil, chainLayers{}, err
+	}
+	closeOutboxes := layers.close
+	layers.close = func() {
+		closeValidation()
+		closeOutboxes()
+	}
+	return next, layers, nil
+}
+
+// reload connects the transports of the new configuration while the old
+// chain keeps delivering; a broker that cannot be reached leaves the old
+// chain in place. The swap then holds back new requests, waits for the
+// in-flight ones and closes the old outboxes before the new ones open the
+// same files and replay what the old ones had not delivered yet. Outboxes
+// the new chain does not open again are drained first. When the new
+// outboxes cannot be opened, the old ones are reopened over the old
+// transports, which are only closed once the new chain runs.
+func (c *enqueuerChain) reload(ctx context.Context) error {
+	appLogger := WithComponent(c.logger, "application-root")
+	appLogger.Info("Reloading. Creating request enqueuer.")
+	cfg, err := loadChainConfig(c.logger)
+	if err != nil {
+		return err
+	}
+	var closedAhead []string
+	if slices.Contains(cfg.modes, EnvWinSoundEnqueuerVal04Mqtt) && c.closeSink(EnvWinSoundEnqueuerVal04Mqtt) {
+		// Two clients with the same client id take the session from each
+		// other, and the old one would send its retained "offline" status
+		// after the new one announced "online". The old outbox keeps the
+		// requests until the swap or, if the reload fails, until the old
+		// client is connected again.
+		closedAhead = append(closedAhead, EnvWinSoundEnqueuerVal04Mqtt)
+	}
+	sinks, err := connectSinks(ctx, cfg, c.logger)
+	if err != nil {
+		c.restoreSinks(ctx, closedAhead)
+		return err
+	}
+
+	var assembleErr error
+	err = c.current.Swap(func() (enqueuer.EnqueueRequest, error) {
+		c.drainDropped(ctx, cfg, sinks)
+		c.layers.close()
+		next, layers, err := assembleChain(cfg, sinks, c.logger)
+		if err == nil {
+			c.layers = layers
+			return next, nil
+		}
+		assembleErr = err
+		next, layers, err = assembleChain(c.cfg, c.sinks, c.logger)
+		if err != nil {
+			c.layers = noLayers()
+			return nil, fmt.Errorf("%w (reopen previous request enqueuer: %w)", assembleErr, err)
+		}
+		c.layers = layers
+		return next, nil
+	})
+	if err == nil && assembleErr != nil {
+		err = assembleErr
+	}
+	if err != nil {
+		closeSinks(sinks)
+		c.restoreSinks(ctx, closedAhead)
+		return err
+	}
+
+	closeSinks(c.sinks)
+	c.cfg, c.sinks = cfg, sinks
+	appLogger.Info("Request enqueuer replaced")
+	return nil
+}
+
+// closeSink closes the transport of mode ahead of the rest of the chain and
+// reports whether the chain had one.
+func (c *enqueuerChain) closeSink(mode string) bool {
+	closed := false
+	for i := range c.sinks {
+		if c.sinks[i].mode == mode {
+			c.sinks[i].cleanup()
+			c.sinks[i].cleanup = func() {}
+			closed = true
+		}
+	}
+	return closed
+}
+
+// restoreSinks connects the transports closeSink closed again after a
+// failed reload and puts the current outboxes in front of them.
+func (c *enqueuerChain) restoreSinks(ctx context.Context, modes []string) {
+	if len(modes) == 0 {
+		return
+	}
+	requestLogger := WithComponent(c.logger, "dispatch_enqueuer")
+	sinks := slices.Clone(c.sinks)
+	for _, mode := range modes {
+		for i := range sinks {
+			if sinks[i].mode != mode || sinks[i].reconnect == nil {
+				continue
+			}
+			sink, err := sinks[i].reconnect(ctx)
+			if err != nil {
+				requestLogger.Error("Previous transport could not be connected again; its outbox keeps the requests until the next reload",
+					"sink", mode, "err", err)
+				continue
+			}
+			sink.required = sinks[i].required
+			sink.reconnect = sinks[i].reconnect
+			sinks[i] = sink
+		}
+	}
+
+	err := c.current.Swap(func() (enqueuer.EnqueueRequest, error) {
+		c.layers.close()
+		next, layers, err := assembleChain(c.cfg, sinks, c.logger)
+		if err != nil {
+			c.layers = noLayers()
+			return nil, fmt.Errorf("reopen previous request enqueuer: %w", err)
+		}
+		c.layers = layers
+		return next, nil
+	})
+	c.sinks = sinks
+	if err != nil {
+		requestLogger.Error("Previous request enqueuer could not be restored", "err", err)
+	}
+}
+
+// drainDropped waits for the outboxes the new chain does not open again,
+// because their sink was removed or the outbox directory changed, so their
+// requests are not left behind in a file nobody reads.
+func (c *enqueuerChain) drainDropped(ctx context.Context, cfg chainConfig, sinks []connectedSink) {
+	kept := make(map[string]bool, len(sinks))
+	for _, sink := range sinks {
+		kept[outboxDir(cfg.outbox, sink)] = true
+	}
+	requestLogger := WithComponent(c.logger, "dispatch_enqueuer")
+	for _, sink := range c.sinks {
+		box, ok := c.layers.outboxes[sink.mode]
+		dir := outboxDir(c.cfg.outbox, sink)
+		if !ok || kept[dir] {
+			continue
+		}
+		requestLogger.Info("Draining the outbox of a dropped sink", "sink", sink.mode, "pending", box.Pending())
+		drainCtx, cancel := context.WithTimeout(ctx, reloadDrainTimeout)
+		pending := box.Drain(drainCtx)
+		cancel()
+		if pending > 0 {
+			requestLogger.Warn("Outbox of a dropped sink still holds requests; they are delivered once the sink is configured again",
+				"sink", sink.mode, "pending", pending, "dir", dir)
+		}
+	}
+}
+
+// serveReloads handles reload requests until ctx is done or the returned
+// stop function is called.
+func (c *enqueuerChain) serveReloads(ctx context.Context, reload <-chan ReloadRequest) (stop func()) {
+	quit := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-quit:
+				return
+			case req, ok := <-reload:
+				if !ok {
+					return
+				}
+				err := c.reload(ctx)
+				if req.Done != nil {
+					req.Done <- err
+				}
+			}
+		}
+	}()
+	return func() {
+		close(quit)
+		<-stopped
+	}
+}
+
+func (c *enqueuerChain) close() {
+	c.layers.close()
+	closeSinks(c.sinks)
+}
@@ -0,0 +1,214 @@
+package scannerapp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/outbox"
+)
+
+func volumeRequest(volume string) enqueuer.Request {
+	return enqueuer.Request{
+		Timestamp: time.Now(),
+		Event:     contract.EventTypeRenderVolumeChanged,
+		Fields:    map[string]string{contract.FieldPnpID: "pnp-1", contract.FieldHostName: "host-1", contract.FieldVolume: volume},
+	}
+}
+
+func requestFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "requests-*.jsonl"))
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	return files
+}
+
+func TestEnqueuerChain_ReloadSwapsToNewConfiguration(t *testing.T) {
+	clearSettings(t)
+	first, second := t.TempDir(), t.TempDir()
+	t.Setenv(EnvWinSoundEnqueuer, EnvWinSoundEnqueuerVal06File)
+	t.Setenv(EnvWinSoundSchemaValidation, "false")
+	t.Setenv(EnvWinSoundFileFsyncInterval, "0")
+	t.Setenv(EnvWinSoundFileDir, first)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	chain, err := newEnqueuerChain(ctx, slog.Default())
+	if err != nil {
+		t.Fatalf("newEnqueuerChain failed: %v", err)
+	}
+	reload := make(chan ReloadRequest)
+	stop := chain.serveReloads(ctx, reload)
+	if err := chain.current.EnqueueRequest(volumeRequest("10")); err != nil {
+		t.Fatalf("EnqueueRequest failed: %v", err)
+	}
+
+	t.Setenv(EnvWinSoundFileDir, second)
+	done := make(chan error, 1)
+	reload <- ReloadRequest{Done: done}
+	if err := <-done; err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if err := chain.current.EnqueueRequest(volumeRequest("20")); err != nil {
+		t.Fatalf("EnqueueRequest after reload failed: %v", err)
+	}
+
+	// An invalid configuration keeps the running chain.
+	t.Setenv(EnvWinSoundEnqueuer, "carrier-pigeon")
+	reload <- ReloadRequest{Done: done}
+	if err := <-done; err == nil {
+		t.Fatal("expected the reload to fail")
+	}
+	if err := chain.current.EnqueueRequest(volumeRequest("30")); err != nil {
+		t.Fatalf("EnqueueRequest after failed reload failed: %v", err)
+	}
+	stop()
+	chain.close()
+
+	if len(requestFiles(t, first)) != 1 || len(requestFiles(t, second)) != 1 {
+		t.Fatalf("expected one request file per directory, got %v and %v", requestFiles(t, first), requestFiles(t, second))
+	}
+}
+
+func TestEnqueuerChain_FailedReloadKeepsDeliveringToOldSink(t *testing.T) {
+	clearSettings(t)
+	first, second := t.TempDir(), t.TempDir()
+	t.Setenv(EnvWinSoundEnqueuer, EnvWinSoundEnqueuerVal06File)
+	t.Setenv(EnvWinSoundSchemaValidation, "false")
+	t.Setenv(EnvWinSoundFileFsyncInterval, "0")
+	t.Setenv(EnvWinSoundFileDir, first)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	chain, err := newEnqueuerChain(ctx, slog.Default())
+	if err != nil {
+		t.Fatalf("newEnqueuerChain failed: %v", err)
+	}
+	defer chain.close()
+
+	// The new sink connects, but the rejected events log cannot be opened
+	// below a regular file, so assembling the new chain fails.
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatalf("write blocker: %v", err)
+	}
+	t.Setenv(EnvWinSoundFileDir, second)
+	t.Setenv(EnvWinSoundSchemaValidation, "true")
+	t.Setenv(EnvWinSoundRejectedDir, filepath.Join(blocker, "rejected"))
+	if err := chain.reload(ctx); err == nil {
+		t.Fatal("expected the reload to fail")
+	}
+
+	if err := chain.current.EnqueueRequest(volumeRequest("10")); err != nil {
+		t.Fatalf("EnqueueRequest after failed reload failed: %v", err)
+	}
+	files := requestFiles(t, first)
+	if len(files) != 1 {
+		t.Fatalf("expected one request file in the old directory, got %v", files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("read request file: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 1 {
+		t.Fatalf("expected one request in the old file, got %d", lines)
+	}
+}
+
+// flakyTransport fails the first requests, like a broker that comes back.
+type flakyTransport struct {
+	mu       sync.Mutex
+	failures int
+	received int
+}
+
+func (f *flakyTransport) EnqueueRequest(enqueuer.Request) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("broker down")
+	}
+	f.received++
+	return nil
+}
+
+func (f *flakyTransport) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.received
+}
+
+func TestEnqueuerChain_ReloadDrainsOutboxOfDroppedSink(t *testing.T) {
+	outboxCfg := outbox.Config{Enabled: true, Dir: t.TempDir(), RetryInitialDelay: time.Millisecond, RetryMaxDelay: time.Millisecond}
+	dropped := &flakyTransport{failures: 3}
+	sinks := []connectedSink{
+		{mode: EnvWinSoundEnqueuerVal03Http, transport: dropped, cleanup: func() {}, required: true},
+		{mode: EnvWinSoundEnqueuerVal04Mqtt, transport: &flakyTransport{failures: 1000}, cleanup: func() {}, required: true},
+	}
+	_, layers, err := assembleSinks(sinks, outboxCfg, slog.Default())
+	if err != nil {
+		t.Fatalf("assembleSinks failed: %v", err)
+	}
+	defer layers.close()
+	for _, box := range layers.outboxes {
+		if err := box.EnqueueRequest(volumeRequest("10")); err != nil {
+			t.Fatalf("EnqueueRequest failed: %v", err)
+		}
+	}
+	chain := &enqueuerChain{logger: slog.Default(), cfg: chainConfig{outbox: outboxCfg}, sinks: sinks, layers: layers}
+
+	chain.drainDropped(context.Background(), chainConfig{outbox: outboxCfg}, sinks[1:])
+
+	if dropped.count() != 1 || layers.outboxes[EnvWinSoundEnqueuerVal03Http].Pending() != 0 {
+		t.Fatalf("expected the dropped outbox to be drained, got %d delivered", dropped.count())
+	}
+	if pending := layers.outboxes[EnvWinSoundEnqueuerVal04Mqtt].Pending(); pending != 1 {
+		t.Fatalf("expected the kept outbox to be left alone, got %d pending", pending)
+	}
+}
+
+func TestEnqueuerChain_RestoreSinksReconnectsClosedTransport(t *testing.T) {
+	clearSettings(t)
+	t.Setenv(EnvWinSoundEnqueuer, EnvWinSoundEnqueuerVal06File)
+	t.Setenv(EnvWinSoundSchemaValidation, "false")
+	t.Setenv(EnvWinSoundFileFsyncInterval, "0")
+	t.Setenv(EnvWinSoundFileDir, t.TempDir())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	chain, err := newEnqueuerChain(ctx, slog.Default())
+	if err != nil {
+		t.Fatalf("newEnqueuerChain failed: %v", err)
+	}
+	defer chain.close()
+	reconnected := &flakyTransport{}
+	chain.sinks[0].reconnect = func(context.Context) (connectedSink, error) {
+		return newConnectedSink(EnvWinSoundEnqueuerVal06File, reconnected, func() {}), nil
+	}
+
+	if !chain.closeSink(EnvWinSoundEnqueuerVal06File) {
+		t.Fatal("expected the file sink to be closed")
+	}
+	chain.restoreSinks(ctx, []string{EnvWinSoundEnqueuerVal06File})
+
+	if err := chain.current.EnqueueRequest(volumeRequest("10")); err != nil {
+		t.Fatalf("EnqueueRequest after restore failed: %v", err)
+	}
+	if reconnected.count() != 1 {
+		t.Fatalf("expected the request on the reconnected transport, got %d", reconnected.count())
+	}
+	if chain.sinks[0].reconnect == nil {
+		t.Fatal("expected the restored sink to keep its reconnect function")
+	}
+}
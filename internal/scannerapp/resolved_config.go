@@ -0,0 +1,384 @@
+package scannerapp
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/cloudevents"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/filesink"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/health"
+	kafkatarget "github.com/collect-sound-devices/win-sound-scanner-go/internal/kafka"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/metrics"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/mqtt"
+	natstarget "github.com/collect-sound-devices/win-sound-scanner-go/internal/nats"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/outbox"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/pipeline"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/rabbitmq"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/restapi"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/schema"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/tracing"
+)
+
+// ResolvedSetting is one setting as Run would use it, defaults and
+// fallbacks applied. Key is the configuration file key, see Settings.
+type ResolvedSetting struct {
+	Key   string
+	Value string
+	// DerivedFrom is the key the value was computed from when the setting
+	// itself does not decide it, e.g. rabbitmq.port from "host:port" in
+	// rabbitmq.host.
+	DerivedFrom string
+}
+
+// ResolveConfig loads the configuration of the device source, the pipeline
+// and the selected enqueuers from the environment with the same loaders as
+// Run, without connecting anywhere. It returns what could be resolved
+// together with every problem found, not only the first one.
+func ResolveConfig() ([]ResolvedSetting, error) {
+	r := &resolver{}
+	r.source()
+	modes := r.enqueuers()
+	r.pipeline()
+	if needsOutbox(modes) {
+		r.outbox()
+	}
+	for _, mode := range modes {
+		switch mode {
+		case EnvWinSoundEnqueuerVal01RabbitMq:
+			r.rabbitMQ()
+		case EnvWinSoundEnqueuerVal02Kafka:
+			r.kafka()
+		case EnvWinSoundEnqueuerVal03Http:
+			r.http()
+		case EnvWinSoundEnqueuerVal04Mqtt:
+			r.mqtt()
+		case EnvWinSoundEnqueuerVal05Nats:
+			r.nats()
+		case EnvWinSoundEnqueuerVal06File:
+			r.file()
+		}
+	}
+	return r.settings, errors.Join(r.errs...)
+}
+
+// resolver collects the resolved settings and the problems of all sections.
+type resolver struct {
+	settings []ResolvedSetting
+	errs     []error
+}
+
+func (r *resolver) add(key, value string) {
+	r.settings = append(r.settings, ResolvedSetting{Key: key, Value: value})
+}
+
+func (r *resolver) addDerived(key, value, from string) {
+	r.settings = append(r.settings, ResolvedSetting{Key: key, Value: value, DerivedFrom: from})
+}
+
+func (r *resolver) fail(err error) {
+	r.errs = append(r.errs, err)
+}
+
+func (r *resolver) source() {
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv(EnvWinSoundSource)))
+	if mode == "" {
+		mode = defaultDeviceSourceMode
+	}
+	r.add("source", mode)
+	switch mode {
+	case EnvWinSoundSourceVal00SoundLib, EnvWinSoundSourceVal01Simulated:
+	case EnvWinSoundSourceVal02Scenario:
+		path := strings.TrimSpace(os.Getenv(EnvWinSoundScenarioFile))
+		r.add("scenarioFile", path)
+		if _, err := newScenarioDeviceSourceFromEnv(); err != nil {
+			r.fail(err)
+		}
+	default:
+		r.fail(unsupportedDeviceSourceError(mode))
+	}
+}
+
+func (r *resolver) enqueuers() []string {
+	modes, bestEffort, err := parseEnqueuerModes(os.Getenv(EnvWinSoundEnqueuer), os.Getenv(EnvWinSoundEnqueuerBestEffort))
+	if err != nil {
+		r.fail(err)
+		return nil
+	}
+	r.add("enqueuer", strings.Join(modes, ","))
+	if len(modes) > 1 {
+		var names []string
+		for _, mode := range modes {
+			if bestEffort[mode] {
+				names = append(names, mode)
+			}
+		}
+		r.add("enqueuerBestEffort", strings.Join(names, ","))
+	}
+
+	mode, err := cloudevents.LoadModeFromEnv()
+	if err != nil {
+		r.fail(err)
+		return modes
+	}
+	r.add("cloudEventsMode", string(mode))
+	return modes
+}
+
+func (r *resolver) pipeline() {
+	if cfg, err := schema.LoadConfigFromEnv(); err != nil {
+		r.fail(err)
+	} else {
+		r.add("schema.validation", strconv.FormatBool(cfg.Enabled))
+		if cfg.Enabled {
+			r.add("schema.rejectedDir", cfg.RejectedDir)
+		}
+	}
+
+	if cfg, err := pipeline.LoadQueueConfigFromEnv(); err != nil {
+		r.fail(err)
+	} else {
+		r.add("queue.capacity", strconv.Itoa(cfg.Capacity))
+		r.add("queue.overflow", string(cfg.Overflow))
+	}
+
+	if cfg, err := pipeline.LoadCoalesceConfigFromEnv(); err != nil {
+		r.fail(err)
+	} else {
+		r.add("volume.settleMs", millis(cfg.SettleWindow))
+		r.add("volume.maxDelayMs", millis(cfg.MaxDelay))
+	}
+
+	metricsCfg, metricsErr := metrics.LoadConfigFromEnv()
+	if metricsErr != nil {
+		r.fail(metricsErr)
+	} else {
+		r.add("metrics.listen", metricsCfg.ListenAddress)
+	}
+	if cfg, err := health.LoadConfigFromEnv(); err != nil {
+		r.fail(err)
+	} else {
+		r.add("health.listen", cfg.ListenAddress)
+		if metricsErr == nil {
+			// Without a listener of their own the health endpoints are
+			// served by the metrics listener.
+			endpoints, from := "off", "metrics.listen"
+			if cfg.Enabled() {
+				from = "health.listen"
+			}
+			if cfg.Enabled() || metricsCfg.Enabled() {
+				endpoints = health.LivePath + "," + health.ReadyPath
+			}
+			r.addDerived("health.endpoints", endpoints, from)
+		}
+	}
+
+	if cfg, err := tracing.LoadConfigFromEnv(); err != nil {
+		r.fail(err)
+	} else {
+		r.add("otel.endpoint", cfg.Endpoint)
+	}
+}
+
+// needsOutbox reports whether any of the modes is put behind the outbox,
+// see connectSink.
+func needsOutbox(modes []string) bool {
+	for _, mode := range modes {
+		if !durableModes[mode] {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *resolver) outbox() {
+	cfg, err := outbox.LoadConfigFromEnv()
+	if err != nil {
+		r.fail(err)
+		return
+	}
+	r.add("outbox.enabled", strconv.FormatBool(cfg.Enabled))
+	if !cfg.Enabled {
+		return
+	}
+	r.add("outbox.dir", cfg.Dir)
+	r.add("outbox.retryInitialDelayMs", millis(cfg.RetryInitialDelay))
+	r.add("outbox.retryMaxDelayMs", millis(cfg.RetryMaxDelay))
+	r.add("outbox.bestEffortMaxAttempts", strconv.Itoa(cfg.BestEffortMaxAttempts))
+}
+
+func (r *resolver) rabbitMQ() {
+	cfg, err := rabbitmq.LoadConfigFromEnv()
+	if err != nil {
+		r.fail(err)
+		return
+	}
+	r.add("rabbitmq.host", cfg.Host)
+	if portFromHost(os.Getenv(EnvWinSoundRabbitMQHost), os.Getenv(EnvWinSoundRabbitMQPort), cfg.Port) {
+		r.addDerived("rabbitmq.port", strconv.Itoa(cfg.Port), "rabbitmq.host")
+	} else {
+		r.add("rabbitmq.port", strconv.Itoa(cfg.Port))
+	}
+	r.add("rabbitmq.vhost", cfg.VHost)
+	r.add("rabbitmq.user", cfg.User)
+	r.add("rabbitmq.password", cfg.Password)
+	r.add("rabbitmq.exchange", cfg.ExchangeName)
+	r.add("rabbitmq.queue", cfg.QueueName)
+	r.add("rabbitmq.routingKey", cfg.RoutingKey)
+	r.add("rabbitmq.authMechanism", string(cfg.AuthMechanism))
+	r.add("rabbitmq.connectionThresholdSec", strconv.Itoa(int(cfg.ConnectionThreshold/time.Second)))
+	r.add("rabbitmq.maxReconnectAttempts", strconv.Itoa(cfg.MaxReconnectionAttempts))
+	r.add("rabbitmq.initialReconnectDelayMs", millis(cfg.InitialReconnectDelay))
+	// withDefaults raises the maximum delay to the initial one.
+	if cfg.MaxReconnectDelay == cfg.InitialReconnectDelay && os.Getenv(EnvWinSoundRabbitMQRetryMax) != millis(cfg.MaxReconnectDelay) {
+		r.addDerived("rabbitmq.maxReconnectDelayMs", millis(cfg.MaxReconnectDelay), "rabbitmq.initialReconnectDelayMs")
+	} else {
+		r.add("rabbitmq.maxReconnectDelayMs", millis(cfg.MaxReconnectDelay))
+	}
+	r.add("rabbitmq.publishConfirmTimeoutMs", millis(cfg.PublishConfirmTimeout))
+	r.add("rabbitmq.tls.enabled", strconv.FormatBool(cfg.TLSEnabled))
+	if cfg.TLSEnabled {
+		r.add("rabbitmq.tls.caFile", cfg.TLS.CAFile)
+		r.add("rabbitmq.tls.certFile", cfg.TLS.CertFile)
+		r.add("rabbitmq.tls.keyFile", cfg.TLS.KeyFile)
+		r.add("rabbitmq.tls.serverName", cfg.TLS.ServerName)
+	}
+}
+
+// portFromHost reports whether the port was taken from a "host:port"
+// host rather than from the port setting.
+func portFromHost(rawHost, rawPort string, port int) bool {
+	_, hostPort, err := net.SplitHostPort(strings.TrimSpace(rawHost))
+	if err != nil {
+		return false
+	}
+	return hostPort == strconv.Itoa(port) && strings.TrimSpace(rawPort) != hostPort
+}
+
+func (r *resolver) kafka() {
+	cfg, err := kafkatarget.LoadConfigFromEnv()
+	if err != nil {
+		r.fail(err)
+		return
+	}
+	r.add("kafka.brokers", strings.Join(cfg.Brokers, ","))
+	r.add("kafka.topic", cfg.Topic)
+	r.add("kafka.clientId", cfg.ClientID)
+	r.add("kafka.writeTimeoutMs", millis(cfg.WriteTimeout))
+	r.add("kafka.healthWindowMs", millis(cfg.HealthWindow))
+	r.add("kafka.tls.enabled", strconv.FormatBool(cfg.TLSEnabled))
+	if cfg.TLSEnabled {
+		r.add("kafka.tls.caFile", cfg.TLS.CAFile)
+		r.add("kafka.tls.certFile", cfg.TLS.CertFile)
+		r.add("kafka.tls.keyFile", cfg.TLS.KeyFile)
+		r.add("kafka.tls.insecureSkipVerify", strconv.FormatBool(cfg.TLS.InsecureSkipVerify))
+	}
+	r.add("kafka.sasl.mechanism", string(cfg.SASLMechanism))
+	if cfg.SASLMechanism != kafkatarget.SASLNone {
+		r.add("kafka.sasl.username", cfg.SASLUsername)
+		r.add("kafka.sasl.password", cfg.SASLPassword)
+	}
+	r.add("kafka.encoding", string(cfg.Encoding))
+	if cfg.SchemaRegistry.URL != "" {
+		r.add("kafka.schemaRegistry.url", cfg.SchemaRegistry.URL)
+		r.add("kafka.schemaRegistry.username", cfg.SchemaRegistry.Username)
+		r.add("kafka.schemaRegistry.password", cfg.SchemaRegistry.Password)
+		r.add("kafka.schemaRegistry.autoRegister", strconv.FormatBool(cfg.AutoRegisterSchemas))
+	}
+
+	if mode, err := cloudevents.LoadModeFromEnv(); err == nil {
+		if err := checkKafkaCloudEvents(mode, cfg); err != nil {
+			r.fail(err)
+		}
+	}
+}
+
+func (r *resolver) http() {
+	cfg, err := restapi.LoadConfigFromEnv()
+	if err != nil {
+		r.fail(err)
+		return
+	}
+	r.add("http.baseUrl", cfg.BaseURL)
+	r.add("http.timeoutMs", millis(cfg.Timeout))
+	r.add("http.maxRetries", strconv.Itoa(cfg.MaxRetries))
+	r.add("http.initialRetryDelayMs", millis(cfg.InitialRetryDelay))
+	r.add("http.maxRetryDelayMs", millis(cfg.MaxRetryDelay))
+	r.add("http.headers", formatHeaders(cfg.Headers))
+}
+
+// formatHeaders writes headers in the "Name: value; Name: value" form the
+// setting accepts, sorted by name.
+func formatHeaders(headers http.Header) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var pairs []string
+	for _, name := range names {
+		for _, value := range headers[name] {
+			pairs = append(pairs, name+": "+value)
+		}
+	}
+	return strings.Join(pairs, "; ")
+}
+
+func (r *resolver) mqtt() {
+	cfg, err := mqtt.LoadConfigFromEnv()
+	if err != nil {
+		r.fail(err)
+		return
+	}
+	r.add("mqtt.broker", cfg.Broker)
+	r.add("mqtt.clientId", cfg.ClientID)
+	r.add("mqtt.user", cfg.User)
+	r.add("mqtt.password", cfg.Password)
+	r.add("mqtt.protocolVersion", cfg.ProtocolVersion.String())
+	r.add("mqtt.topic", string(cfg.Topic))
+	r.add("mqtt.statusTopic", string(cfg.StatusTopic))
+	r.add("mqtt.qos", strconv.Itoa(int(cfg.QoS)))
+	r.add("mqtt.retained", strconv.FormatBool(cfg.Retained))
+	r.add("mqtt.connectTimeoutMs", millis(cfg.ConnectTimeout))
+	r.add("mqtt.publishTimeoutMs", millis(cfg.PublishTimeout))
+}
+
+func (r *resolver) nats() {
+	cfg, err := natstarget.LoadConfigFromEnv()
+	if err != nil {
+		r.fail(err)
+		return
+	}
+	r.add("nats.url", cfg.URL)
+	r.add("nats.user", cfg.User)
+	r.add("nats.password", cfg.Password)
+	r.add("nats.clientName", cfg.ClientName)
+	r.add("nats.subjectPrefix", cfg.SubjectPrefix)
+	r.add("nats.jetStream", strconv.FormatBool(cfg.JetStream))
+	if cfg.JetStream {
+		r.add("nats.stream", cfg.Stream)
+	}
+	r.add("nats.publishTimeoutMs", millis(cfg.PublishTimeout))
+}
+
+func (r *resolver) file() {
+	cfg, err := filesink.LoadConfigFromEnv()
+	if err != nil {
+		r.fail(err)
+		return
+	}
+	r.add("file.dir", cfg.Dir)
+	r.add("file.maxSizeMb", strconv.FormatInt(cfg.MaxSizeBytes>>20, 10))
+	r.add("file.rotateDaily", strconv.FormatBool(cfg.RotateDaily))
+	r.add("file.fsyncIntervalMs", millis(cfg.FsyncInterval))
+}
+
+// millis formats a duration in the milliseconds the *Ms settings take.
+func millis(d time.Duration) string {
+	return strconv.FormatInt(d.Milliseconds(), 10)
+}
@@ -0,0 +1,95 @@
+package scannerapp
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// clearSettings unsets every configurable variable for the test.
+func clearSettings(t *testing.T) {
+	t.Helper()
+	for _, s := range Settings() {
+		t.Setenv(s.Env, "")
+		if err := os.Unsetenv(s.Env); err != nil {
+			t.Fatalf("unset %s: %v", s.Env, err)
+		}
+	}
+}
+
+func resolvedByKey(settings []ResolvedSetting) map[string]ResolvedSetting {
+	byKey := make(map[string]ResolvedSetting, len(settings))
+	for _, s := range settings {
+		byKey[s.Key] = s
+	}
+	return byKey
+}
+
+func TestResolveConfig_AppliesDefaultsAndHostPort(t *testing.T) {
+	clearSettings(t)
+	t.Setenv("ProgramData", t.TempDir())
+	t.Setenv(EnvWinSoundSource, EnvWinSoundSourceVal01Simulated)
+	t.Setenv(EnvWinSoundRabbitMQHost, "broker:5673")
+
+	settings, err := ResolveConfig()
+	if err != nil {
+		t.Fatalf("ResolveConfig failed: %v", err)
+	}
+	byKey := resolvedByKey(settings)
+	if byKey["enqueuer"].Value != EnvWinSoundEnqueuerVal01RabbitMq {
+		t.Fatalf("expected the default enqueuer, got %+v", byKey["enqueuer"])
+	}
+	if host := byKey["rabbitmq.host"]; host.Value != "broker" || host.DerivedFrom != "" {
+		t.Fatalf("unexpected host %+v", host)
+	}
+	if port := byKey["rabbitmq.port"]; port.Value != "5673" || port.DerivedFrom != "rabbitmq.host" {
+		t.Fatalf("expected port derived from host, got %+v", port)
+	}
+	if byKey["rabbitmq.password"].Value != "guest" {
+		t.Fatalf("expected default password, got %+v", byKey["rabbitmq.password"])
+	}
+	if _, ok := byKey["kafka.topic"]; ok {
+		t.Fatal("unselected transports must not be resolved")
+	}
+	if endpoints := byKey["health.endpoints"]; endpoints.Value != "off" || endpoints.DerivedFrom != "metrics.listen" {
+		t.Fatalf("expected health endpoints off with the metrics listener, got %+v", endpoints)
+	}
+}
+
+func TestResolveConfig_HealthEndpointsWithoutMetricsListener(t *testing.T) {
+	clearSettings(t)
+	t.Setenv("ProgramData", t.TempDir())
+	t.Setenv(EnvWinSoundSource, EnvWinSoundSourceVal01Simulated)
+	t.Setenv(EnvWinSoundHealthListen, ":9465")
+
+	settings, err := ResolveConfig()
+	if err != nil {
+		t.Fatalf("ResolveConfig failed: %v", err)
+	}
+	byKey := resolvedByKey(settings)
+	if listen := byKey["health.listen"]; listen.Value != ":9465" {
+		t.Fatalf("unexpected health listener %+v", listen)
+	}
+	if endpoints := byKey["health.endpoints"]; endpoints.Value != "/healthz,/readyz" || endpoints.DerivedFrom != "health.listen" {
+		t.Fatalf("expected health endpoints on their own listener, got %+v", endpoints)
+	}
+}
+
+func TestResolveConfig_ReportsAllProblems(t *testing.T) {
+	clearSettings(t)
+	t.Setenv(EnvWinSoundSource, "usb")
+	t.Setenv(EnvWinSoundEnqueuer, "kafka,nats")
+	t.Setenv(EnvWinSoundQueueOverflow, "explode")
+	t.Setenv(EnvWinSoundKafkaEncoding, "avro")
+	t.Setenv(EnvWinSoundNATSPublishTimeout, "soon")
+
+	_, err := ResolveConfig()
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, want := range []string{EnvWinSoundSource, EnvWinSoundQueueOverflow, EnvWinSoundKafkaRegistryURL, EnvWinSoundNATSPublishTimeout} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected a problem naming %s in:\n%v", want, err)
+		}
+	}
+}
@@ -6,10 +6,14 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/collect-sound-devices/win-sound-engine/v4/pkg/soundlibwrap"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	c "github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/tracing"
 	"github.com/collect-sound-devices/win-sound-scanner-go/pkg/appinfo"
 )
 
@@ -19,15 +23,33 @@ type ScannerApp interface {
 	Shutdown()
 }
 
+// knownDevice is the last default device reported to the API for one flow.
+type knownDevice struct {
+	name  string
+	pnpID string
+}
+
+// EnqueueFunc receives the requests of the scanner; ctx carries the span of
+// the device source callback that produced the request.
+type EnqueueFunc func(ctx context.Context, event c.EventType, fields map[string]string)
+
 type scannerAppImpl struct {
-	soundLibHandle soundlibwrap.Handle
-	enqueueFunc    func(c.EventType, map[string]string)
-	logger         *slog.Logger
-	osName         string
-	hostName       string
+	source      DeviceSource
+	enqueueFunc EnqueueFunc
+	logger      *slog.Logger
+	osName      string
+	hostName    string
+
+	// Native callbacks arrive on arbitrary threads.
+	mu          sync.Mutex
+	lastRender  knownDevice
+	lastCapture knownDevice
 }
 
-func NewImpl(enqueue func(c.EventType, map[string]string), logger *slog.Logger) (ScannerApp, error) {
+func NewImpl(source DeviceSource, enqueue EnqueueFunc, logger *slog.Logger) (ScannerApp, error) {
+	if source == nil {
+		panic("nil device source")
+	}
 	if enqueue == nil {
 		panic("nil enqueue")
 	}
@@ -36,6 +58,7 @@ func NewImpl(enqueue func(c.EventType, map[string]string), logger *slog.Logger)
 	}
 
 	app := &scannerAppImpl{
+		source:      source,
 		enqueueFunc: enqueue,
 		logger:      logger,
 	}
@@ -52,19 +75,11 @@ func NewImpl(enqueue func(c.EventType, map[string]string), logger *slog.Logger)
 }
 
 func (app *scannerAppImpl) init() error {
-	h, err := soundlibwrap.Initialize(appinfo.AppName, appinfo.Version)
-	if err != nil {
-		return err
-	}
-
-	app.soundLibHandle = h
-	if err := soundlibwrap.RegisterCallbacks(app.soundLibHandle); err != nil {
-		_ = soundlibwrap.Uninitialize(app.soundLibHandle)
-		app.soundLibHandle = 0
+	if err := app.source.Initialize(appinfo.AppName, appinfo.Version); err != nil {
 		return err
 	}
 
-	if osName, err := soundlibwrap.GetExtendedOperatingSystemName(app.soundLibHandle); err != nil || strings.TrimSpace(osName) == "" {
+	if osName, err := app.source.OperatingSystemName(); err != nil || strings.TrimSpace(osName) == "" {
 		app.logger.Warn("Cannot get OS name", "err", err)
 		app.osName = "Unknown OS"
 	} else {
@@ -82,79 +97,98 @@ func (app *scannerAppImpl) init() error {
 }
 
 func (app *scannerAppImpl) attachHandlers() {
+	app.source.SetHandlers(DeviceHandlers{
+		Log:                  app.logNative,
+		DefaultRender:        app.defaultRenderHandler,
+		DefaultCapture:       app.defaultCaptureHandler,
+		RenderVolumeChanged:  app.renderVolumeChangedHandler,
+		CaptureVolumeChanged: app.captureVolumeChangedHandler,
+	})
+}
 
-	soundlibwrap.SetLogHandler(func(timestamp, level, content string) {
-		nativeLevel := strings.ToLower(strings.TrimSpace(level))
-		args := make([]any, 0, 4)
-		if nativeLevel != "" {
-			args = append(args, "native_level", nativeLevel)
-		}
-		if timestamp = strings.TrimSpace(timestamp); timestamp != "" {
-			args = append(args, "native_timestamp", timestamp)
-		}
+func (app *scannerAppImpl) logNative(timestamp, level, content string) {
+	nativeLevel := strings.ToLower(strings.TrimSpace(level))
+	args := make([]any, 0, 4)
+	if nativeLevel != "" {
+		args = append(args, "native_level", nativeLevel)
+	}
+	if timestamp = strings.TrimSpace(timestamp); timestamp != "" {
+		args = append(args, "native_timestamp", timestamp)
+	}
 
-		getLogLevel := func(level string) slog.Level {
-			switch level {
-			case "trace", "debug":
-				return slog.LevelDebug
-			case "warn", "warning":
-				return slog.LevelWarn
-			case "error", "critical":
-				return slog.LevelError
-			default:
-				return slog.LevelInfo
-			}
+	getLogLevel := func(level string) slog.Level {
+		switch level {
+		case "trace", "debug":
+			return slog.LevelDebug
+		case "warn", "warning":
+			return slog.LevelWarn
+		case "error", "critical":
+			return slog.LevelError
+		default:
+			return slog.LevelInfo
 		}
+	}
 
-		app.logger.Log(context.Background(), getLogLevel(nativeLevel),
-			content, args...)
-	})
+	app.logger.Log(context.Background(), getLogLevel(nativeLevel),
+		content, args...)
+}
 
-	// Device default change notifications.
-	soundlibwrap.SetDefaultRenderHandler(func(present bool) {
-		if present {
-			app.RepostRenderDeviceToApi(c.EventTypeRenderDeviceDiscovered)
-		} else {
-			// not yet implemented removeDeviceToApi
-			app.logger.Info("Render device removed")
-		}
-	})
-	soundlibwrap.SetDefaultCaptureHandler(func(present bool) {
-		if present {
-			app.RepostCaptureDeviceToApi(c.EventTypeCaptureDeviceDiscovered)
-		} else {
-			// not yet implemented removeDeviceToApi
-			app.logger.Info("Capture device removed")
-		}
-	})
+// startCallback starts the root span of one device source notification.
+func startCallback(name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Start(context.Background(), name+" callback", trace.WithAttributes(attrs...))
+}
 
-	// Volume change notifications.
-	soundlibwrap.SetRenderVolumeChangedHandler(func() {
-		if desc, err := soundlibwrap.GetDefaultRender(app.soundLibHandle); err == nil {
-			app.putVolumeChangeToApi(c.EventTypeRenderVolumeChanged, desc.PnpID, int(desc.RenderVolume))
-			app.logger.Info("Render volume changed", "name", desc.Name, "pnpId", desc.PnpID, "volume", desc.RenderVolume)
-		} else {
-			app.logger.Error("Render volume changed, cannot read it", "err", err)
-		}
-	})
-	soundlibwrap.SetCaptureVolumeChangedHandler(func() {
-		if desc, err := soundlibwrap.GetDefaultCapture(app.soundLibHandle); err == nil {
-			app.putVolumeChangeToApi(c.EventTypeCaptureVolumeChanged, desc.PnpID, int(desc.CaptureVolume))
-			app.logger.Info("Capture volume changed", "name", desc.Name, "pnpId", desc.PnpID, "volume", desc.CaptureVolume)
-		} else {
-			app.logger.Error("Capture volume changed, cannot read it", "err", err)
-		}
-	})
+// Device default change notifications.
+func (app *scannerAppImpl) defaultRenderHandler(present bool) {
+	ctx, span := startCallback("DefaultRender", attribute.Bool("present", present))
+	defer span.End()
+	if present {
+		app.defaultRenderChanged(ctx)
+	} else {
+		app.detachDeviceFromApi(ctx, c.EventTypeRenderDeviceDetached, &app.lastRender)
+	}
+}
+
+func (app *scannerAppImpl) defaultCaptureHandler(present bool) {
+	ctx, span := startCallback("DefaultCapture", attribute.Bool("present", present))
+	defer span.End()
+	if present {
+		app.defaultCaptureChanged(ctx)
+	} else {
+		app.detachDeviceFromApi(ctx, c.EventTypeCaptureDeviceDetached, &app.lastCapture)
+	}
+}
+
+// Volume change notifications.
+func (app *scannerAppImpl) renderVolumeChangedHandler() {
+	ctx, span := startCallback("RenderVolumeChanged")
+	defer span.End()
+	if desc, err := app.source.DefaultRender(); err == nil {
+		app.putVolumeChangeToApi(ctx, c.EventTypeRenderVolumeChanged, desc.PnpID, desc.RenderVolume)
+		app.logger.Info("Render volume changed", "name", desc.Name, "pnpId", desc.PnpID, "volume", desc.RenderVolume)
+	} else {
+		app.logger.Error("Render volume changed, cannot read it", "err", err)
+	}
+}
+
+func (app *scannerAppImpl) captureVolumeChangedHandler() {
+	ctx, span := startCallback("CaptureVolumeChanged")
+	defer span.End()
+	if desc, err := app.source.DefaultCapture(); err == nil {
+		app.putVolumeChangeToApi(ctx, c.EventTypeCaptureVolumeChanged, desc.PnpID, desc.CaptureVolume)
+		app.logger.Info("Capture volume changed", "name", desc.Name, "pnpId", desc.PnpID, "volume", desc.CaptureVolume)
+	} else {
+		app.logger.Error("Capture volume changed, cannot read it", "err", err)
+	}
 }
 
 func (app *scannerAppImpl) Shutdown() {
-	if app.soundLibHandle != 0 {
-		_ = soundlibwrap.Uninitialize(app.soundLibHandle)
-		app.soundLibHandle = 0
+	if err := app.source.Close(); err != nil {
+		app.logger.Warn("Device source close failed", "err", err)
 	}
 }
 
-func (app *scannerAppImpl) putVolumeChangeToApi(event c.EventType, pnpID string, volume int) {
+func (app *scannerAppImpl) putVolumeChangeToApi(ctx context.Context, event c.EventType, pnpID string, volume int) {
 	fields := map[string]string{
 		c.FieldUpdateDate: time.Now().UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
 		c.FieldVolume:     strconv.Itoa(volume),
@@ -164,30 +198,63 @@ func (app *scannerAppImpl) putVolumeChangeToApi(event c.EventType, pnpID string,
 		fields[c.FieldPnpID] = pnpID
 	}
 
-	app.enqueueFunc(event, fields)
+	app.enqueueFunc(ctx, event, fields)
 }
 
 func (app *scannerAppImpl) RepostRenderDeviceToApi(event c.EventType) {
-	if desc, err := soundlibwrap.GetDefaultRender(app.soundLibHandle); err == nil {
-		renderVolume := int(desc.RenderVolume)
-		captureVolume := int(desc.CaptureVolume)
-		app.postDeviceToApi(event, desc.Name, desc.PnpID, renderVolume, captureVolume)
+	ctx, span := tracing.Start(context.Background(), "RepostRenderDevice", trace.WithAttributes(tracing.AttrEventType.String(event.String())))
+	defer span.End()
+	if desc, err := app.source.DefaultRender(); err == nil {
+		app.rememberDevice(&app.lastRender, desc.Name, desc.PnpID)
+		app.postDeviceToApi(ctx, event, desc.Name, desc.PnpID, "", desc.RenderVolume, desc.CaptureVolume)
 	} else {
 		app.logger.Error("Render device cannot be identified", "err", err)
 	}
 }
 
 func (app *scannerAppImpl) RepostCaptureDeviceToApi(event c.EventType) {
-	if desc, err := soundlibwrap.GetDefaultCapture(app.soundLibHandle); err == nil {
-		renderVolume := int(desc.RenderVolume)
-		captureVolume := int(desc.CaptureVolume)
-		app.postDeviceToApi(event, desc.Name, desc.PnpID, renderVolume, captureVolume)
+	ctx, span := tracing.Start(context.Background(), "RepostCaptureDevice", trace.WithAttributes(tracing.AttrEventType.String(event.String())))
+	defer span.End()
+	if desc, err := app.source.DefaultCapture(); err == nil {
+		app.rememberDevice(&app.lastCapture, desc.Name, desc.PnpID)
+		app.postDeviceToApi(ctx, event, desc.Name, desc.PnpID, "", desc.RenderVolume, desc.CaptureVolume)
+	} else {
+		app.logger.Error("Capture device cannot be identified", "err", err)
+	}
+}
+
+// defaultRenderChanged posts the new default render device. A switch away from
+// a known device is reported as DefaultRenderChanged carrying both PnP IDs;
+// a device appearing without a predecessor is reported as Discovered.
+func (app *scannerAppImpl) defaultRenderChanged(ctx context.Context) {
+	if desc, err := app.source.DefaultRender(); err == nil {
+		previous := app.rememberDevice(&app.lastRender, desc.Name, desc.PnpID)
+		event, previousPnpID := defaultChangeEvent(previous, desc.PnpID, c.EventTypeRenderDeviceDiscovered, c.EventTypeDefaultRenderChanged)
+		app.postDeviceToApi(ctx, event, desc.Name, desc.PnpID, previousPnpID, desc.RenderVolume, desc.CaptureVolume)
+	} else {
+		app.logger.Error("Render device cannot be identified", "err", err)
+	}
+}
+
+// defaultCaptureChanged is the capture counterpart of defaultRenderChanged.
+func (app *scannerAppImpl) defaultCaptureChanged(ctx context.Context) {
+	if desc, err := app.source.DefaultCapture(); err == nil {
+		previous := app.rememberDevice(&app.lastCapture, desc.Name, desc.PnpID)
+		event, previousPnpID := defaultChangeEvent(previous, desc.PnpID, c.EventTypeCaptureDeviceDiscovered, c.EventTypeDefaultCaptureChanged)
+		app.postDeviceToApi(ctx, event, desc.Name, desc.PnpID, previousPnpID, desc.RenderVolume, desc.CaptureVolume)
 	} else {
 		app.logger.Error("Capture device cannot be identified", "err", err)
 	}
 }
 
-func (app *scannerAppImpl) postDeviceToApi(event c.EventType, name, pnpID string, renderVolume, captureVolume int) {
+func defaultChangeEvent(previous knownDevice, pnpID string, discovered, switched c.EventType) (c.EventType, string) {
+	if previous.pnpID == "" || previous.pnpID == pnpID {
+		return discovered, ""
+	}
+	return switched, previous.pnpID
+}
+
+func (app *scannerAppImpl) postDeviceToApi(ctx context.Context, event c.EventType, name, pnpID, previousPnpID string, renderVolume, captureVolume int) {
 	fields := map[string]string{
 		c.FieldUpdateDate:          time.Now().UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
 		c.FieldName:                name,
@@ -197,6 +264,43 @@ func (app *scannerAppImpl) postDeviceToApi(event c.EventType, name, pnpID string
 		c.FieldOperationSystemName: app.osName,
 		c.FieldHostName:            app.hostName,
 	}
+	if previousPnpID != "" {
+		fields[c.FieldPreviousPnpID] = previousPnpID
+	}
+
+	app.enqueueFunc(ctx, event, fields)
+}
+
+// rememberDevice records the current default device and returns the previous one.
+func (app *scannerAppImpl) rememberDevice(last *knownDevice, name, pnpID string) knownDevice {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	previous := *last
+	*last = knownDevice{name: name, pnpID: pnpID}
+	return previous
+}
+
+// detachDeviceFromApi reports the removal of the last known default device.
+// The native notification only tells that no default device is present,
+// so the removed device is taken from the remembered state.
+func (app *scannerAppImpl) detachDeviceFromApi(ctx context.Context, event c.EventType, last *knownDevice) {
+	app.mu.Lock()
+	device := *last
+	*last = knownDevice{}
+	app.mu.Unlock()
+
+	if device.pnpID == "" {
+		app.logger.Warn("Device removed, but no previous device is known", "event", event)
+		return
+	}
+	app.logger.Info("Device removed", "event", event, "name", device.name, "pnpId", device.pnpID)
+
+	fields := map[string]string{
+		c.FieldUpdateDate: time.Now().UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		c.FieldName:       device.name,
+		c.FieldPnpID:      device.pnpID,
+		c.FieldHostName:   app.hostName,
+	}
 
-	app.enqueueFunc(event, fields)
+	app.enqueueFunc(ctx, event, fields)
 }
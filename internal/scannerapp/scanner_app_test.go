@@ -0,0 +1,187 @@
+package scannerapp
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	c "github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+)
+
+type recordedRequest struct {
+	event  c.EventType
+	fields map[string]string
+}
+
+type requestRecorder struct {
+	mu       sync.Mutex
+	requests []recordedRequest
+}
+
+func (r *requestRecorder) enqueue(_ context.Context, event c.EventType, fields map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, recordedRequest{event: event, fields: fields})
+}
+
+func (r *requestRecorder) EnqueueRequest(request enqueuer.Request) error {
+	r.enqueue(context.Background(), request.Event, request.Fields)
+	return nil
+}
+
+func (r *requestRecorder) snapshot() []recordedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]recordedRequest(nil), r.requests...)
+}
+
+func (r *requestRecorder) events() []c.EventType {
+	requests := r.snapshot()
+	events := make([]c.EventType, 0, len(requests))
+	for _, request := range requests {
+		events = append(events, request.event)
+	}
+	return events
+}
+
+var (
+	speakers   = DeviceDescription{Name: "Speakers", PnpID: "pnp-speakers", RenderVolume: 30}
+	headset    = DeviceDescription{Name: "Headset", PnpID: "pnp-headset", RenderVolume: 60}
+	microphone = DeviceDescription{Name: "Microphone", PnpID: "pnp-mic", CaptureVolume: 70}
+)
+
+func newTestApp(t *testing.T) (*SimulatedDeviceSource, *requestRecorder, ScannerApp) {
+	t.Helper()
+	source := NewSimulatedDeviceSource(&speakers, &microphone)
+	recorder := &requestRecorder{}
+	app, err := NewImpl(source, recorder.enqueue, slog.Default())
+	if err != nil {
+		t.Fatalf("NewImpl failed: %v", err)
+	}
+	return source, recorder, app
+}
+
+func assertEvents(t *testing.T, actual []c.EventType, expected ...c.EventType) {
+	t.Helper()
+	if len(actual) != len(expected) {
+		t.Fatalf("expected events %v, got %v", expected, actual)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Fatalf("expected events %v, got %v", expected, actual)
+		}
+	}
+}
+
+func TestNewImpl_ConfirmsDefaultDevicesOnStartup(t *testing.T) {
+	_, recorder, app := newTestApp(t)
+	defer app.Shutdown()
+
+	requests := recorder.snapshot()
+	assertEvents(t, recorder.events(), c.EventTypeRenderDeviceConfirmed, c.EventTypeCaptureDeviceConfirmed)
+	if requests[0].fields[c.FieldPnpID] != "pnp-speakers" || requests[0].fields[c.FieldRenderVolume] != "30" {
+		t.Fatalf("unexpected render confirmation: %#v", requests[0].fields)
+	}
+	if requests[1].fields[c.FieldOperationSystemName] != "Simulated OS" {
+		t.Fatalf("unexpected OS name: %#v", requests[1].fields)
+	}
+}
+
+func TestScannerApp_VolumeChangeProducesVolumeEvent(t *testing.T) {
+	source, recorder, app := newTestApp(t)
+	defer app.Shutdown()
+
+	if err := source.SetCaptureVolume(15); err != nil {
+		t.Fatalf("SetCaptureVolume failed: %v", err)
+	}
+
+	requests := recorder.snapshot()
+	last := requests[len(requests)-1]
+	if last.event != c.EventTypeCaptureVolumeChanged || last.fields[c.FieldVolume] != "15" || last.fields[c.FieldPnpID] != "pnp-mic" {
+		t.Fatalf("unexpected volume request: %v %#v", last.event, last.fields)
+	}
+}
+
+func TestScannerApp_DefaultSwitchAndRemoval(t *testing.T) {
+	source, recorder, app := newTestApp(t)
+	defer app.Shutdown()
+
+	source.SetDefaultRender(headset)
+	source.RemoveDefaultRender()
+	source.SetDefaultRender(speakers)
+
+	requests := recorder.snapshot()
+	assertEvents(t, recorder.events(),
+		c.EventTypeRenderDeviceConfirmed,
+		c.EventTypeCaptureDeviceConfirmed,
+		c.EventTypeDefaultRenderChanged,
+		c.EventTypeRenderDeviceDetached,
+		c.EventTypeRenderDeviceDiscovered,
+	)
+	if requests[2].fields[c.FieldPnpID] != "pnp-headset" || requests[2].fields[c.FieldPreviousPnpID] != "pnp-speakers" {
+		t.Fatalf("unexpected switch request: %#v", requests[2].fields)
+	}
+	if requests[3].fields[c.FieldPnpID] != "pnp-headset" || requests[3].fields[c.FieldName] != "Headset" {
+		t.Fatalf("unexpected detach request: %#v", requests[3].fields)
+	}
+}
+
+func TestScannerApp_ShutdownStopsNotifications(t *testing.T) {
+	source, recorder, app := newTestApp(t)
+
+	app.Shutdown()
+	source.SetDefaultCapture(microphone)
+
+	if source.Initialized() {
+		t.Fatal("expected source to be closed")
+	}
+	assertEvents(t, recorder.events(), c.EventTypeRenderDeviceConfirmed, c.EventTypeCaptureDeviceConfirmed)
+}
+
+func TestRunPipeline_DeliversEventsUntilCancelled(t *testing.T) {
+	t.Setenv(EnvWinSoundVolumeSettleWindow, "20")
+
+	source := NewSimulatedDeviceSource(&speakers, &microphone)
+	transport := &requestRecorder{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- runPipeline(ctx, slog.Default(), source, transport)
+	}()
+
+	waitUntil(t, source.Initialized)
+	for _, volume := range []int{10, 20, 30} {
+		if err := source.SetRenderVolume(volume); err != nil {
+			t.Fatalf("SetRenderVolume failed: %v", err)
+		}
+	}
+	waitUntil(t, func() bool { return len(transport.snapshot()) == 3 })
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("runPipeline failed: %v", err)
+	}
+
+	requests := transport.snapshot()
+	assertEvents(t, transport.events(), c.EventTypeRenderDeviceConfirmed, c.EventTypeCaptureDeviceConfirmed, c.EventTypeRenderVolumeChanged)
+	if requests[2].fields[c.FieldVolume] != "30" {
+		t.Fatalf("expected coalesced final volume 30, got %#v", requests[2].fields)
+	}
+	if source.Initialized() {
+		t.Fatal("expected source to be closed on shutdown")
+	}
+}
+
+func waitUntil(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
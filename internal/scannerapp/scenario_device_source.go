@@ -0,0 +1,252 @@
+package scannerapp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	scenarioFlowRender   = "render"
+	scenarioFlowCapture  = "capture"
+	scenarioActionSet    = "default"
+	scenarioActionRemove = "remove"
+	scenarioActionVolume = "volume"
+	scenarioActionLog    = "log"
+)
+
+// Scenario is a timeline of device events replayed by a ScenarioDeviceSource.
+//
+//	{
+//	  "osName": "Windows 11 Pro",
+//	  "render": {"name": "Speakers", "pnpId": "pnp-1", "renderVolume": 30},
+//	  "events": [
+//	    {"at": "2s", "flow": "render", "action": "volume", "volume": 40},
+//	    {"at": "5s", "flow": "capture", "action": "remove"}
+//	  ]
+//	}
+type Scenario struct {
+	OSName  string              `json:"osName,omitempty"`
+	Render  *ScenarioDevice     `json:"render,omitempty"`
+	Capture *ScenarioDevice     `json:"capture,omitempty"`
+	Events  []ScenarioEventSpec `json:"events"`
+}
+
+// ScenarioDevice is the JSON form of a DeviceDescription.
+type ScenarioDevice struct {
+	Name          string `json:"name"`
+	PnpID         string `json:"pnpId"`
+	RenderVolume  int    `json:"renderVolume"`
+	CaptureVolume int    `json:"captureVolume"`
+}
+
+// ScenarioEventSpec is one step of the timeline. At is an offset from
+// Initialize in time.ParseDuration syntax; steps with equal offsets run in file order.
+type ScenarioEventSpec struct {
+	At      string          `json:"at"`
+	Flow    string          `json:"flow,omitempty"`
+	Action  string          `json:"action"`
+	Device  *ScenarioDevice `json:"device,omitempty"`
+	Volume  *int            `json:"volume,omitempty"`
+	Level   string          `json:"level,omitempty"`
+	Message string          `json:"message,omitempty"`
+
+	offset time.Duration
+}
+
+func (d *ScenarioDevice) description() *DeviceDescription {
+	if d == nil {
+		return nil
+	}
+	return &DeviceDescription{
+		Name:          d.Name,
+		PnpID:         d.PnpID,
+		RenderVolume:  d.RenderVolume,
+		CaptureVolume: d.CaptureVolume,
+	}
+}
+
+// LoadScenario reads and validates a scenario file.
+func LoadScenario(path string) (Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Scenario{}, fmt.Errorf("read scenario file: %w", err)
+	}
+	scenario, err := ParseScenario(data)
+	if err != nil {
+		return Scenario{}, fmt.Errorf("scenario file %s: %w", path, err)
+	}
+	return scenario, nil
+}
+
+// ParseScenario decodes and validates a JSON scenario and orders its events by offset.
+func ParseScenario(data []byte) (Scenario, error) {
+	var scenario Scenario
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&scenario); err != nil {
+		return Scenario{}, fmt.Errorf("decode scenario: %w", err)
+	}
+
+	var errs []error
+	for i := range scenario.Events {
+		if err := scenario.Events[i].validate(); err != nil {
+			errs = append(errs, fmt.Errorf("event %d: %w", i+1, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return Scenario{}, err
+	}
+
+	sort.SliceStable(scenario.Events, func(i, j int) bool {
+		return scenario.Events[i].offset < scenario.Events[j].offset
+	})
+	return scenario, nil
+}
+
+func (e *ScenarioEventSpec) validate() error {
+	offset, err := time.ParseDuration(strings.TrimSpace(e.At))
+	if err != nil {
+		return fmt.Errorf("invalid at %q: %w", e.At, err)
+	}
+	if offset < 0 {
+		return fmt.Errorf("at can not be negative %q", e.At)
+	}
+	e.offset = offset
+
+	e.Action = strings.ToLower(strings.TrimSpace(e.Action))
+	if e.Action == scenarioActionLog {
+		return nil
+	}
+
+	e.Flow = strings.ToLower(strings.TrimSpace(e.Flow))
+	if e.Flow != scenarioFlowRender && e.Flow != scenarioFlowCapture {
+		return fmt.Errorf("unsupported flow %q (supported: render, capture)", e.Flow)
+	}
+
+	switch e.Action {
+	case scenarioActionSet:
+		if e.Device == nil || strings.TrimSpace(e.Device.PnpID) == "" {
+			return errors.New("action default requires a device with pnpId")
+		}
+	case scenarioActionVolume:
+		if e.Volume == nil {
+			return errors.New("action volume requires volume")
+		}
+	case scenarioActionRemove:
+	default:
+		return fmt.Errorf("unsupported action %q (supported: default, remove, volume, log)", e.Action)
+	}
+	return nil
+}
+
+// ScenarioDeviceSource replays a Scenario through the handlers of a
+// SimulatedDeviceSource, i.e. through the same handlers the native callbacks use.
+// Playback starts on Initialize.
+type ScenarioDeviceSource struct {
+	*SimulatedDeviceSource
+	scenario Scenario
+
+	mu      sync.Mutex
+	started bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func NewScenarioDeviceSource(scenario Scenario) *ScenarioDeviceSource {
+	simulated := NewSimulatedDeviceSource(scenario.Render.description(), scenario.Capture.description())
+	if strings.TrimSpace(scenario.OSName) != "" {
+		simulated.SetOperatingSystemName(scenario.OSName)
+	}
+	return &ScenarioDeviceSource{
+		SimulatedDeviceSource: simulated,
+		scenario:              scenario,
+		stop:                  make(chan struct{}),
+		done:                  make(chan struct{}),
+	}
+}
+
+func (s *ScenarioDeviceSource) Initialize(appName, version string) error {
+	if err := s.SimulatedDeviceSource.Initialize(appName, version); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.started = true
+	s.mu.Unlock()
+	go s.play(time.Now())
+	return nil
+}
+
+// Done is closed when the playback finished or was stopped.
+func (s *ScenarioDeviceSource) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *ScenarioDeviceSource) Close() error {
+	s.mu.Lock()
+	started := s.started
+	select {
+	case <-s.stop:
+	default:
+		close(s.stop)
+	}
+	s.mu.Unlock()
+
+	if started {
+		<-s.done
+	}
+	return s.SimulatedDeviceSource.Close()
+}
+
+func (s *ScenarioDeviceSource) play(start time.Time) {
+	defer close(s.done)
+
+	for i, event := range s.scenario.Events {
+		timer := time.NewTimer(time.Until(start.Add(event.offset)))
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.Log("info", fmt.Sprintf("Scenario step %d at %s: %s", i+1, event.offset, strings.TrimSpace(event.Flow+" "+event.Action)))
+		if err := s.apply(event); err != nil {
+			s.Log("warn", fmt.Sprintf("Scenario step %d failed: %v", i+1, err))
+		}
+	}
+	s.Log("info", "Scenario completed")
+}
+
+func (s *ScenarioDeviceSource) apply(event ScenarioEventSpec) error {
+	render := event.Flow == scenarioFlowRender
+	switch event.Action {
+	case scenarioActionSet:
+		if render {
+			s.SetDefaultRender(*event.Device.description())
+		} else {
+			s.SetDefaultCapture(*event.Device.description())
+		}
+	case scenarioActionRemove:
+		if render {
+			s.RemoveDefaultRender()
+		} else {
+			s.RemoveDefaultCapture()
+		}
+	case scenarioActionVolume:
+		if render {
+			return s.SetRenderVolume(*event.Volume)
+		}
+		return s.SetCaptureVolume(*event.Volume)
+	case scenarioActionLog:
+		s.Log(event.Level, event.Message)
+	}
+	return nil
+}
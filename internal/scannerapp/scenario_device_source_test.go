@@ -0,0 +1,119 @@
+package scannerapp
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	c "github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+)
+
+const testScenario = `{
+  "osName": "Windows 11 Pro",
+  "render": {"name": "Speakers", "pnpId": "pnp-speakers", "renderVolume": 30},
+  "capture": {"name": "Microphone", "pnpId": "pnp-mic", "captureVolume": 70},
+  "events": [
+    {"at": "30ms", "flow": "capture", "action": "remove"},
+    {"at": "20ms", "flow": "render", "action": "volume", "volume": 40},
+    {"at": "10ms", "flow": "render", "action": "default", "device": {"name": "Headset", "pnpId": "pnp-headset", "renderVolume": 60}}
+  ]
+}`
+
+func TestParseScenario_OrdersEventsByOffset(t *testing.T) {
+	scenario, err := ParseScenario([]byte(testScenario))
+	if err != nil {
+		t.Fatalf("ParseScenario failed: %v", err)
+	}
+
+	actions := make([]string, 0, len(scenario.Events))
+	for _, event := range scenario.Events {
+		actions = append(actions, event.Action)
+	}
+	if strings.Join(actions, ",") != "default,volume,remove" {
+		t.Fatalf("unexpected event order: %v", actions)
+	}
+}
+
+func TestParseScenario_ReportsAllInvalidEvents(t *testing.T) {
+	_, err := ParseScenario([]byte(`{"events": [
+		{"at": "soon", "flow": "render", "action": "remove"},
+		{"at": "1s", "flow": "speaker", "action": "remove"},
+		{"at": "2s", "flow": "render", "action": "volume"}
+	]}`))
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, part := range []string{"event 1", "event 2", "event 3"} {
+		if !strings.Contains(err.Error(), part) {
+			t.Fatalf("expected %q in error %q", part, err)
+		}
+	}
+}
+
+func TestScenarioDeviceSource_ReplaysThroughScannerHandlers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scenario.json")
+	if err := os.WriteFile(path, []byte(testScenario), 0o644); err != nil {
+		t.Fatalf("write scenario: %v", err)
+	}
+	scenario, err := LoadScenario(path)
+	if err != nil {
+		t.Fatalf("LoadScenario failed: %v", err)
+	}
+
+	source := NewScenarioDeviceSource(scenario)
+	recorder := &requestRecorder{}
+	app, err := NewImpl(source, recorder.enqueue, slog.Default())
+	if err != nil {
+		t.Fatalf("NewImpl failed: %v", err)
+	}
+
+	select {
+	case <-source.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("scenario did not complete")
+	}
+	app.Shutdown()
+
+	requests := recorder.snapshot()
+	assertEvents(t, recorder.events(),
+		c.EventTypeRenderDeviceConfirmed,
+		c.EventTypeCaptureDeviceConfirmed,
+		c.EventTypeDefaultRenderChanged,
+		c.EventTypeRenderVolumeChanged,
+		c.EventTypeCaptureDeviceDetached,
+	)
+	if requests[0].fields[c.FieldOperationSystemName] != "Windows 11 Pro" {
+		t.Fatalf("unexpected OS name: %#v", requests[0].fields)
+	}
+	if requests[3].fields[c.FieldPnpID] != "pnp-headset" || requests[3].fields[c.FieldVolume] != "40" {
+		t.Fatalf("unexpected volume request: %#v", requests[3].fields)
+	}
+	if requests[4].fields[c.FieldPnpID] != "pnp-mic" {
+		t.Fatalf("unexpected detach request: %#v", requests[4].fields)
+	}
+}
+
+func TestScenarioDeviceSource_CloseStopsPlayback(t *testing.T) {
+	scenario, err := ParseScenario([]byte(`{"events": [{"at": "1h", "flow": "render", "action": "remove"}]}`))
+	if err != nil {
+		t.Fatalf("ParseScenario failed: %v", err)
+	}
+	source := NewScenarioDeviceSource(scenario)
+	if err := source.Initialize("test", "dev"); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		_ = source.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close did not stop the playback")
+	}
+}
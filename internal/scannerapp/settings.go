@@ -0,0 +1,118 @@
+package scannerapp
+
+import "github.com/collect-sound-devices/win-sound-scanner-go/internal/config"
+
+// Settings lists every option the configuration file can set, with the
+// environment variable it stands for.
+func Settings() []config.Setting {
+	s := func(key, env string, kind config.Kind) config.Setting {
+		return config.Setting{Key: key, Env: env, Kind: kind}
+	}
+	secret := func(key, env string) config.Setting {
+		return config.Setting{Key: key, Env: env, Kind: config.KindString, Secret: true}
+	}
+	// The device source and the pipeline in front of the enqueuers are not
+	// rebuilt by a reload.
+	startup := func(key, env string, kind config.Kind) config.Setting {
+		return config.Setting{Key: key, Env: env, Kind: kind, StartupOnly: true}
+	}
+	str, num, flag, list := config.KindString, config.KindInt, config.KindBool, config.KindList
+
+	return []config.Setting{
+		startup("source", EnvWinSoundSource, str),
+		startup("scenarioFile", EnvWinSoundScenarioFile, str),
+		s("enqueuer", EnvWinSoundEnqueuer, list),
+		s("enqueuerBestEffort", EnvWinSoundEnqueuerBestEffort, list),
+		s("cloudEventsMode", EnvWinSoundCloudEventsMode, str),
+
+		s("schema.validation", EnvWinSoundSchemaValidation, flag),
+		s("schema.rejectedDir", EnvWinSoundRejectedDir, str),
+
+		startup("queue.capacity", EnvWinSoundQueueCapacity, num),
+		startup("queue.overflow", EnvWinSoundQueueOverflow, str),
+		startup("volume.settleMs", EnvWinSoundVolumeSettleWindow, num),
+		startup("volume.maxDelayMs", EnvWinSoundVolumeMaxDelay, num),
+		startup("metrics.listen", EnvWinSoundMetricsListen, str),
+		startup("health.listen", EnvWinSoundHealthListen, str),
+		startup("otel.endpoint", EnvWinSoundOTelEndpoint, str),
+
+		s("outbox.enabled", EnvWinSoundOutboxEnabled, flag),
+		s("outbox.dir", EnvWinSoundOutboxDir, str),
+		s("outbox.retryInitialDelayMs", EnvWinSoundOutboxRetryInitial, num),
+		s("outbox.retryMaxDelayMs", EnvWinSoundOutboxRetryMax, num),
+		s("outbox.bestEffortMaxAttempts", EnvWinSoundOutboxBestEffortMax, num),
+
+		s("rabbitmq.host", EnvWinSoundRabbitMQHost, str),
+		s("rabbitmq.port", EnvWinSoundRabbitMQPort, num),
+		s("rabbitmq.vhost", EnvWinSoundRabbitMQVHost, str),
+		s("rabbitmq.user", EnvWinSoundRabbitMQUser, str),
+		secret("rabbitmq.password", EnvWinSoundRabbitMQPassword),
+		s("rabbitmq.exchange", EnvWinSoundRabbitMQExchange, str),
+		s("rabbitmq.queue", EnvWinSoundRabbitMQQueue, str),
+		s("rabbitmq.routingKey", EnvWinSoundRabbitMQRoutingKey, str),
+		s("rabbitmq.authMechanism", EnvWinSoundRabbitMQAuthMechanism, str),
+		s("rabbitmq.connectionThresholdSec", EnvWinSoundRabbitMQConnThreshold, num),
+		s("rabbitmq.maxReconnectAttempts", EnvWinSoundRabbitMQMaxReconnects, num),
+		s("rabbitmq.initialReconnectDelayMs", EnvWinSoundRabbitMQRetryInitial, num),
+		s("rabbitmq.maxReconnectDelayMs", EnvWinSoundRabbitMQRetryMax, num),
+		s("rabbitmq.publishConfirmTimeoutMs", EnvWinSoundRabbitMQConfirmWait, num),
+		s("rabbitmq.tls.enabled", EnvWinSoundRabbitMQTLSEnabled, flag),
+		s("rabbitmq.tls.caFile", EnvWinSoundRabbitMQTLSCAFile, str),
+		s("rabbitmq.tls.certFile", EnvWinSoundRabbitMQTLSCertFile, str),
+		s("rabbitmq.tls.keyFile", EnvWinSoundRabbitMQTLSKeyFile, str),
+		s("rabbitmq.tls.serverName", EnvWinSoundRabbitMQTLSServerName, str),
+
+		s("kafka.brokers", EnvWinSoundKafkaBrokers, list),
+		s("kafka.topic", EnvWinSoundKafkaTopic, str),
+		s("kafka.clientId", EnvWinSoundKafkaClientID, str),
+		s("kafka.writeTimeoutMs", EnvWinSoundKafkaWriteTimeout, num),
+		s("kafka.healthWindowMs", EnvWinSoundKafkaHealthWindow, num),
+		s("kafka.tls.enabled", EnvWinSoundKafkaTLSEnabled, flag),
+		s("kafka.tls.caFile", EnvWinSoundKafkaTLSCAFile, str),
+		s("kafka.tls.certFile", EnvWinSoundKafkaTLSCertFile, str),
+		s("kafka.tls.keyFile", EnvWinSoundKafkaTLSKeyFile, str),
+		s("kafka.tls.insecureSkipVerify", EnvWinSoundKafkaTLSSkipVerify, flag),
+		s("kafka.sasl.mechanism", EnvWinSoundKafkaSASLMechanism, str),
+		s("kafka.sasl.username", EnvWinSoundKafkaSASLUsername, str),
+		secret("kafka.sasl.password", EnvWinSoundKafkaSASLPassword),
+		s("kafka.encoding", EnvWinSoundKafkaEncoding, str),
+		s("kafka.schemaRegistry.url", EnvWinSoundKafkaRegistryURL, str),
+		s("kafka.schemaRegistry.username", EnvWinSoundKafkaRegistryUsername, str),
+		secret("kafka.schemaRegistry.password", EnvWinSoundKafkaRegistryPassword),
+		s("kafka.schemaRegistry.autoRegister", EnvWinSoundKafkaRegistryAutoReg, flag),
+
+		s("http.baseUrl", EnvWinSoundHTTPBaseURL, str),
+		s("http.timeoutMs", EnvWinSoundHTTPTimeout, num),
+		s("http.maxRetries", EnvWinSoundHTTPMaxRetries, num),
+		s("http.initialRetryDelayMs", EnvWinSoundHTTPRetryInitial, num),
+		s("http.maxRetryDelayMs", EnvWinSoundHTTPRetryMax, num),
+		// Headers usually carry an Authorization token.
+		{Key: "http.headers", Env: EnvWinSoundHTTPHeaders, Kind: config.KindHeaders, Secret: true},
+
+		s("mqtt.broker", EnvWinSoundMQTTBroker, str),
+		s("mqtt.clientId", EnvWinSoundMQTTClientID, str),
+		s("mqtt.user", EnvWinSoundMQTTUser, str),
+		secret("mqtt.password", EnvWinSoundMQTTPassword),
+		s("mqtt.protocolVersion", EnvWinSoundMQTTProtocolVersion, str),
+		s("mqtt.topic", EnvWinSoundMQTTTopic, str),
+		s("mqtt.statusTopic", EnvWinSoundMQTTStatusTopic, str),
+		s("mqtt.qos", EnvWinSoundMQTTQoS, num),
+		s("mqtt.retained", EnvWinSoundMQTTRetained, flag),
+		s("mqtt.connectTimeoutMs", EnvWinSoundMQTTConnectTimeout, num),
+		s("mqtt.publishTimeoutMs", EnvWinSoundMQTTPublishTimeout, num),
+
+		s("nats.url", EnvWinSoundNATSURL, str),
+		s("nats.user", EnvWinSoundNATSUser, str),
+		secret("nats.password", EnvWinSoundNATSPassword),
+		s("nats.clientName", EnvWinSoundNATSClientName, str),
+		s("nats.subjectPrefix", EnvWinSoundNATSSubjectPrefix, str),
+		s("nats.jetStream", EnvWinSoundNATSJetStream, flag),
+		s("nats.stream", EnvWinSoundNATSStream, str),
+		s("nats.publishTimeoutMs", EnvWinSoundNATSPublishTimeout, num),
+
+		s("file.dir", EnvWinSoundFileDir, str),
+		s("file.maxSizeMb", EnvWinSoundFileMaxSizeMB, num),
+		s("file.rotateDaily", EnvWinSoundFileRotateDaily, flag),
+		s("file.fsyncIntervalMs", EnvWinSoundFileFsyncInterval, num),
+	}
+}
@@ -0,0 +1,40 @@
+package scannerapp
+
+import (
+	"os"
+	"testing"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/config"
+)
+
+func TestSettings_KeysAndVariablesAreUnique(t *testing.T) {
+	keys := make(map[string]bool)
+	envs := make(map[string]bool)
+	for _, s := range Settings() {
+		if keys[s.Key] || envs[s.Env] {
+			t.Fatalf("duplicate setting %s / %s", s.Key, s.Env)
+		}
+		keys[s.Key] = true
+		envs[s.Env] = true
+	}
+}
+
+func TestSettings_ExampleConfigIsValid(t *testing.T) {
+	for _, s := range Settings() {
+		t.Setenv(s.Env, "")
+		if err := os.Unsetenv(s.Env); err != nil {
+			t.Fatalf("unset %s: %v", s.Env, err)
+		}
+	}
+
+	layers, err := config.New(Settings(), "../../docs/config-example.yaml", nil)
+	if err != nil {
+		t.Fatalf("config.New failed: %v", err)
+	}
+	if _, err := layers.Apply(); err != nil {
+		t.Fatalf("example config is invalid: %v", err)
+	}
+	if got := os.Getenv(EnvWinSoundEnqueuer); got != "rabbitmq,file" {
+		t.Fatalf("unexpected %s %q", EnvWinSoundEnqueuer, got)
+	}
+}
@@ -0,0 +1,212 @@
+package scannerapp
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	defaultSimulatedRender = &DeviceDescription{
+		Name:          "Simulated Speakers",
+		PnpID:         "SWD\\MMDEVAPI\\{0.0.0.00000000}.{SIMULATED-RENDER}",
+		RenderVolume:  50,
+		CaptureVolume: 0,
+	}
+	defaultSimulatedCapture = &DeviceDescription{
+		Name:          "Simulated Microphone",
+		PnpID:         "SWD\\MMDEVAPI\\{0.0.1.00000000}.{SIMULATED-CAPTURE}",
+		RenderVolume:  0,
+		CaptureVolume: 50,
+	}
+)
+
+var errNoDefaultDevice = errors.New("no default device")
+
+// SimulatedDeviceSource is a DeviceSource driven from Go code. Its mutators
+// update the simulated state and invoke the registered handlers on the calling
+// goroutine, the same way the native library calls back on its own threads.
+// Notifications are delivered only between Initialize and Close.
+type SimulatedDeviceSource struct {
+	mu          sync.Mutex
+	handlers    DeviceHandlers
+	render      *DeviceDescription
+	capture     *DeviceDescription
+	osName      string
+	initialized bool
+	closed      bool
+}
+
+// NewSimulatedDeviceSource creates a source with the given default devices; nil means absent.
+func NewSimulatedDeviceSource(render, capture *DeviceDescription) *SimulatedDeviceSource {
+	return &SimulatedDeviceSource{
+		render:  cloneDescription(render),
+		capture: cloneDescription(capture),
+		osName:  "Simulated OS",
+	}
+}
+
+func (s *SimulatedDeviceSource) SetHandlers(handlers DeviceHandlers) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers = handlers
+}
+
+func (s *SimulatedDeviceSource) Initialize(_, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.initialized {
+		return errors.New("simulated device source is already initialized")
+	}
+	s.initialized = true
+	s.closed = false
+	return nil
+}
+
+// Initialized reports whether the source is between Initialize and Close.
+func (s *SimulatedDeviceSource) Initialized() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.initialized && !s.closed
+}
+
+func (s *SimulatedDeviceSource) OperatingSystemName() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.osName, nil
+}
+
+// SetOperatingSystemName changes the reported operating system name.
+func (s *SimulatedDeviceSource) SetOperatingSystemName(osName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.osName = osName
+}
+
+func (s *SimulatedDeviceSource) DefaultRender() (DeviceDescription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.render == nil {
+		return DeviceDescription{}, errNoDefaultDevice
+	}
+	return *s.render, nil
+}
+
+func (s *SimulatedDeviceSource) DefaultCapture() (DeviceDescription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.capture == nil {
+		return DeviceDescription{}, errNoDefaultDevice
+	}
+	return *s.capture, nil
+}
+
+// SetDefaultRender makes desc the default render device and notifies the handlers.
+func (s *SimulatedDeviceSource) SetDefaultRender(desc DeviceDescription) {
+	s.mu.Lock()
+	s.render = &desc
+	handler, ok := s.activeHandlerLocked(s.handlers.DefaultRender)
+	s.mu.Unlock()
+	if ok {
+		handler(true)
+	}
+}
+
+// RemoveDefaultRender removes the default render device and notifies the handlers.
+func (s *SimulatedDeviceSource) RemoveDefaultRender() {
+	s.mu.Lock()
+	s.render = nil
+	handler, ok := s.activeHandlerLocked(s.handlers.DefaultRender)
+	s.mu.Unlock()
+	if ok {
+		handler(false)
+	}
+}
+
+// SetDefaultCapture makes desc the default capture device and notifies the handlers.
+func (s *SimulatedDeviceSource) SetDefaultCapture(desc DeviceDescription) {
+	s.mu.Lock()
+	s.capture = &desc
+	handler, ok := s.activeHandlerLocked(s.handlers.DefaultCapture)
+	s.mu.Unlock()
+	if ok {
+		handler(true)
+	}
+}
+
+// RemoveDefaultCapture removes the default capture device and notifies the handlers.
+func (s *SimulatedDeviceSource) RemoveDefaultCapture() {
+	s.mu.Lock()
+	s.capture = nil
+	handler, ok := s.activeHandlerLocked(s.handlers.DefaultCapture)
+	s.mu.Unlock()
+	if ok {
+		handler(false)
+	}
+}
+
+// SetRenderVolume changes the volume of the default render device and notifies the handlers.
+func (s *SimulatedDeviceSource) SetRenderVolume(volume int) error {
+	s.mu.Lock()
+	if s.render == nil {
+		s.mu.Unlock()
+		return errNoDefaultDevice
+	}
+	s.render.RenderVolume = volume
+	handler, ok := s.activeVolumeHandlerLocked(s.handlers.RenderVolumeChanged)
+	s.mu.Unlock()
+	if ok {
+		handler()
+	}
+	return nil
+}
+
+// SetCaptureVolume changes the volume of the default capture device and notifies the handlers.
+func (s *SimulatedDeviceSource) SetCaptureVolume(volume int) error {
+	s.mu.Lock()
+	if s.capture == nil {
+		s.mu.Unlock()
+		return errNoDefaultDevice
+	}
+	s.capture.CaptureVolume = volume
+	handler, ok := s.activeVolumeHandlerLocked(s.handlers.CaptureVolumeChanged)
+	s.mu.Unlock()
+	if ok {
+		handler()
+	}
+	return nil
+}
+
+// Log emits a library log line through the log handler.
+func (s *SimulatedDeviceSource) Log(level, content string) {
+	s.mu.Lock()
+	handler := s.handlers.Log
+	active := s.initialized && !s.closed
+	s.mu.Unlock()
+	if active && handler != nil {
+		handler(time.Now().Format("2006-01-02 15:04:05.000"), level, content)
+	}
+}
+
+func (s *SimulatedDeviceSource) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *SimulatedDeviceSource) activeHandlerLocked(handler func(bool)) (func(bool), bool) {
+	return handler, handler != nil && s.initialized && !s.closed
+}
+
+func (s *SimulatedDeviceSource) activeVolumeHandlerLocked(handler func()) (func(), bool) {
+	return handler, handler != nil && s.initialized && !s.closed
+}
+
+func cloneDescription(desc *DeviceDescription) *DeviceDescription {
+	if desc == nil {
+		return nil
+	}
+	clone := *desc
+	return &clone
+}
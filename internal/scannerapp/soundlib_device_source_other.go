@@ -0,0 +1,11 @@
+//go:build !windows
+
+package scannerapp
+
+import "errors"
+
+const defaultDeviceSourceMode = EnvWinSoundSourceVal01Simulated
+
+func newSoundLibDeviceSource() (DeviceSource, error) {
+	return nil, errors.New("the soundlib device source requires Windows")
+}
@@ -0,0 +1,106 @@
+//go:build windows
+
+package scannerapp
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/collect-sound-devices/win-sound-engine/v4/pkg/soundlibwrap"
+)
+
+const defaultDeviceSourceMode = EnvWinSoundSourceVal00SoundLib
+
+// soundLibDeviceSource reads devices through SoundAgentApi.dll.
+type soundLibDeviceSource struct {
+	handle soundlibwrap.Handle
+	// initialized mirrors handle != 0 for the health check, which runs on
+	// the listener's goroutines.
+	initialized atomic.Bool
+}
+
+func newSoundLibDeviceSource() (DeviceSource, error) {
+	return &soundLibDeviceSource{}, nil
+}
+
+func (s *soundLibDeviceSource) SetHandlers(handlers DeviceHandlers) {
+	if handlers.Log != nil {
+		soundlibwrap.SetLogHandler(handlers.Log)
+	}
+	if handlers.DefaultRender != nil {
+		soundlibwrap.SetDefaultRenderHandler(handlers.DefaultRender)
+	}
+	if handlers.DefaultCapture != nil {
+		soundlibwrap.SetDefaultCaptureHandler(handlers.DefaultCapture)
+	}
+	if handlers.RenderVolumeChanged != nil {
+		soundlibwrap.SetRenderVolumeChangedHandler(handlers.RenderVolumeChanged)
+	}
+	if handlers.CaptureVolumeChanged != nil {
+		soundlibwrap.SetCaptureVolumeChangedHandler(handlers.CaptureVolumeChanged)
+	}
+}
+
+func (s *soundLibDeviceSource) Initialize(appName, version string) error {
+	if s.handle != 0 {
+		return errors.New("sound library is already initialized")
+	}
+
+	h, err := soundlibwrap.Initialize(appName, version)
+	if err != nil {
+		return err
+	}
+
+	if err := soundlibwrap.RegisterCallbacks(h); err != nil {
+		_ = soundlibwrap.Uninitialize(h)
+		return err
+	}
+
+	s.handle = h
+	s.initialized.Store(true)
+	return nil
+}
+
+func (s *soundLibDeviceSource) Initialized() bool {
+	return s.initialized.Load()
+}
+
+func (s *soundLibDeviceSource) OperatingSystemName() (string, error) {
+	return soundlibwrap.GetExtendedOperatingSystemName(s.handle)
+}
+
+func (s *soundLibDeviceSource) DefaultRender() (DeviceDescription, error) {
+	desc, err := soundlibwrap.GetDefaultRender(s.handle)
+	if err != nil {
+		return DeviceDescription{}, err
+	}
+	return DeviceDescription{
+		Name:          desc.Name,
+		PnpID:         desc.PnpID,
+		RenderVolume:  int(desc.RenderVolume),
+		CaptureVolume: int(desc.CaptureVolume),
+	}, nil
+}
+
+func (s *soundLibDeviceSource) DefaultCapture() (DeviceDescription, error) {
+	desc, err := soundlibwrap.GetDefaultCapture(s.handle)
+	if err != nil {
+		return DeviceDescription{}, err
+	}
+	return DeviceDescription{
+		Name:          desc.Name,
+		PnpID:         desc.PnpID,
+		RenderVolume:  int(desc.RenderVolume),
+		CaptureVolume: int(desc.CaptureVolume),
+	}, nil
+}
+
+func (s *soundLibDeviceSource) Close() error {
+	if s.handle == 0 {
+		return nil
+	}
+	s.initialized.Store(false)
+	err := soundlibwrap.Uninitialize(s.handle)
+	s.handle = 0
+	return err
+}
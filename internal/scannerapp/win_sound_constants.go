@@ -1,10 +1,20 @@
 package scannerapp
 
 const (
+	EnvWinSoundSource                = "WIN_SOUND_SOURCE"
+	EnvWinSoundSourceVal00SoundLib   = "soundlib"
+	EnvWinSoundSourceVal01Simulated  = "simulated"
+	EnvWinSoundSourceVal02Scenario   = "scenario"
+	EnvWinSoundScenarioFile          = "WIN_SOUND_SCENARIO_FILE"
 	EnvWinSoundEnqueuer              = "WIN_SOUND_ENQUEUER"
+	EnvWinSoundEnqueuerBestEffort    = "WIN_SOUND_ENQUEUER_BEST_EFFORT"
 	EnvWinSoundEnqueuerVal00Empty    = "empty"
 	EnvWinSoundEnqueuerVal01RabbitMq = "rabbitmq"
 	EnvWinSoundEnqueuerVal02Kafka    = "kafka"
+	EnvWinSoundEnqueuerVal03Http     = "http"
+	EnvWinSoundEnqueuerVal04Mqtt     = "mqtt"
+	EnvWinSoundEnqueuerVal05Nats     = "nats"
+	EnvWinSoundEnqueuerVal06File     = "file"
 	EnvWinSoundRabbitMQHost          = "WIN_SOUND_RABBITMQ_HOST"
 	EnvWinSoundRabbitMQPort          = "WIN_SOUND_RABBITMQ_PORT"
 	EnvWinSoundRabbitMQVHost         = "WIN_SOUND_RABBITMQ_VHOST"
@@ -13,8 +23,77 @@ const (
 	EnvWinSoundRabbitMQExchange      = "WIN_SOUND_RABBITMQ_EXCHANGE"
 	EnvWinSoundRabbitMQQueue         = "WIN_SOUND_RABBITMQ_QUEUE"
 	EnvWinSoundRabbitMQRoutingKey    = "WIN_SOUND_RABBITMQ_ROUTING_KEY"
+	EnvWinSoundRabbitMQTLSEnabled    = "WIN_SOUND_RABBITMQ_TLS_ENABLED"
+	EnvWinSoundRabbitMQTLSCAFile     = "WIN_SOUND_RABBITMQ_TLS_CA_FILE"
+	EnvWinSoundRabbitMQTLSCertFile   = "WIN_SOUND_RABBITMQ_TLS_CERT_FILE"
+	EnvWinSoundRabbitMQTLSKeyFile    = "WIN_SOUND_RABBITMQ_TLS_KEY_FILE"
+	EnvWinSoundRabbitMQTLSServerName = "WIN_SOUND_RABBITMQ_TLS_SERVER_NAME"
+	EnvWinSoundRabbitMQAuthMechanism = "WIN_SOUND_RABBITMQ_AUTH_MECHANISM"
+	EnvWinSoundRabbitMQConnThreshold = "WIN_SOUND_RABBITMQ_CONNECTION_THRESHOLD_SEC"
+	EnvWinSoundRabbitMQMaxReconnects = "WIN_SOUND_RABBITMQ_MAX_RECONNECT_ATTEMPTS"
+	EnvWinSoundRabbitMQRetryInitial  = "WIN_SOUND_RABBITMQ_INITIAL_RECONNECT_DELAY_MS"
+	EnvWinSoundRabbitMQRetryMax      = "WIN_SOUND_RABBITMQ_MAX_RECONNECT_DELAY_MS"
+	EnvWinSoundRabbitMQConfirmWait   = "WIN_SOUND_RABBITMQ_PUBLISH_CONFIRM_TIMEOUT_MS"
+	EnvWinSoundCloudEventsMode       = "WIN_SOUND_CLOUDEVENTS_MODE"
+	EnvWinSoundSchemaValidation      = "WIN_SOUND_SCHEMA_VALIDATION"
+	EnvWinSoundRejectedDir           = "WIN_SOUND_REJECTED_DIR"
 	EnvWinSoundKafkaBrokers          = "WIN_SOUND_KAFKA_BROKERS"
 	EnvWinSoundKafkaTopic            = "WIN_SOUND_KAFKA_TOPIC"
 	EnvWinSoundKafkaClientID         = "WIN_SOUND_KAFKA_CLIENT_ID"
 	EnvWinSoundKafkaWriteTimeout     = "WIN_SOUND_KAFKA_WRITE_TIMEOUT_MS"
+	EnvWinSoundKafkaHealthWindow     = "WIN_SOUND_KAFKA_HEALTH_WINDOW_MS"
+	EnvWinSoundKafkaTLSEnabled       = "WIN_SOUND_KAFKA_TLS_ENABLED"
+	EnvWinSoundKafkaTLSCAFile        = "WIN_SOUND_KAFKA_TLS_CA_FILE"
+	EnvWinSoundKafkaTLSCertFile      = "WIN_SOUND_KAFKA_TLS_CERT_FILE"
+	EnvWinSoundKafkaTLSKeyFile       = "WIN_SOUND_KAFKA_TLS_KEY_FILE"
+	EnvWinSoundKafkaTLSSkipVerify    = "WIN_SOUND_KAFKA_TLS_INSECURE_SKIP_VERIFY"
+	EnvWinSoundKafkaSASLMechanism    = "WIN_SOUND_KAFKA_SASL_MECHANISM"
+	EnvWinSoundKafkaSASLUsername     = "WIN_SOUND_KAFKA_SASL_USERNAME"
+	EnvWinSoundKafkaSASLPassword     = "WIN_SOUND_KAFKA_SASL_PASSWORD"
+	EnvWinSoundKafkaEncoding         = "WIN_SOUND_KAFKA_ENCODING"
+	EnvWinSoundKafkaRegistryURL      = "WIN_SOUND_KAFKA_SCHEMA_REGISTRY_URL"
+	EnvWinSoundKafkaRegistryUsername = "WIN_SOUND_KAFKA_SCHEMA_REGISTRY_USERNAME"
+	EnvWinSoundKafkaRegistryPassword = "WIN_SOUND_KAFKA_SCHEMA_REGISTRY_PASSWORD"
+	EnvWinSoundKafkaRegistryAutoReg  = "WIN_SOUND_KAFKA_SCHEMA_REGISTRY_AUTO_REGISTER"
+	EnvWinSoundHTTPBaseURL           = "WIN_SOUND_HTTP_BASE_URL"
+	EnvWinSoundHTTPTimeout           = "WIN_SOUND_HTTP_TIMEOUT_MS"
+	EnvWinSoundHTTPMaxRetries        = "WIN_SOUND_HTTP_MAX_RETRIES"
+	EnvWinSoundHTTPRetryInitial      = "WIN_SOUND_HTTP_INITIAL_RETRY_DELAY_MS"
+	EnvWinSoundHTTPRetryMax          = "WIN_SOUND_HTTP_MAX_RETRY_DELAY_MS"
+	EnvWinSoundHTTPHeaders           = "WIN_SOUND_HTTP_HEADERS"
+	EnvWinSoundMQTTBroker            = "WIN_SOUND_MQTT_BROKER"
+	EnvWinSoundMQTTClientID          = "WIN_SOUND_MQTT_CLIENT_ID"
+	EnvWinSoundMQTTUser              = "WIN_SOUND_MQTT_USER"
+	EnvWinSoundMQTTPassword          = "WIN_SOUND_MQTT_PASSWORD"
+	EnvWinSoundMQTTProtocolVersion   = "WIN_SOUND_MQTT_PROTOCOL_VERSION"
+	EnvWinSoundMQTTTopic             = "WIN_SOUND_MQTT_TOPIC"
+	EnvWinSoundMQTTStatusTopic       = "WIN_SOUND_MQTT_STATUS_TOPIC"
+	EnvWinSoundMQTTQoS               = "WIN_SOUND_MQTT_QOS"
+	EnvWinSoundMQTTRetained          = "WIN_SOUND_MQTT_RETAINED"
+	EnvWinSoundMQTTConnectTimeout    = "WIN_SOUND_MQTT_CONNECT_TIMEOUT_MS"
+	EnvWinSoundMQTTPublishTimeout    = "WIN_SOUND_MQTT_PUBLISH_TIMEOUT_MS"
+	EnvWinSoundNATSURL               = "WIN_SOUND_NATS_URL"
+	EnvWinSoundNATSUser              = "WIN_SOUND_NATS_USER"
+	EnvWinSoundNATSPassword          = "WIN_SOUND_NATS_PASSWORD"
+	EnvWinSoundNATSClientName        = "WIN_SOUND_NATS_CLIENT_NAME"
+	EnvWinSoundNATSSubjectPrefix     = "WIN_SOUND_NATS_SUBJECT_PREFIX"
+	EnvWinSoundNATSJetStream         = "WIN_SOUND_NATS_JETSTREAM"
+	EnvWinSoundNATSStream            = "WIN_SOUND_NATS_STREAM"
+	EnvWinSoundNATSPublishTimeout    = "WIN_SOUND_NATS_PUBLISH_TIMEOUT_MS"
+	EnvWinSoundFileDir               = "WIN_SOUND_FILE_DIR"
+	EnvWinSoundFileMaxSizeMB         = "WIN_SOUND_FILE_MAX_SIZE_MB"
+	EnvWinSoundFileRotateDaily       = "WIN_SOUND_FILE_ROTATE_DAILY"
+	EnvWinSoundFileFsyncInterval     = "WIN_SOUND_FILE_FSYNC_INTERVAL_MS"
+	EnvWinSoundOutboxEnabled         = "WIN_SOUND_OUTBOX_ENABLED"
+	EnvWinSoundOutboxDir             = "WIN_SOUND_OUTBOX_DIR"
+	EnvWinSoundOutboxRetryInitial    = "WIN_SOUND_OUTBOX_RETRY_INITIAL_DELAY_MS"
+	EnvWinSoundOutboxRetryMax        = "WIN_SOUND_OUTBOX_RETRY_MAX_DELAY_MS"
+	EnvWinSoundOutboxBestEffortMax   = "WIN_SOUND_OUTBOX_BEST_EFFORT_MAX_ATTEMPTS"
+	EnvWinSoundQueueCapacity         = "WIN_SOUND_QUEUE_CAPACITY"
+	EnvWinSoundQueueOverflow         = "WIN_SOUND_QUEUE_OVERFLOW"
+	EnvWinSoundVolumeSettleWindow    = "WIN_SOUND_VOLUME_SETTLE_MS"
+	EnvWinSoundVolumeMaxDelay        = "WIN_SOUND_VOLUME_MAX_DELAY_MS"
+	EnvWinSoundMetricsListen         = "WIN_SOUND_METRICS_LISTEN"
+	EnvWinSoundHealthListen          = "WIN_SOUND_HEALTH_LISTEN"
+	EnvWinSoundOTelEndpoint          = "WIN_SOUND_OTEL_ENDPOINT"
 )
@@ -0,0 +1,63 @@
+package schema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/pkg/appinfo"
+)
+
+const (
+	defaultRejectedDirName = "rejected"
+	envSchemaValidation    = "WIN_SOUND_SCHEMA_VALIDATION"
+	envRejectedDir         = "WIN_SOUND_REJECTED_DIR"
+)
+
+// Config controls pre-publish validation and where rejected requests go.
+type Config struct {
+	Enabled     bool
+	RejectedDir string
+}
+
+// LoadConfigFromEnv loads validation settings from environment variables.
+// Validation is on by default; rejected requests are written below
+// %ProgramData%\WinSoundScanner\rejected, see defaultRejectedDir.
+func LoadConfigFromEnv() (Config, error) {
+	cfg := Config{Enabled: true}
+
+	if v := strings.TrimSpace(os.Getenv(envSchemaValidation)); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s %q: %w", envSchemaValidation, v, err)
+		}
+		cfg.Enabled = enabled
+	}
+	if !cfg.Enabled {
+		return cfg, nil
+	}
+
+	if v := strings.TrimSpace(os.Getenv(envRejectedDir)); v != "" {
+		cfg.RejectedDir = v
+	} else {
+		cfg.RejectedDir = defaultRejectedDir()
+	}
+	return cfg, nil
+}
+
+// defaultRejectedDir falls back to the user cache or temp directory where
+// there is no %ProgramData%, e.g. for the simulated source on Linux, since
+// validation is on by default and must not keep the scanner from starting.
+func defaultRejectedDir() string {
+	dataDir, err := appinfo.DataDir()
+	if err != nil {
+		baseDir, err := os.UserCacheDir()
+		if err != nil {
+			baseDir = os.TempDir()
+		}
+		dataDir = filepath.Join(baseDir, appinfo.DataDirName)
+	}
+	return filepath.Join(dataDir, defaultRejectedDirName)
+}
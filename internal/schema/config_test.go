@@ -0,0 +1,45 @@
+package schema
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/pkg/appinfo"
+)
+
+func TestLoadConfigFromEnv_RejectedDirWithoutProgramData(t *testing.T) {
+	cacheDir := t.TempDir()
+	t.Setenv("ProgramData", "")
+	t.Setenv("ALLUSERSPROFILE", "")
+	t.Setenv("XDG_CACHE_HOME", cacheDir)
+	t.Setenv("LocalAppData", cacheDir)
+	t.Setenv(envSchemaValidation, "")
+	t.Setenv(envRejectedDir, "")
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed: %v", err)
+	}
+	if !cfg.Enabled {
+		t.Fatal("expected validation to be enabled by default")
+	}
+	want := filepath.Join(appinfo.DataDirName, defaultRejectedDirName)
+	if !strings.HasSuffix(cfg.RejectedDir, want) || cfg.RejectedDir == want {
+		t.Fatalf("expected an absolute rejected directory ending in %s, got %q", want, cfg.RejectedDir)
+	}
+}
+
+func TestLoadConfigFromEnv_RejectedDirOverride(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(envSchemaValidation, "true")
+	t.Setenv(envRejectedDir, dir)
+
+	cfg, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatalf("LoadConfigFromEnv failed: %v", err)
+	}
+	if cfg.RejectedDir != dir {
+		t.Fatalf("expected %q, got %q", dir, cfg.RejectedDir)
+	}
+}
@@ -0,0 +1,109 @@
+// Package schema holds the versioned JSON Schemas of the request payloads
+// and validates payloads against them.
+package schema
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+)
+
+// Version is stamped into every payload as schemaVersion. Bump it, and add
+// a new directory, whenever a payload changes incompatibly.
+const Version = "1"
+
+const (
+	dir     = "v" + Version
+	baseURL = "https://collect-sound-devices.github.io/win-sound-scanner/schema/" + dir + "/"
+)
+
+//go:embed v1/*.json
+var files embed.FS
+
+var fileNames = map[contract.MessageType]string{
+	contract.MessageTypeConfirmed:             "confirmed.json",
+	contract.MessageTypeDiscovered:            "discovered.json",
+	contract.MessageTypeDetached:              "detached.json",
+	contract.MessageTypeVolumeRenderChanged:   "volume-render-changed.json",
+	contract.MessageTypeVolumeCaptureChanged:  "volume-capture-changed.json",
+	contract.MessageTypeDefaultRenderChanged:  "default-render-changed.json",
+	contract.MessageTypeDefaultCaptureChanged: "default-capture-changed.json",
+}
+
+// FileName returns the schema file of a message type, e.g. "confirmed.json".
+func FileName(messageType contract.MessageType) (string, bool) {
+	name, ok := fileNames[messageType]
+	return name, ok
+}
+
+// Files returns the embedded schemas of the current version.
+func Files() fs.FS {
+	sub, err := fs.Sub(files, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+var compiled = sync.OnceValues(compile)
+
+func compile() (map[contract.MessageType]*jsonschema.Schema, error) {
+	c := jsonschema.NewCompiler()
+	c.AssertFormat()
+
+	entries, err := fs.ReadDir(files, dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		data, err := files.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("parse schema %s: %w", entry.Name(), err)
+		}
+		if err := c.AddResource(baseURL+entry.Name(), doc); err != nil {
+			return nil, fmt.Errorf("add schema %s: %w", entry.Name(), err)
+		}
+	}
+
+	schemas := make(map[contract.MessageType]*jsonschema.Schema, len(fileNames))
+	for messageType, name := range fileNames {
+		sch, err := c.Compile(baseURL + name)
+		if err != nil {
+			return nil, fmt.Errorf("compile schema %s: %w", name, err)
+		}
+		schemas[messageType] = sch
+	}
+	return schemas, nil
+}
+
+// Validate checks a JSON payload against the schema of its message type.
+func Validate(messageType contract.MessageType, body []byte) error {
+	schemas, err := compiled()
+	if err != nil {
+		return err
+	}
+	sch, ok := schemas[messageType]
+	if !ok {
+		return fmt.Errorf("no schema for message type %d", messageType)
+	}
+
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("payload is not valid JSON: %w", err)
+	}
+	if err := sch.Validate(instance); err != nil {
+		return fmt.Errorf("payload does not match its schema: %w", err)
+	}
+	return nil
+}
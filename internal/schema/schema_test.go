@@ -0,0 +1,112 @@
+package schema_test
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/contract"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/enqueuer"
+	"github.com/collect-sound-devices/win-sound-scanner-go/internal/schema"
+)
+
+const updateDate = "2026-05-26T10:00:00.000000Z"
+
+func deviceFields() map[string]string {
+	return map[string]string{
+		contract.FieldUpdateDate:          updateDate,
+		contract.FieldName:                "Speakers",
+		contract.FieldPnpID:               "pnp-1",
+		contract.FieldRenderVolume:        "30",
+		contract.FieldCaptureVolume:       "0",
+		contract.FieldOperationSystemName: "Windows 11",
+		contract.FieldHostName:            "host-1",
+	}
+}
+
+// scannerRequests mirrors the fields the scanner sends for each event type.
+func scannerRequests() map[contract.EventType]map[string]string {
+	switched := deviceFields()
+	switched[contract.FieldPreviousPnpID] = "pnp-0"
+	volume := map[string]string{
+		contract.FieldUpdateDate: updateDate,
+		contract.FieldVolume:     "40",
+		contract.FieldHostName:   "host-1",
+		contract.FieldPnpID:      "pnp-1",
+	}
+	detached := map[string]string{
+		contract.FieldUpdateDate: updateDate,
+		contract.FieldName:       "Speakers",
+		contract.FieldPnpID:      "pnp-1",
+		contract.FieldHostName:   "host-1",
+	}
+	return map[contract.EventType]map[string]string{
+		contract.EventTypeRenderDeviceConfirmed:   deviceFields(),
+		contract.EventTypeCaptureDeviceConfirmed:  deviceFields(),
+		contract.EventTypeRenderDeviceDiscovered:  deviceFields(),
+		contract.EventTypeCaptureDeviceDiscovered: deviceFields(),
+		contract.EventTypeRenderVolumeChanged:     volume,
+		contract.EventTypeCaptureVolumeChanged:    volume,
+		contract.EventTypeRenderDeviceDetached:    detached,
+		contract.EventTypeCaptureDeviceDetached:   detached,
+		contract.EventTypeDefaultRenderChanged:    switched,
+		contract.EventTypeDefaultCaptureChanged:   switched,
+	}
+}
+
+func validate(t *testing.T, event contract.EventType, fields map[string]string) error {
+	t.Helper()
+	payload, err := enqueuer.BuildRequestPayload(enqueuer.Request{Timestamp: time.Now(), Event: event, Fields: fields})
+	if err != nil {
+		t.Fatalf("BuildRequestPayload failed: %v", err)
+	}
+	return schema.Validate(payload.MessageType, payload.Body)
+}
+
+func TestValidate_AcceptsScannerPayloads(t *testing.T) {
+	for event, fields := range scannerRequests() {
+		if err := validate(t, event, fields); err != nil {
+			t.Fatalf("%s: %v", event, err)
+		}
+	}
+}
+
+func TestValidate_RejectsInvalidPayloads(t *testing.T) {
+	cases := map[string]func(map[string]string){
+		"volume not a number":    func(f map[string]string) { f[contract.FieldRenderVolume] = "loud" },
+		"volume out of range":    func(f map[string]string) { f[contract.FieldRenderVolume] = "101" },
+		"missing PnP ID":         func(f map[string]string) { delete(f, contract.FieldPnpID) },
+		"invalid update date":    func(f map[string]string) { f[contract.FieldUpdateDate] = "yesterday" },
+		"unknown field":          func(f map[string]string) { f["color"] = "blue" },
+		"previous PnP ID on new": func(f map[string]string) { f[contract.FieldPreviousPnpID] = "pnp-0" },
+	}
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			fields := deviceFields()
+			mutate(fields)
+			if err := validate(t, contract.EventTypeRenderDeviceDiscovered, fields); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
+
+func TestValidate_RequiresPreviousPnpIDForDefaultChange(t *testing.T) {
+	err := validate(t, contract.EventTypeDefaultCaptureChanged, deviceFields())
+	if err == nil || !strings.Contains(err.Error(), "default-capture-changed.json") {
+		t.Fatalf("expected default-capture-changed validation error, got %v", err)
+	}
+}
+
+func TestFiles_CoverEveryMessageType(t *testing.T) {
+	for messageType := contract.MessageTypeConfirmed; messageType <= contract.MessageTypeDefaultCaptureChanged; messageType++ {
+		name, ok := schema.FileName(messageType)
+		if !ok {
+			t.Fatalf("no schema for message type %d", messageType)
+		}
+		if _, err := fs.Stat(schema.Files(), name); err != nil {
+			t.Fatalf("schema %s is not embedded: %v", name, err)
+		}
+	}
+}
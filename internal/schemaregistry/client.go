@@ -0,0 +1,202 @@
+// Package schemaregistry is a minimal client of the Confluent Schema Registry
+// REST API and the wire format of registry-aware serializers.
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	contentType    = "application/vnd.schemaregistry.v1+json"
+	defaultTimeout = 10 * time.Second
+	// magicByte starts every message in the registry wire format.
+	magicByte = 0
+)
+
+// SchemaType is the registry name of a schema language.
+type SchemaType string
+
+const (
+	SchemaTypeAvro     SchemaType = "AVRO"
+	SchemaTypeProtobuf SchemaType = "PROTOBUF"
+)
+
+// ErrNotFound is returned by Lookup when the subject or schema is not registered.
+var ErrNotFound = errors.New("schema not registered")
+
+type Config struct {
+	URL      string
+	Username string
+	Password string
+	Timeout  time.Duration
+}
+
+// Client resolves schema IDs and caches them per subject and schema text.
+type Client struct {
+	baseURL  *url.URL
+	username string
+	password string
+	http     *http.Client
+
+	mu  sync.Mutex
+	ids map[cacheKey]int
+}
+
+type cacheKey struct {
+	subject string
+	schema  string
+}
+
+func NewClient(cfg Config) (*Client, error) {
+	raw := strings.TrimSpace(cfg.URL)
+	if raw == "" {
+		return nil, errors.New("schema registry URL is empty")
+	}
+	baseURL, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema registry URL %q: %w", raw, err)
+	}
+	if baseURL.Scheme != "http" && baseURL.Scheme != "https" {
+		return nil, fmt.Errorf("invalid schema registry URL %q: scheme must be http or https", raw)
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Client{
+		baseURL:  baseURL,
+		username: cfg.Username,
+		password: cfg.Password,
+		http:     &http.Client{Timeout: timeout},
+		ids:      make(map[cacheKey]int),
+	}, nil
+}
+
+type schemaRequest struct {
+	Schema     string     `json:"schema"`
+	SchemaType SchemaType `json:"schemaType,omitempty"`
+}
+
+type schemaResponse struct {
+	ID int `json:"id"`
+}
+
+type errorResponse struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+// Register registers a schema under a subject and returns its ID. The
+// registry answers with the existing ID when the schema is already known.
+func (c *Client) Register(ctx context.Context, subject string, schemaType SchemaType, schema string) (int, error) {
+	return c.resolve(ctx, c.baseURL.JoinPath("subjects", subject, "versions"), subject, schemaType, schema)
+}
+
+// Lookup returns the ID of a schema already registered under a subject, or
+// ErrNotFound.
+func (c *Client) Lookup(ctx context.Context, subject string, schemaType SchemaType, schema string) (int, error) {
+	return c.resolve(ctx, c.baseURL.JoinPath("subjects", subject), subject, schemaType, schema)
+}
+
+func (c *Client) resolve(ctx context.Context, endpoint *url.URL, subject string, schemaType SchemaType, schema string) (int, error) {
+	key := cacheKey{subject: subject, schema: schema}
+	c.mu.Lock()
+	id, ok := c.ids[key]
+	c.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	request := schemaRequest{Schema: schema}
+	// AVRO is the registry default and older registries reject the field.
+	if schemaType != SchemaTypeAvro {
+		request.SchemaType = schemaType
+	}
+	var response schemaResponse
+	if err := c.post(ctx, endpoint, request, &response); err != nil {
+		return 0, fmt.Errorf("schema registry subject %q: %w", subject, err)
+	}
+
+	c.mu.Lock()
+	c.ids[key] = response.ID
+	c.mu.Unlock()
+	return response.ID, nil
+}
+
+func (c *Client) post(ctx context.Context, endpoint *url.URL, body, result any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", contentType)
+	if c.username != "" || c.password != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		var registryErr errorResponse
+		if json.Unmarshal(data, &registryErr) == nil && registryErr.Message != "" {
+			return fmt.Errorf("status %d, error %d: %s", resp.StatusCode, registryErr.ErrorCode, registryErr.Message)
+		}
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// Frame prepends the wire-format header to an Avro body: the magic byte
+// and the big-endian schema ID.
+func Frame(schemaID int, body []byte) []byte {
+	framed := make([]byte, 5, 5+len(body))
+	framed[0] = magicByte
+	binary.BigEndian.PutUint32(framed[1:5], uint32(schemaID))
+	return append(framed, body...)
+}
+
+// FrameProtobuf prepends the wire-format header to a Protobuf body. After
+// the schema ID come the message indexes as zig-zag varints, count first;
+// the common case of the first message in the file is a single zero byte.
+func FrameProtobuf(schemaID int, messageIndexes []int, body []byte) []byte {
+	framed := Frame(schemaID, nil)
+	if len(messageIndexes) == 1 && messageIndexes[0] == 0 {
+		framed = append(framed, 0)
+	} else {
+		framed = binary.AppendVarint(framed, int64(len(messageIndexes)))
+		for _, index := range messageIndexes {
+			framed = binary.AppendVarint(framed, int64(index))
+		}
+	}
+	return append(framed, body...)
+}
@@ -0,0 +1,112 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRegister_PostsSchemaAndCachesID(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Method != http.MethodPost || r.URL.Path != "/subjects/events-a.B/versions" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "reader" || pass != "secret" {
+			t.Errorf("unexpected basic auth %q %q", user, pass)
+		}
+		var request schemaRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if request.Schema != "syntax = \"proto3\";" || request.SchemaType != SchemaTypeProtobuf {
+			t.Errorf("unexpected request body %+v", request)
+		}
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write([]byte(`{"id":42}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{URL: server.URL, Username: "reader", Password: "secret"})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		id, err := client.Register(context.Background(), "events-a.B", SchemaTypeProtobuf, "syntax = \"proto3\";")
+		if err != nil || id != 42 {
+			t.Fatalf("Register = %d, %v; want 42", id, err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected one registry call, got %d", calls.Load())
+	}
+}
+
+func TestLookup_OmitsAvroTypeAndReportsMissingSchema(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		if _, ok := request["schemaType"]; ok || r.URL.Path != "/subjects/events-a.B" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, request)
+		}
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err := client.Lookup(context.Background(), "events-a.B", SchemaTypeAvro, `{"type":"string"}`); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRegister_ReportsRegistryError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"error_code":409,"message":"Schema being registered is incompatible"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	_, err = client.Register(context.Background(), "events-a.B", SchemaTypeAvro, `{"type":"string"}`)
+	if err == nil || !bytes.Contains([]byte(err.Error()), []byte("incompatible")) {
+		t.Fatalf("expected incompatibility error, got %v", err)
+	}
+}
+
+func TestNewClient_RejectsInvalidURL(t *testing.T) {
+	for _, raw := range []string{"", "registry:8081", "ftp://registry"} {
+		if _, err := NewClient(Config{URL: raw}); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestFrame(t *testing.T) {
+	cases := []struct {
+		name string
+		got  []byte
+		want []byte
+	}{
+		{"avro", Frame(258, []byte{0xAA}), []byte{0, 0, 0, 1, 2, 0xAA}},
+		{"first protobuf message", FrameProtobuf(1, []int{0}, []byte{0xAA}), []byte{0, 0, 0, 0, 1, 0, 0xAA}},
+		{"fifth protobuf message", FrameProtobuf(1, []int{4}, []byte{0xAA}), []byte{0, 0, 0, 0, 1, 2, 8, 0xAA}},
+	}
+	for _, c := range cases {
+		if !bytes.Equal(c.got, c.want) {
+			t.Fatalf("%s: got %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Package tlsconfig builds client TLS settings shared by the broker transports.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Config names the PEM files and verification options of a TLS client.
+type Config struct {
+	// CAFile is a PEM bundle trusted in addition to the system roots.
+	CAFile string
+	// CertFile and KeyFile are the client certificate and its private key;
+	// they must be set together.
+	CertFile   string
+	KeyFile    string
+	ServerName string
+	// InsecureSkipVerify disables server certificate verification; for labs only.
+	InsecureSkipVerify bool
+}
+
+// IsZero reports whether no TLS option is set.
+func (c Config) IsZero() bool {
+	return c == Config{}
+}
+
+// HasClientCertificate reports whether a client certificate is configured.
+func (c Config) HasClientCertificate() bool {
+	return strings.TrimSpace(c.CertFile) != ""
+}
+
+// Validate checks option combinations without touching the files.
+func (c Config) Validate() error {
+	hasCert := strings.TrimSpace(c.CertFile) != ""
+	hasKey := strings.TrimSpace(c.KeyFile) != ""
+	if hasCert != hasKey {
+		return errors.New("client certificate and key files must be set together")
+	}
+	if c.InsecureSkipVerify && strings.TrimSpace(c.CAFile) != "" {
+		return errors.New("a CA file has no effect when server certificate verification is skipped")
+	}
+	return nil
+}
+
+// Build loads the files and returns the client TLS configuration.
+func (c Config) Build() (*tls.Config, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         strings.TrimSpace(c.ServerName),
+		InsecureSkipVerify: c.InsecureSkipVerify, //nolint:gosec // explicitly requested for lab brokers
+	}
+
+	if caFile := strings.TrimSpace(c.CAFile); caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA file %q contains no PEM certificates", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if c.HasClientCertificate() {
+		cert, err := tls.LoadX509KeyPair(strings.TrimSpace(c.CertFile), strings.TrimSpace(c.KeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,87 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSelfSigned writes a self-signed certificate and its key as PEM files.
+func writeSelfSigned(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "win-sound-scanner-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	return certFile, keyFile
+}
+
+func TestBuild_LoadsCAAndClientCertificate(t *testing.T) {
+	certFile, keyFile := writeSelfSigned(t, t.TempDir())
+
+	cfg, err := Config{CAFile: certFile, CertFile: certFile, KeyFile: keyFile, ServerName: "broker.local"}.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if cfg.RootCAs == nil || len(cfg.Certificates) != 1 || cfg.ServerName != "broker.local" {
+		t.Fatalf("unexpected TLS config: %+v", cfg)
+	}
+}
+
+func TestBuild_RejectsInvalidInput(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeSelfSigned(t, dir)
+	notPEM := filepath.Join(dir, "ca.txt")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	cases := map[string]Config{
+		"cert without key":    {CertFile: certFile},
+		"key without cert":    {KeyFile: keyFile},
+		"missing CA file":     {CAFile: filepath.Join(dir, "missing.pem")},
+		"CA without PEM":      {CAFile: notPEM},
+		"CA with skip":        {CAFile: certFile, InsecureSkipVerify: true},
+		"mismatched key pair": {CertFile: certFile, KeyFile: certFile},
+	}
+	for name, cfg := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := cfg.Build(); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	envOTelEndpoint = "WIN_SOUND_OTEL_ENDPOINT"
+	// tracesPath is appended to an endpoint without a path, the way the
+	// OpenTelemetry SDKs treat OTEL_EXPORTER_OTLP_ENDPOINT.
+	tracesPath = "/v1/traces"
+)
+
+// Config selects the OTLP/HTTP collector the spans are exported to.
+type Config struct {
+	// Endpoint is the collector URL, e.g. "http://localhost:4318"; empty
+	// disables the export.
+	Endpoint string
+}
+
+// Enabled reports whether spans are exported.
+func (c Config) Enabled() bool {
+	return c.Endpoint != ""
+}
+
+// tracesURL is the URL the spans are posted to.
+func (c Config) tracesURL() string {
+	u, err := url.Parse(c.Endpoint)
+	if err != nil || strings.Trim(u.Path, "/") != "" {
+		return c.Endpoint
+	}
+	u.Path = tracesPath
+	return u.String()
+}
+
+// LoadConfigFromEnv loads the collector URL from WIN_SOUND_OTEL_ENDPOINT.
+// Tracing is off by default.
+func LoadConfigFromEnv() (Config, error) {
+	cfg := Config{Endpoint: strings.TrimSpace(os.Getenv(envOTelEndpoint))}
+	if !cfg.Enabled() {
+		return cfg, nil
+	}
+	u, err := url.Parse(cfg.Endpoint)
+	if err == nil && u.Scheme != "http" && u.Scheme != "https" {
+		err = errors.New("expected an http or https URL")
+	}
+	if err == nil && u.Host == "" {
+		err = errors.New("missing host")
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid %s %q: %w", envOTelEndpoint, cfg.Endpoint, err)
+	}
+	return cfg, nil
+}
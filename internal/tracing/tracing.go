@@ -0,0 +1,123 @@
+// Package tracing follows a device event with OpenTelemetry spans from the
+// device source callback to the broker acknowledgement.
+//
+// The request queue and the outbox hand requests between goroutines and
+// across restarts, so the trace context travels with each request as a W3C
+// traceparent (enqueuer.Request.TraceParent) rather than in a context. The
+// RabbitMQ and Kafka publishers write it into the message headers, so the
+// forwarders can continue the trace.
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/collect-sound-devices/win-sound-scanner-go/pkg/appinfo"
+)
+
+const (
+	instrumentationName = "github.com/collect-sound-devices/win-sound-scanner-go"
+	headerTraceParent   = "traceparent"
+	shutdownTimeout     = 5 * time.Second
+)
+
+// Attribute keys shared by the spans.
+const (
+	AttrEventType              = attribute.Key("win_sound.event_type")
+	AttrMessagingSystem        = attribute.Key("messaging.system")
+	AttrMessagingDestination   = attribute.Key("messaging.destination.name")
+	AttrMessagingOperationType = attribute.Key("messaging.operation.type")
+)
+
+// propagator writes and reads W3C Trace Context headers.
+var propagator = propagation.TraceContext{}
+
+// Start starts a span below the one in ctx. Without Setup the spans are
+// not recorded, but a parent taken from a traceparent is still propagated.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// End records err, if any, on span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceParent returns the W3C traceparent of the span in ctx, or "" when
+// there is none.
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	return carrier[headerTraceParent]
+}
+
+// ContextWithTraceParent returns ctx with the remote span named by
+// traceParent as parent; an empty or malformed traceParent leaves ctx as is.
+func ContextWithTraceParent(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier{headerTraceParent: traceParent})
+}
+
+// Inject writes the trace context of ctx into carrier.
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	propagator.Inject(ctx, carrier)
+}
+
+// Setup exports the spans to the OTLP/HTTP collector of cfg and returns the
+// function that flushes and stops the export. Export errors are logged and
+// never fail a publish.
+func Setup(ctx context.Context, cfg Config, logger *slog.Logger) (func(), error) {
+	if ctx == nil {
+		panic("nil context")
+	}
+	if logger == nil {
+		panic("nil logger")
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.tracesURL()))
+	if err != nil {
+		return nil, err
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("service.name", appinfo.AppName),
+		attribute.String("service.version", appinfo.Version),
+	}
+	if hostName, err := os.Hostname(); err == nil {
+		attrs = append(attrs, attribute.String("host.name", hostName))
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attrs...)),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.Warn("OpenTelemetry export failed", "err", err)
+	}))
+	logger.Info("Exporting traces", "endpoint", cfg.tracesURL())
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			logger.Warn("Trace export shutdown failed", "err", err)
+		}
+	}, nil
+}
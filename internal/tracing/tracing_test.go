@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceParent_ContinuesTraceAcrossRequests(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctx, callback := Start(context.Background(), "RenderVolumeChanged callback")
+	traceParent := TraceParent(ctx)
+	callback.End()
+	if traceParent == "" {
+		t.Fatal("expected a traceparent for a recorded span")
+	}
+
+	// A transport picks the request up on another goroutine.
+	_, publish := Start(ContextWithTraceParent(context.Background(), traceParent), "rabbitmq publish",
+		trace.WithSpanKind(trace.SpanKindProducer))
+	publish.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	if spans[1].SpanContext().TraceID() != spans[0].SpanContext().TraceID() ||
+		spans[1].Parent().SpanID() != spans[0].SpanContext().SpanID() {
+		t.Fatal("expected the publish span to be a child of the callback span")
+	}
+}
+
+func TestContextWithTraceParent_IgnoresEmptyAndMalformed(t *testing.T) {
+	for _, traceParent := range []string{"", "not-a-traceparent"} {
+		ctx := ContextWithTraceParent(context.Background(), traceParent)
+		if trace.SpanContextFromContext(ctx).IsValid() {
+			t.Fatalf("expected no parent for %q", traceParent)
+		}
+	}
+}
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	t.Setenv(envOTelEndpoint, "")
+	cfg, err := LoadConfigFromEnv()
+	if err != nil || cfg.Enabled() {
+		t.Fatalf("expected tracing off by default, got %+v, %v", cfg, err)
+	}
+
+	for endpoint, want := range map[string]string{
+		"http://localhost:4318":             "http://localhost:4318/v1/traces",
+		"https://collector:4318/":           "https://collector:4318/v1/traces",
+		"http://collector:4318/otlp/traces": "http://collector:4318/otlp/traces",
+	} {
+		t.Setenv(envOTelEndpoint, endpoint)
+		cfg, err := LoadConfigFromEnv()
+		if err != nil {
+			t.Fatalf("LoadConfigFromEnv(%q) failed: %v", endpoint, err)
+		}
+		if got := cfg.tracesURL(); got != want {
+			t.Fatalf("tracesURL(%q) = %q, want %q", endpoint, got, want)
+		}
+	}
+
+	for _, endpoint := range []string{"localhost:4318", "grpc://collector:4317", "http://"} {
+		t.Setenv(envOTelEndpoint, endpoint)
+		if _, err := LoadConfigFromEnv(); err == nil {
+			t.Fatalf("expected error for %q", endpoint)
+		}
+	}
+}
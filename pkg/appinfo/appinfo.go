@@ -1,8 +1,38 @@
 package appinfo
 
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
 var (
 	// AppName is passed to the underlying DLL.
 	AppName = "win-sound-scanner"
 	// Version can be injected at build time using -ldflags -X
 	Version = "dev"
 )
+
+// DataDirName is the folder below %ProgramData% that holds logs and local state.
+const DataDirName = "WinSoundScanner"
+
+// ProgramDataDir returns the machine-wide application data root.
+func ProgramDataDir() (string, error) {
+	if v, ok := os.LookupEnv("ProgramData"); ok && strings.TrimSpace(v) != "" {
+		return v, nil
+	}
+	if v, ok := os.LookupEnv("ALLUSERSPROFILE"); ok && strings.TrimSpace(v) != "" {
+		return v, nil
+	}
+	return "", errors.New("ProgramData is not available in environment")
+}
+
+// DataDir returns %ProgramData%\WinSoundScanner.
+func DataDir() (string, error) {
+	baseDir, err := ProgramDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(baseDir, DataDirName), nil
+}